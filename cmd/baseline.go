@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iq2i/ainspector/internal/baseline"
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var baselineOutput string
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline of accepted findings",
+}
+
+var baselineCreateCmd = &cobra.Command{
+	Use:   "create [paths...]",
+	Short: "Review the working tree and accept its current findings",
+	Long: `Reviews every function of the working tree (or of the given files and directories) and records the findings in a baseline file to commit.
+
+When reviewing a pull request or merge request, findings matching the baseline are not reported, so that pre-existing problems of a legacy codebase don't flood the review. A finding matches when it is reported for the same file and function, for the same rule id (if any) and with the same description, ignoring case, punctuation and numbers. New findings of a baselined rule in the same function are still reported.
+
+Every function is sent to the LLM: limit the paths on large repositories.
+
+Requires the same LLM environment variables as the review command.`,
+	RunE: runBaselineCreate,
+}
+
+func init() {
+	baselineCreateCmd.Flags().StringVarP(&baselineOutput, "output", "o", baseline.DefaultPath, "Baseline file to write")
+	baselineCmd.AddCommand(baselineCreateCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+func runBaselineCreate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Fetch remote configurations through the provider when running in CI
+	var fetcher config.RemoteFetcher
+	if env, err := ci.Detect(); err == nil {
+		p, err := newProvider(env)
+		if err != nil {
+			return fmt.Errorf("failed to create provider: %w", err)
+		}
+		fetcher, _ = p.(config.RemoteFetcher)
+	}
+
+	cfg, err := config.LoadWithFetcher(ctx, fetcher)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Extract every function of the working tree
+	p := provider.NewLocalProvider(".", args...)
+	files, err := p.GetModifiedFiles(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	ext := extractor.New(p, cfg)
+	defer ext.Close()
+	functions, err := ext.ExtractModifiedFunctions(ctx, files)
+	if err != nil {
+		return fmt.Errorf("failed to extract functions: %w", err)
+	}
+
+	fmt.Printf("Extracted %d functions from %d files\n", len(functions), len(files))
+
+	if len(functions) == 0 {
+		fmt.Println("No functions to review")
+		return nil
+	}
+
+	prompts, err := llm.LoadPrompts(&cfg.Prompts)
+	if err != nil {
+		return fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	reviewer, err := newReviewer(ctx, cfg, prompts, functionLanguages(functions))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reviewing %d functions with LLM (%s)...\n", len(functions), llmModel())
+	results := reviewer.Review(ctx, functions)
+
+	base := baseline.New()
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			fmt.Printf("Warning: failed to review %s (%s): %v\n", result.Function.Name, result.Function.FilePath, result.Error)
+			failed++
+			continue
+		}
+		for _, suggestion := range result.Suggestions {
+			if result.Function.IsSuppressed(suggestion.Line, suggestion.Rule) {
+				continue
+			}
+			base.Add(result.Function.FilePath, result.Function.Name, suggestion.Rule, suggestion.Description)
+		}
+	}
+
+	if err := base.Save(baselineOutput); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	fmt.Printf("Recorded %d findings in %s\n", base.Len(), baselineOutput)
+	if failed > 0 {
+		fmt.Printf("Warning: %d functions could not be reviewed and have no findings in the baseline\n", failed)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/iq2i/ainspector/internal/cache"
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clearFunctions []string
+	clearFile      string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and invalidate the review state of a pull request or merge request",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the functions considered reviewed on the PR/MR",
+	Long: `Fetches the review comments of the PR/MR and shows, for each modified function, whether it is considered reviewed and would be skipped by the review command.
+
+Reviews of functions changed since are listed as outdated.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheList,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Invalidate the review of specific functions",
+	Long: `Invalidates the review of the given functions, so that the next review command reviews them again without --force reviewing everything.
+
+The hash markers of their review comments are rewritten so they are no longer recognized, and their results are removed from the local review cache.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+func init() {
+	cacheClearCmd.Flags().StringArrayVar(&clearFunctions, "function", nil, "Name of a function to review again (repeatable)")
+	cacheClearCmd.Flags().StringVar(&clearFile, "file", "", "Only clear functions of this file")
+	cacheClearCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory of the local review cache (default: $AINSPECTOR_CACHE_DIR or the user cache directory)")
+	_ = cacheClearCmd.MarkFlagRequired("function")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// pullRequest is the PR/MR of the CI environment with its modified functions
+// and review comments
+type pullRequest struct {
+	env       *ci.Environment
+	provider  provider.Provider
+	settings  *reviewSettings
+	functions []extractor.ExtractedFunction
+	comments  []provider.ExistingComment
+}
+
+// loadPullRequest fetches the modified functions and the review comments of
+// the PR/MR of the CI environment
+func loadPullRequest(ctx context.Context) (*pullRequest, error) {
+	env, err := ci.Detect()
+	if err != nil {
+		return nil, fmt.Errorf("CI detection failed: %w", err)
+	}
+	p, err := newProvider(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	fetcher, _ := p.(config.RemoteFetcher)
+	cfg, err := config.LoadWithFetcher(ctx, fetcher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	prompts, err := llm.LoadPrompts(&cfg.Prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	filterFiles(p, cfg)
+	files, err := p.GetModifiedFiles(ctx, env.PRNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modified files: %w", err)
+	}
+
+	ext := extractor.New(p, cfg)
+	defer ext.Close()
+	functions, err := ext.ExtractModifiedFunctions(ctx, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract functions: %w", err)
+	}
+
+	comments, err := p.GetReviewComments(ctx, env.PRNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch review comments: %w", err)
+	}
+
+	return &pullRequest{
+		env:       env,
+		provider:  p,
+		settings:  newReviewSettings(cfg, prompts, llmModel()),
+		functions: functions,
+		comments:  comments,
+	}, nil
+}
+
+// commentsByHash groups the review comments by the hash of their marker
+func commentsByHash(comments []provider.ExistingComment) map[string][]provider.ExistingComment {
+	byHash := make(map[string][]provider.ExistingComment)
+	for _, c := range comments {
+		if hash := cache.ExtractHash(c.Body); hash != "" {
+			byHash[hash] = append(byHash[hash], c)
+		}
+	}
+	return byHash
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	pr, err := loadPullRequest(context.Background())
+	if err != nil {
+		return err
+	}
+
+	tracker := pr.settings.tracker(pr.comments)
+	byHash := commentsByHash(pr.comments)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FUNCTION\tFILE\tHASH\tCOMMENTS\tSTATUS")
+	for i := range pr.functions {
+		fn := &pr.functions[i]
+		hash := pr.settings.hash(fn)
+
+		status := "not reviewed"
+		if tracker.IsReviewed(fn) {
+			status = "reviewed"
+		} else if len(byHash[hash]) > 0 {
+			status = "settings changed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", fn.Name, fn.FilePath, hash, len(byHash[hash]), status)
+		delete(byHash, hash)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// The remaining hashes belong to functions changed since their review
+	if len(byHash) > 0 {
+		fmt.Printf("\nOutdated reviews (%d):\n", len(byHash))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "HASH\tCOMMENTS\tLOCATION")
+		hashes := make([]string, 0, len(byHash))
+		for hash := range byHash {
+			hashes = append(hashes, hash)
+		}
+		sort.Strings(hashes)
+		for _, hash := range hashes {
+			comments := byHash[hash]
+			fmt.Fprintf(w, "%s\t%d\t%s:%d\n", hash, len(comments), comments[0].Path, comments[0].Line)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	pr, err := loadPullRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	updater, ok := pr.provider.(provider.CommentUpdater)
+	if !ok {
+		return fmt.Errorf("%s does not support editing review comments", pr.env.Provider)
+	}
+
+	names := make(map[string]bool)
+	for _, name := range clearFunctions {
+		names[name] = true
+	}
+
+	store := openStore()
+	byHash := commentsByHash(pr.comments)
+	found, updated := make(map[string]bool), 0
+
+	for i := range pr.functions {
+		fn := &pr.functions[i]
+		if !names[fn.Name] || (clearFile != "" && fn.FilePath != clearFile) {
+			continue
+		}
+		found[fn.Name] = true
+
+		if store != nil {
+			if err := store.Delete(pr.settings.storeKey(fn)); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+
+		hash := pr.settings.hash(fn)
+		for _, c := range byHash[hash] {
+			if err := updater.UpdateReviewComment(ctx, pr.env.PRNumber, c.ID, cache.InvalidateMarkers(c.Body)); err != nil {
+				return fmt.Errorf("failed to invalidate review of %s: %w", fn.Name, err)
+			}
+			updated++
+		}
+
+		fmt.Printf("Cleared %s (%s)\n", fn.Name, fn.FilePath)
+	}
+
+	for _, name := range clearFunctions {
+		if !found[name] {
+			fmt.Printf("Warning: function %s is not modified in this PR/MR\n", name)
+		}
+	}
+
+	fmt.Printf("Invalidated %d review comments\n", updated)
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// newCheckRun creates the check run reporting the findings of a review, which
+// fails if one of them is at least as severe as the fail_on setting
+func newCheckRun(checks config.ChecksConfig, annotations []provider.Annotation, summary string) *provider.CheckRun {
+	conclusion := "success"
+	if findingsFail(checks.Fails, annotations) {
+		conclusion = "failure"
+	} else if len(annotations) > 0 {
+		conclusion = "neutral"
+	}
+
+	failOn := "never fails"
+	if checks.FailSeverity() != config.FailNever {
+		failOn = fmt.Sprintf("fails on %s findings", checks.FailSeverity())
+	}
+
+	return &provider.CheckRun{
+		Conclusion:  conclusion,
+		Title:       describeFindings(annotations),
+		Summary:     fmt.Sprintf("%s\n\nThe check %s.", summary, failOn),
+		Annotations: annotations,
+	}
+}
+
+// findingsFail reports whether one of the findings is at least as severe as
+// a fail_on setting, given as its Fails method
+func findingsFail(fails func(severity string) bool, annotations []provider.Annotation) bool {
+	for _, annotation := range annotations {
+		if fails(annotation.Severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// describePrevious mentions the findings of previous runs included in a check
+// run summary, if any
+func describePrevious(previous []provider.Annotation) string {
+	switch len(previous) {
+	case 0:
+		return ""
+	case 1:
+		return " 1 finding reported by an open comment of a previous run is included."
+	default:
+		return fmt.Sprintf(" %d findings reported by open comments of previous runs are included.", len(previous))
+	}
+}
+
+// describeFindings summarizes findings, e.g. "3 issues (1 critical)"
+func describeFindings(annotations []provider.Annotation) string {
+	critical := 0
+	for _, annotation := range annotations {
+		if annotation.Severity == config.SeverityCritical {
+			critical++
+		}
+	}
+
+	switch {
+	case len(annotations) == 0:
+		return "No issues"
+	case len(annotations) == 1 && critical == 0:
+		return "1 issue"
+	case len(annotations) == 1:
+		return "1 issue (1 critical)"
+	case critical == 0:
+		return fmt.Sprintf("%d issues", len(annotations))
+	default:
+		return fmt.Sprintf("%d issues (%d critical)", len(annotations), critical)
+	}
+}
+
+// createCheckRun creates the check run of a review, if the provider supports
+// check runs
+func createCheckRun(ctx context.Context, p provider.Provider, run *provider.CheckRun) error {
+	reporter, ok := p.(provider.CheckReporter)
+	if !ok {
+		fmt.Println("Warning: the git host does not support check runs, skipping check run")
+		return nil
+	}
+
+	fmt.Printf("Creating check run (%s)...\n", run.Title)
+	if err := reporter.CreateCheckRun(ctx, *run); err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	return nil
+}
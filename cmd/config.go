@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the ainspector configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate ainspector.yaml and print the effective configuration",
+	Long: `Loads ainspector.yaml (or ainspector.yml) from the current directory, along with the files it extends and the nested configuration files of subdirectories.
+
+Unknown fields, invalid values and invalid glob patterns are reported with their line number. When the configuration is valid, the effective configuration of the repository root and of every directory containing a nested configuration file is printed.
+
+Configurations extended from other repositories can only be fetched in a CI environment.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	// Fetch remote configurations through the provider when running in CI
+	var fetcher config.RemoteFetcher
+	if env, err := ci.Detect(); err == nil {
+		p, err := newProvider(env)
+		if err != nil {
+			return fmt.Errorf("failed to create provider: %w", err)
+		}
+		fetcher, _ = p.(config.RemoteFetcher)
+	}
+
+	cfg, err := config.LoadWithFetcher(context.Background(), fetcher)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Println("Configuration is valid")
+
+	if err := printConfig(".", cfg); err != nil {
+		return err
+	}
+	for _, dir := range cfg.NestedDirs() {
+		if err := printConfig(dir, cfg.ForDir(dir)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printConfig prints the effective configuration of a directory as YAML
+func printConfig(dir string, cfg *config.Config) error {
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	fmt.Printf("\n# Effective configuration for %s\n", dir)
+	if out.String() == "{}\n" {
+		fmt.Println("# (empty)")
+		return nil
+	}
+	fmt.Print(out.String())
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initForce bool
+	initHost  string
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate an ainspector.yaml for the current repository",
+	Long: `Inspects the working tree and writes a commented ainspector.yaml with:
+  - ignore paths for dependency directories (vendor/, node_modules/...) and generated files
+  - context includes for the documentation and AI instruction files found (CLAUDE.md, AGENTS.md...)
+  - a starter rule set for the detected languages
+
+A CI workflow running ainspector on pull requests is also generated for the detected host:
+.github/workflows/ainspector.yml is written for GitHub, a job to add to .gitlab-ci.yml is printed for GitLab.`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func init() {
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite existing files")
+	initCmd.Flags().StringVar(&initHost, "host", "", "Git host to generate the CI workflow for (github or gitlab, detected by default)")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	project, err := scaffold.Detect(".")
+	if err != nil {
+		return fmt.Errorf("failed to inspect working tree: %w", err)
+	}
+
+	if initHost != "" {
+		if initHost != scaffold.HostGitHub && initHost != scaffold.HostGitLab {
+			return fmt.Errorf("invalid host %q (expected github or gitlab)", initHost)
+		}
+		project.Host = initHost
+	}
+
+	if len(project.Languages) > 0 {
+		fmt.Printf("Detected languages: %s\n", strings.Join(project.Languages, ", "))
+	} else {
+		fmt.Println("No supported languages detected")
+	}
+
+	if err := writeNewFile("ainspector.yaml", scaffold.Config(project)); err != nil {
+		return err
+	}
+	fmt.Println("Created ainspector.yaml")
+
+	workflowPath, workflow := scaffold.Workflow(project.Host)
+	if project.Host == scaffold.HostGitLab {
+		fmt.Printf("\nAdd this job to %s:\n\n%s", workflowPath, workflow)
+		return nil
+	}
+
+	if err := writeNewFile(workflowPath, workflow); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return nil
+	}
+	fmt.Printf("Created %s (add an LLM_API_KEY secret to your repository)\n", workflowPath)
+
+	return nil
+}
+
+// writeNewFile writes a file, refusing to overwrite it unless --force is set
+func writeNewFile(path, content string) error {
+	if _, err := os.Stat(path); err == nil && !initForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
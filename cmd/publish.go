@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iq2i/ainspector/internal/artifact"
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var artifactPath string
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Post a review written by 'ainspector review --emit-artifact'",
+	Long: `Posts the review of a pull request written to a file by 'ainspector review --emit-artifact'.
+
+This splits the review of pull requests from forks, whose workflows only get a read-only token, in two workflows: the pull_request workflow reviews the changes and uploads the artifact, and a workflow_run workflow, which has write access, downloads it and publishes it.
+
+The artifact comes from an untrusted workflow, so it is validated before posting: it must be for the head commit of the workflow run, which must still be the head of the pull request, its comments must be on lines of the diff, and it may only mark or update the open ainspector comments of the pull request, without changing their text. The check run and the commit status are created from its findings with the configuration of the checked out branch, not the one of the pull request.
+
+Requires the same git host environment variables as the review command, but no LLM.`,
+	Args: cobra.NoArgs,
+	RunE: runPublish,
+}
+
+func init() {
+	publishCmd.Flags().StringVar(&artifactPath, "artifact", artifact.DefaultPath, "Review artifact file to post")
+	rootCmd.AddCommand(publishCmd)
+}
+
+func runPublish(cmd *cobra.Command, args []string) (err error) {
+	env, err := ci.Detect()
+	if err != nil {
+		return fmt.Errorf("CI detection failed: %w", err)
+	}
+
+	a, err := artifact.Load(artifactPath)
+	if err != nil {
+		return fmt.Errorf("failed to load review artifact: %w", err)
+	}
+
+	p, err := newProvider(env)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+	head, ok := p.(provider.HeadCommitReader)
+	if !ok {
+		return fmt.Errorf("%s does not support publishing review artifacts", env.Provider)
+	}
+
+	ctx := context.Background()
+
+	// The configuration of the checked out branch is trusted, unlike the one
+	// the artifact was reviewed with
+	fetcher, _ := p.(config.RemoteFetcher)
+	cfg, err := config.LoadWithFetcher(ctx, fetcher)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Check the artifact against the current state of the PR/MR
+	filterFiles(p, cfg)
+	files, err := p.GetModifiedFiles(ctx, a.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get modified files: %w", err)
+	}
+	if err := a.Validate(env, head.HeadSHA()); err != nil {
+		return fmt.Errorf("invalid review artifact: %w", err)
+	}
+	env.PRNumber = a.PRNumber
+
+	fmt.Printf("Publishing review of %s/%s, PR/MR: #%d\n", env.Owner, env.Repo, env.PRNumber)
+
+	existingComments, err := p.GetReviewComments(ctx, env.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get review comments: %w", err)
+	}
+	review, err := a.Review(files, existingComments)
+	if err != nil {
+		return err
+	}
+
+	// Report the findings on the head commit, failing it if the publication fails
+	status := newCommitStatus(p, env)
+	state, description := "success", describeFindings(review.Findings)
+	if findingsFail(cfg.Status.Fails, review.Findings) {
+		state = "failure"
+	}
+	defer func() {
+		if err != nil {
+			state, description = "failure", "Review failed"
+		}
+		status.set(ctx, state, description)
+	}()
+
+	if cfg.Checks.IsEnabled() {
+		review.Check = newCheckRun(cfg.Checks, review.Findings, fmt.Sprintf("Review of commit %s, published from a workflow artifact.", shortSHA(env)))
+	}
+	if !cfg.Checks.CommentsEnabled() {
+		review.Comments = nil
+	}
+
+	return postReview(ctx, p, env, review)
+}
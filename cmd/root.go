@@ -2,26 +2,32 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/iq2i/ainspector/internal/artifact"
+	"github.com/iq2i/ainspector/internal/baseline"
 	"github.com/iq2i/ainspector/internal/cache"
 	"github.com/iq2i/ainspector/internal/ci"
 	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
 	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/parser"
 	"github.com/iq2i/ainspector/internal/provider"
 	"github.com/spf13/cobra"
 )
 
 var (
-	version     = "0.1.0"
-	forceReview bool
+	version      = "0.1.0"
+	forceReview  bool
+	baselinePath string
+	emitArtifact string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "ainspector",
-	Short: "AI-powered code review tool for GitHub PRs and GitLab MRs",
+	Short: "AI-powered code review tool for GitHub PRs, GitLab MRs and Bitbucket PRs",
 	Long:  `ainspector analyzes pull requests and merge requests to extract modified functions for AI-powered code review.`,
 }
 
@@ -30,7 +36,7 @@ var reviewCmd = &cobra.Command{
 	Short: "Review a pull request or merge request",
 	Long: `Analyzes a GitHub Pull Request or GitLab Merge Request and extracts functions that contain modified lines.
 
-This command automatically detects the CI environment (GitHub Actions or GitLab CI) and posts the review as a comment on the PR/MR.
+This command automatically detects the CI environment (GitHub Actions, Gitea Actions, GitLab CI, Bitbucket Pipelines, Azure Pipelines, Gerrit Trigger or Bitbucket Server) and posts the review as a comment on the PR/MR.
 
 Required environment variables:
   LLM_API_KEY     - API key for the LLM service
@@ -41,9 +47,32 @@ Optional environment variables:
 
 For GitHub Actions:
   GITHUB_TOKEN    - GitHub API token (usually provided automatically)
+  GITHUB_APP_ID   - GitHub App to authenticate as instead (with GITHUB_APP_PRIVATE_KEY)
+  GITHUB_CA_BUNDLE - CA certificates of GitHub Enterprise Server instances
+                    using an internal certificate authority (optional)
+
+For Gitea / Forgejo Actions:
+  GITEA_TOKEN     - Gitea API token (or GITHUB_TOKEN)
 
 For GitLab CI:
-  GITLAB_TOKEN    - GitLab API token (or CI_JOB_TOKEN)`,
+  GITLAB_TOKEN    - GitLab API token (or CI_JOB_TOKEN)
+
+For Bitbucket Pipelines:
+  BITBUCKET_TOKEN - Repository or workspace access token
+                    (or BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD)
+
+For Azure Pipelines:
+  SYSTEM_ACCESSTOKEN     - Pipeline access token, mapped from $(System.AccessToken)
+                           (or AZURE_DEVOPS_TOKEN, a personal access token)
+
+For Gerrit (Jenkins Gerrit Trigger):
+  GERRIT_USERNAME, GERRIT_HTTP_PASSWORD - Account and HTTP password of the reviewer
+  GERRIT_URL             - Base URL of the instance (default: from GERRIT_CHANGE_URL)
+
+For Bitbucket Server / Data Center:
+  BITBUCKET_SERVER_URL   - Base URL of the instance
+  BITBUCKET_SERVER_TOKEN - Personal access token
+  BITBUCKET_PROJECT_KEY, BITBUCKET_REPO_SLUG and BITBUCKET_PR_ID (or CHANGE_ID)`,
 	Args: cobra.NoArgs,
 	RunE: runReview,
 }
@@ -58,6 +87,9 @@ var versionCmd = &cobra.Command{
 
 func init() {
 	reviewCmd.Flags().BoolVarP(&forceReview, "force", "f", false, "Force re-review of all functions, ignoring cache")
+	reviewCmd.Flags().StringVar(&baselinePath, "baseline", baseline.DefaultPath, "Baseline file of accepted findings (see 'ainspector baseline create')")
+	reviewCmd.Flags().StringVar(&emitArtifact, "emit-artifact", "", "Write the review to a file instead of posting it (see 'ainspector publish')")
+	reviewCmd.Flags().Lookup("emit-artifact").NoOptDefVal = artifact.DefaultPath
 	rootCmd.AddCommand(reviewCmd)
 	rootCmd.AddCommand(versionCmd)
 }
@@ -68,34 +100,60 @@ func Execute() {
 	}
 }
 
-func runReview(cmd *cobra.Command, args []string) error {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
+func runReview(cmd *cobra.Command, args []string) (err error) {
 	// Detect CI environment
 	env, err := ci.Detect()
 	if err != nil {
 		return fmt.Errorf("CI detection failed: %w", err)
 	}
 
+	if env.PRNumber == 0 {
+		return fmt.Errorf("CI detection failed: could not determine PR/MR number")
+	}
+
 	fmt.Printf("Detected %s CI environment\n", env.Provider)
 	fmt.Printf("Repository: %s/%s, PR/MR: #%d\n", env.Owner, env.Repo, env.PRNumber)
 
 	// Create provider based on detected environment
-	var p provider.Provider
-	if env.Provider == "github" {
-		p = provider.NewGitHubProvider(env.Owner, env.Repo, env.Token)
-	} else {
-		p = provider.NewGitLabProvider(env.ServerHost, env.Owner, env.Repo, env.Token)
+	p, err := newProvider(env)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
 	}
 
 	ctx := context.Background()
 
+	// Report the review on the head commit, failing it if the review fails.
+	// A review written to an artifact has no write access: its status is set
+	// by 'ainspector publish'.
+	var status *commitStatus
+	if emitArtifact == "" {
+		status = newCommitStatus(p, env)
+	}
+	status.set(ctx, "pending", "Reviewing modified functions")
+	state, description := "success", "No issues"
+	defer func() {
+		if err != nil {
+			state, description = "failure", "Review failed"
+		}
+		status.set(ctx, state, description)
+	}()
+
+	// Load configuration, fetching configurations extended from other repositories through the provider
+	fetcher, _ := p.(config.RemoteFetcher)
+	cfg, err := config.LoadWithFetcher(ctx, fetcher)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Load accepted findings
+	base, err := baseline.Load(baselinePath)
+	if err != nil {
+		return fmt.Errorf("failed to load baseline: %w", err)
+	}
+
 	// Get modified files
 	fmt.Printf("Fetching modified files...\n")
+	filterFiles(p, cfg)
 	files, err := p.GetModifiedFiles(ctx, env.PRNumber)
 	if err != nil {
 		return fmt.Errorf("failed to get modified files: %w", err)
@@ -113,59 +171,387 @@ func runReview(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Extracted %d modified functions\n", len(functions))
 
-	if len(functions) == 0 {
-		fmt.Println("No functions to review")
-		return nil
+	// Load prompt templates (built-in defaults, optionally overridden in config)
+	prompts, err := llm.LoadPrompts(&cfg.Prompts)
+	if err != nil {
+		return fmt.Errorf("failed to load prompt templates: %w", err)
 	}
+	model := llmModel()
 
-	// Detect languages used in the modified functions
-	languagesMap := make(map[string]bool)
-	for _, fn := range functions {
-		languagesMap[fn.Language] = true
+	// Fingerprint the review settings so that previous reviews are invalidated
+	// according to the configured policy
+	settings := newReviewSettings(cfg, prompts, model)
+
+	fmt.Println("Checking for previously reviewed functions...")
+	existingComments, commentsErr := p.GetReviewComments(ctx, env.PRNumber)
+	if commentsErr != nil {
+		// Log warning but continue - this is not fatal
+		fmt.Printf("Warning: could not fetch existing comments: %v\n", commentsErr)
 	}
-	var languages []string
-	for lang := range languagesMap {
-		languages = append(languages, lang)
+
+	// Comments of functions changed since their review are marked as fixed,
+	// unless the new review reports the same issues again or fails
+	outdated := cache.OutdatedComments(existingComments, functions)
+	openFindings := cache.OpenFindings(existingComments)
+	// previousFindings annotates the open findings of previous runs that this
+	// run neither fixes nor reports again, so that they still count
+	previousFindings := func(fixed []provider.ExistingComment, results []llm.ReviewResult) []provider.Annotation {
+		return cache.FindingAnnotations(cache.PendingFindings(openFindings, fixed, results), files, cfg)
+	}
+	review := &artifact.Review{OutdatedAction: cfg.Comments.OutdatedAction()}
+	// reportFindings derives the commit status from the findings of the
+	// review, including the open findings of previous runs
+	reportFindings := func(noFindings string) {
+		if findingsFail(cfg.Status.Fails, review.Findings) {
+			state = "failure"
+		}
+		description = noFindings
+		if len(review.Findings) > 0 {
+			description = describeFindings(review.Findings)
+		}
+	}
+
+	if len(functions) == 0 {
+		fmt.Println("No functions to review")
+		review.Outdated = outdated
+		review.Findings = previousFindings(outdated, nil)
+		reportFindings("No modified functions to review")
+		if cfg.Checks.IsEnabled() {
+			review.Check = newCheckRun(cfg.Checks, review.Findings, "No modified functions to review."+describePrevious(review.Findings))
+		}
+		return finishReview(ctx, p, env, review)
 	}
 
 	// Filter out already reviewed functions (unless --force is set)
 	functionsToReview := functions
-	if !forceReview {
-		fmt.Println("Checking for previously reviewed functions...")
-		existingComments, err := p.GetReviewComments(ctx, env.PRNumber)
+	if forceReview {
+		fmt.Println("Force flag set - reviewing all modified functions")
+	} else if commentsErr == nil {
+		// Build tracker from existing comments
+		tracker := settings.tracker(existingComments)
+
+		// Filter out already reviewed functions
+		functionsToReview = tracker.FilterUnreviewed(functions)
+		skipped := len(functions) - len(functionsToReview)
+		if skipped > 0 {
+			fmt.Printf("Skipped %d already reviewed functions\n", skipped)
+		}
+	}
+
+	if len(functionsToReview) == 0 {
+		fmt.Println("All modified functions have already been reviewed")
+		review.Outdated = outdated
+		review.Findings = previousFindings(outdated, nil)
+		reportFindings("All modified functions have already been reviewed")
+		if cfg.Checks.IsEnabled() {
+			review.Check = newCheckRun(cfg.Checks, review.Findings, "All modified functions have already been reviewed."+describePrevious(review.Findings))
+		}
+		return finishReview(ctx, p, env, review)
+	}
+
+	// Reuse the results stored by previous runs, including clean ones
+	store := openStore()
+	var results []llm.ReviewResult
+	pending := functionsToReview
+	if store != nil && !forceReview {
+		results, pending = loadStoredResults(store, functionsToReview, settings.storeKey)
+		if len(results) > 0 {
+			fmt.Printf("Loaded %d reviews from the local cache\n", len(results))
+		}
+	}
+
+	if len(pending) > 0 {
+		reviewer, err := newReviewer(ctx, cfg, prompts, functionLanguages(functions))
 		if err != nil {
-			// Log warning but continue - this is not fatal
-			fmt.Printf("Warning: could not fetch existing comments: %v\n", err)
-		} else {
-			// Build tracker from existing comments
-			tracker := cache.NewTracker()
-			var reviewedComments []cache.ReviewedComment
-			for _, c := range existingComments {
-				reviewedComments = append(reviewedComments, cache.ReviewedComment{
-					Path: c.Path,
-					Line: c.Line,
-					Hash: cache.ExtractHash(c.Body),
-					Body: c.Body,
-				})
+			return err
+		}
+
+		// Review with LLM
+		fmt.Printf("Reviewing %d functions with LLM (%s)...\n", len(pending), model)
+		reviewed := reviewer.Review(ctx, pending)
+		if store != nil {
+			storeResults(store, reviewed, settings.storeKey)
+		}
+		results = append(results, reviewed...)
+	}
+
+	if store != nil {
+		if err := store.Prune(); err != nil {
+			fmt.Printf("Warning: failed to prune the local cache: %v\n", err)
+		}
+	}
+
+	// Convert results to review comments with hash markers for caching
+	var comments []provider.ReviewComment
+	var annotations []provider.Annotation
+	suppressed, baselined := 0, 0
+	duplicatesAction := cfg.Comments.DuplicatesAction()
+	confirmed := make(map[int64]bool)
+	var remarked []provider.ExistingComment
+	// reported are the results without the suppressed and baselined findings
+	reported := make([]llm.ReviewResult, 0, len(results))
+	for _, result := range results {
+		kept := result
+		kept.Suggestions = nil
+		if !result.HasIssues() {
+			reported = append(reported, kept)
+			continue
+		}
+
+		// Generate hash and settings fingerprint for this function to enable caching
+		hashMarker := settings.marker(&result.Function)
+
+		for _, suggestion := range result.Suggestions {
+			// Drop findings silenced by an inline suppression comment
+			if result.Function.IsSuppressed(suggestion.Line, suggestion.Rule) {
+				suppressed++
+				continue
+			}
+
+			// Drop findings accepted in the baseline
+			if base.Contains(result.Function.FilePath, result.Function.Name, suggestion.Rule, suggestion.Description) {
+				baselined++
+				continue
+			}
+			kept.Suggestions = append(kept.Suggestions, suggestion)
+
+			// Annotate every finding on the check run, including the ones
+			// already reported by an open comment
+			annotation := provider.Annotation{
+				Path:     result.Function.FilePath,
+				Line:     suggestion.Line,
+				Severity: suggestion.Severity,
+				Title:    result.Function.Name,
+				Message:  suggestion.Description,
+			}
+			if suggestion.Rule != "" {
+				annotation.Message = fmt.Sprintf("[%s] %s", suggestion.Rule, suggestion.Description)
+			}
+			if suggestion.Code != "" {
+				annotation.Details = "Suggested change:\n" + suggestion.Code
+			}
+			annotations = append(annotations, annotation)
+
+			// Don't post findings already reported by an open comment again
+			if duplicatesAction != config.DuplicatesOff {
+				if existing, ok := cache.FindDuplicate(openFindings, result.Function.FilePath, suggestion.Line, suggestion.Rule, suggestion.Description); ok {
+					// Move the existing comment to the current function hash,
+					// so that the function is considered reviewed
+					if !confirmed[existing.ID] && cache.ExtractHash(existing.Body) != settings.hash(&result.Function) {
+						existing.Body = cache.ReplaceMarker(existing.Body, hashMarker)
+						remarked = append(remarked, existing)
+					}
+					confirmed[existing.ID] = true
+					continue
+				}
+			}
+
+			// Reference the violated rule so findings can be traced back to the config
+			body := suggestion.Description
+			if suggestion.Rule != "" {
+				body = fmt.Sprintf("**[%s]** %s", suggestion.Rule, body)
 			}
-			tracker.LoadFromComments(reviewedComments)
 
-			// Filter out already reviewed functions
-			functionsToReview = tracker.FilterUnreviewed(functions)
-			skipped := len(functions) - len(functionsToReview)
-			if skipped > 0 {
-				fmt.Printf("Skipped %d already reviewed functions\n", skipped)
+			// Append hash marker to comment body for future cache detection
+			body += "\n\n" + hashMarker
+			comment := provider.ReviewComment{
+				Path:       result.Function.FilePath,
+				Line:       suggestion.Line,
+				Body:       body,
+				Suggestion: suggestion.Code,
 			}
+			comments = append(comments, comment)
 		}
+		reported = append(reported, kept)
+	}
+
+	fmt.Printf("Found %d issues (out of %d functions reviewed)\n", len(comments), len(results))
+	if suppressed > 0 {
+		fmt.Printf("Dropped %d issues silenced by inline suppression comments\n", suppressed)
+	}
+	if baselined > 0 {
+		fmt.Printf("Suppressed %d issues matching the baseline (%s)\n", baselined, baselinePath)
+	}
+	if len(confirmed) > 0 {
+		fmt.Printf("Skipped issues already reported by %d open comments\n", len(confirmed))
+	}
+
+	review.Comments = comments
+	review.Outdated = cache.FixedComments(outdated, reported)
+	review.Duplicates = remarked
+	review.Bump = duplicatesAction == config.DuplicatesBump
+	previous := previousFindings(review.Outdated, reported)
+	review.Findings = append(annotations, previous...)
+	if cfg.Checks.IsEnabled() {
+		summary := fmt.Sprintf("Reviewed %d of the %d modified functions.", len(results), len(functions))
+		review.Check = newCheckRun(cfg.Checks, review.Findings, summary+describePrevious(previous))
+	}
+	reportFindings(describeFindings(nil))
+	if !cfg.Checks.CommentsEnabled() {
+		// The findings are only reported on the check run
+		review.Comments = nil
+	}
+	return finishReview(ctx, p, env, review)
+}
+
+// finishReview posts the review, or writes it to the artifact file with
+// --emit-artifact
+func finishReview(ctx context.Context, p provider.Provider, env *ci.Environment, review *artifact.Review) error {
+	if emitArtifact == "" {
+		return postReview(ctx, p, env, review)
+	}
+
+	if err := artifact.New(env, review).Save(emitArtifact); err != nil {
+		return fmt.Errorf("failed to write review artifact: %w", err)
+	}
+
+	fmt.Printf("Review written to %s (%d comments), to post with 'ainspector publish'\n", emitArtifact, len(review.Comments))
+	return nil
+}
+
+// postReview marks the outdated comments as fixed, updates the duplicate
+// comments, posts the new comments of a review and creates its check run
+func postReview(ctx context.Context, p provider.Provider, env *ci.Environment, review *artifact.Review) error {
+	markOutdatedComments(ctx, p, env, review.OutdatedAction, review.Outdated)
+	updateDuplicates(ctx, p, env, review.Duplicates, review.Bump)
+
+	if len(review.Comments) == 0 {
+		// Skip posting if no issues to comment on (e.g. only reported on the check run)
+		fmt.Println("No new issues to comment on, skipping review")
 	} else {
-		fmt.Println("Force flag set - reviewing all modified functions")
+		// Post inline review comments
+		fmt.Println("Posting review with inline suggestions...")
+		if err := p.CreateReview(ctx, env.PRNumber, review.Comments); err != nil {
+			return fmt.Errorf("failed to create review: %w", err)
+		}
+		fmt.Println("Review posted successfully!")
 	}
 
-	if len(functionsToReview) == 0 {
-		fmt.Println("All modified functions have already been reviewed")
-		return nil
+	if review.Check != nil {
+		return createCheckRun(ctx, p, review.Check)
+	}
+	return nil
+}
+
+// markOutdatedComments replies to the comments of functions changed since
+// their review that the issue is fixed, and resolves their thread, depending
+// on action (config.OutdatedResolve, OutdatedReply or OutdatedOff)
+func markOutdatedComments(ctx context.Context, p provider.Provider, env *ci.Environment, action string, outdated []provider.ExistingComment) {
+	if action == config.OutdatedOff || len(outdated) == 0 {
+		return
+	}
+
+	reply := "This issue appears to be fixed by the latest changes."
+	if sha := shortSHA(env); sha != "" {
+		reply = fmt.Sprintf("This issue appears to be fixed in %s.", sha)
+	}
+	reply += "\n\n" + cache.ResolvedMarker
+
+	marked := 0
+	resolve := action == config.OutdatedResolve
+	for _, c := range outdated {
+		if err := p.ReplyToComment(ctx, env.PRNumber, c, reply); err != nil {
+			fmt.Printf("Warning: failed to mark comment on %s:%d as fixed: %v\n", c.Path, c.Line, err)
+			continue
+		}
+		if resolve {
+			err := p.ResolveComment(ctx, env.PRNumber, c)
+			if errors.Is(err, provider.ErrResolveUnsupported) {
+				fmt.Println("Warning: the git host cannot resolve review threads, comments of fixed issues are only replied to (comments.outdated: resolve)")
+				resolve = false
+			} else if err != nil {
+				fmt.Printf("Warning: failed to resolve comment on %s:%d: %v\n", c.Path, c.Line, err)
+			}
+		}
+		marked++
+	}
+
+	if marked > 0 {
+		fmt.Printf("Marked %d comments of changed functions as fixed\n", marked)
+	}
+}
+
+// updateDuplicates saves the new hash markers of open comments whose issue
+// was reported again by the review of the changed function and, when bump is
+// set, replies that the issue is still present
+func updateDuplicates(ctx context.Context, p provider.Provider, env *ci.Environment, comments []provider.ExistingComment, bump bool) {
+	updater, canUpdate := p.(provider.CommentUpdater)
+
+	reply := "This issue is still present after the latest changes."
+	if sha := shortSHA(env); sha != "" {
+		reply = fmt.Sprintf("This issue is still present in %s.", sha)
+	}
+
+	for _, c := range comments {
+		if canUpdate {
+			if err := updater.UpdateReviewComment(ctx, env.PRNumber, c.ID, c.Body); err != nil {
+				fmt.Printf("Warning: failed to update comment on %s:%d: %v\n", c.Path, c.Line, err)
+			}
+		}
+		if bump {
+			if err := p.ReplyToComment(ctx, env.PRNumber, c, reply); err != nil {
+				fmt.Printf("Warning: failed to reply to comment on %s:%d: %v\n", c.Path, c.Line, err)
+			}
+		}
+	}
+}
+
+// shortSHA returns the abbreviated head commit of the PR/MR, if known
+func shortSHA(env *ci.Environment) string {
+	if len(env.HeadSHA) > 7 {
+		return env.HeadSHA[:7]
+	}
+	return env.HeadSHA
+}
+
+// filterFiles makes the providers computing patches from the file contents
+// skip the files that won't be reviewed: ignored or unsupported files
+func filterFiles(p provider.Provider, cfg *config.Config) {
+	if filter, ok := p.(provider.FileFilter); ok {
+		filter.SetFileFilter(func(path string) bool {
+			return parser.IsSupported(path) && !cfg.ForPath(path).ShouldIgnore(path)
+		})
+	}
+}
+
+// newProvider creates the git hosting provider of the detected CI environment
+func newProvider(env *ci.Environment) (provider.Provider, error) {
+	switch env.Provider {
+	case "github":
+		if env.AppID != 0 {
+			apiURL := env.APIURL
+			if apiURL == "" {
+				apiURL = provider.GitHubAPIURL
+			}
+			app := provider.GitHubApp{
+				ID:             env.AppID,
+				PrivateKey:     []byte(env.AppPrivateKey),
+				InstallationID: env.AppInstallationID,
+			}
+			return provider.NewGitHubAppProvider(apiURL, env.Owner, env.Repo, app, env.CABundle)
+		}
+		if (env.APIURL != "" && env.APIURL != provider.GitHubAPIURL) || env.CABundle != "" {
+			return provider.NewGitHubEnterpriseProvider(env.APIURL, env.Owner, env.Repo, env.Token, env.CABundle)
+		}
+		return provider.NewGitHubProvider(env.Owner, env.Repo, env.Token), nil
+	case "gitea":
+		return provider.NewGiteaProvider(env.ServerURL, env.Owner, env.Repo, env.Token), nil
+	case "bitbucket":
+		return provider.NewBitbucketProvider(env.Owner, env.Repo, env.Username, env.Token), nil
+	case "azure":
+		return provider.NewAzureDevOpsProvider(env.ServerURL, env.Owner, env.Repo, env.Token), nil
+	case "gerrit":
+		return provider.NewGerritProvider(env.ServerURL, env.Repo, env.HeadSHA, env.Username, env.Token), nil
+	case "bitbucket-server":
+		return provider.NewBitbucketServerProvider(env.ServerURL, env.Owner, env.Repo, env.Token), nil
+	default:
+		return provider.NewGitLabProvider(env.ServerHost, env.Owner, env.Repo, env.Token), nil
 	}
+}
 
+// newReviewer creates the LLM reviewer from the environment variables and the
+// configuration, generating the project context for the given languages
+func newReviewer(ctx context.Context, cfg *config.Config, prompts *llm.Prompts, languages []string) (*llm.Reviewer, error) {
 	// Get LLM config from environment variables
 	apiURL := os.Getenv("LLM_BASE_URL")
 	if apiURL == "" {
@@ -174,16 +560,11 @@ func runReview(cmd *cobra.Command, args []string) error {
 
 	apiKey := os.Getenv("LLM_API_KEY")
 	if apiKey == "" {
-		return fmt.Errorf("LLM_API_KEY environment variable is required")
-	}
-
-	model := os.Getenv("LLM_MODEL")
-	if model == "" {
-		model = "gpt-4o"
+		return nil, fmt.Errorf("LLM_API_KEY environment variable is required")
 	}
 
 	// Create LLM client
-	client := llm.NewClient(apiURL, apiKey, model)
+	client := llm.NewClient(apiURL, apiKey, llmModel())
 
 	// Generate project context
 	fmt.Println("Generating project context...")
@@ -205,48 +586,30 @@ func runReview(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Review with LLM
-	fmt.Printf("Reviewing %d functions with LLM (%s)...\n", len(functionsToReview), model)
-	results := llm.ReviewFunctions(ctx, client, functionsToReview, projectContext, cfg.Rules)
-
-	// Convert results to review comments with hash markers for caching
-	var comments []provider.ReviewComment
-	for _, result := range results {
-		if !result.HasIssues() {
-			continue
-		}
-
-		// Generate hash for this function to enable caching
-		fnHash := cache.FunctionHash(&result.Function)
-		hashMarker := cache.FormatHashMarker(fnHash)
-
-		for _, suggestion := range result.Suggestions {
-			// Append hash marker to comment body for future cache detection
-			body := suggestion.Description + "\n\n" + hashMarker
-			comment := provider.ReviewComment{
-				Path:       result.Function.FilePath,
-				Line:       suggestion.Line,
-				Body:       body,
-				Suggestion: suggestion.Code,
-			}
-			comments = append(comments, comment)
-		}
-	}
-
-	fmt.Printf("Found %d issues (out of %d functions reviewed)\n", len(comments), len(results))
+	reviewer := llm.NewReviewer(client, prompts)
+	reviewer.ProjectContext = projectContext
+	reviewer.Config = cfg
+	reviewer.ProjectRoot = projectRoot
+	return reviewer, nil
+}
 
-	// Skip posting if no issues found
-	if len(comments) == 0 {
-		fmt.Println("No issues found, skipping review")
-		return nil
+// llmModel returns the LLM model name from the environment
+func llmModel() string {
+	if model := os.Getenv("LLM_MODEL"); model != "" {
+		return model
 	}
+	return "gpt-4o"
+}
 
-	// Post inline review comments
-	fmt.Println("Posting review with inline suggestions...")
-	if err := p.CreateReview(ctx, env.PRNumber, comments); err != nil {
-		return fmt.Errorf("failed to create review: %w", err)
+// functionLanguages returns the languages used by functions
+func functionLanguages(functions []extractor.ExtractedFunction) []string {
+	seen := make(map[string]bool)
+	var languages []string
+	for _, fn := range functions {
+		if !seen[fn.Language] {
+			seen[fn.Language] = true
+			languages = append(languages, fn.Language)
+		}
 	}
-
-	fmt.Println("Review posted successfully!")
-	return nil
+	return languages
 }
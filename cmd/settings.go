@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/iq2i/ainspector/internal/cache"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// reviewSettings decides whether functions need a new review: the function
+// hashing mode, the settings fingerprint and the invalidation policy
+type reviewSettings struct {
+	hash          func(fn *extractor.ExtractedFunction) string
+	fingerprinter *llm.Fingerprinter
+	policy        string
+	model         string
+	prompts       *llm.Prompts
+}
+
+// newReviewSettings creates the review settings of the configuration
+func newReviewSettings(cfg *config.Config, prompts *llm.Prompts, model string) *reviewSettings {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		projectRoot = "."
+	}
+
+	return &reviewSettings{
+		hash:          cache.Hasher(cfg.Cache.HashMode()),
+		fingerprinter: &llm.Fingerprinter{Config: cfg, Prompts: prompts, Model: model, ProjectRoot: projectRoot},
+		policy:        cfg.Cache.InvalidatePolicy(),
+		model:         model,
+		prompts:       prompts,
+	}
+}
+
+// tracker creates a tracker of the functions reviewed in existing comments
+func (s *reviewSettings) tracker(comments []provider.ExistingComment) *cache.Tracker {
+	tracker := cache.NewTracker()
+	tracker.Hash = s.hash
+	tracker.Fingerprint = s.fingerprinter.Fingerprint
+	tracker.Policy = s.policy
+
+	var reviewedComments []cache.ReviewedComment
+	for _, c := range comments {
+		reviewedComments = append(reviewedComments, cache.ReviewedComment{
+			Path:        c.Path,
+			Line:        c.Line,
+			Hash:        cache.ExtractHash(c.Body),
+			Fingerprint: cache.ExtractFingerprint(c.Body),
+			Body:        c.Body,
+		})
+	}
+	tracker.LoadFromComments(reviewedComments)
+
+	return tracker
+}
+
+// marker returns the hash marker appended to the review comments of fn
+func (s *reviewSettings) marker(fn *extractor.ExtractedFunction) string {
+	return cache.FormatMarker(s.hash(fn), cache.FunctionID(fn), s.fingerprinter.Fingerprint(fn))
+}
+
+// storeKey returns the key of the review result of fn in the local cache
+func (s *reviewSettings) storeKey(fn *extractor.ExtractedFunction) string {
+	return cache.StoreKey(s.hash(fn), s.model, s.prompts.Version(), s.fingerprinter.Fingerprint(fn))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// commitStatus sets the provider.StatusContext status of the head commit of
+// the PR/MR, linking to the CI run
+type commitStatus struct {
+	reporter  provider.StatusReporter
+	sha       string
+	targetURL string
+}
+
+// newCommitStatus returns the commit status of the review, or nil if the
+// provider doesn't support commit statuses
+func newCommitStatus(p provider.Provider, env *ci.Environment) *commitStatus {
+	reporter, ok := p.(provider.StatusReporter)
+	if !ok {
+		return nil
+	}
+	return &commitStatus{reporter: reporter, sha: env.HeadSHA, targetURL: env.RunURL}
+}
+
+// set updates the commit status. Failures are only reported as warnings, as
+// the token may not be allowed to set commit statuses.
+func (s *commitStatus) set(ctx context.Context, state, description string) {
+	if s == nil {
+		return
+	}
+
+	status := provider.CommitStatus{SHA: s.sha, State: state, Description: description, TargetURL: s.targetURL}
+	if err := s.reporter.SetCommitStatus(ctx, status); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
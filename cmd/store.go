@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iq2i/ainspector/internal/cache"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+)
+
+var (
+	cacheDir     string
+	cacheTTL     time.Duration
+	cacheMaxSize int64
+	noCache      bool
+)
+
+func init() {
+	reviewCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory of the local review cache (default: $AINSPECTOR_CACHE_DIR or the user cache directory)")
+	reviewCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", cache.DefaultTTL, "Lifetime of the local review cache entries")
+	reviewCmd.Flags().Int64Var(&cacheMaxSize, "cache-max-size", cache.DefaultMaxSize>>20, "Maximum size of the local review cache in MB")
+	reviewCmd.Flags().BoolVar(&noCache, "no-cache", false, "Disable the local review cache")
+}
+
+// openStore opens the local review cache configured with the flags.
+// Returns nil when the cache is disabled or can't be opened.
+func openStore() cache.Store {
+	if noCache {
+		return nil
+	}
+
+	dir := cacheDir
+	if dir == "" {
+		dir = cache.DefaultDir()
+	}
+
+	store, err := cache.NewDirStore(dir)
+	if err != nil {
+		fmt.Printf("Warning: local review cache disabled: %v\n", err)
+		return nil
+	}
+	store.TTL = cacheTTL
+	store.MaxSize = cacheMaxSize << 20
+
+	return store
+}
+
+// loadStoredResults splits functions into the results found in the store and
+// the functions still to review
+func loadStoredResults(store cache.Store, functions []extractor.ExtractedFunction, key func(*extractor.ExtractedFunction) string) ([]llm.ReviewResult, []extractor.ExtractedFunction) {
+	var results []llm.ReviewResult
+	var pending []extractor.ExtractedFunction
+
+	for _, fn := range functions {
+		if entry, ok := store.Get(key(&fn)); ok {
+			results = append(results, entry.Result(fn))
+			continue
+		}
+		pending = append(pending, fn)
+	}
+
+	return results, pending
+}
+
+// storeResults saves the successful review results in the store
+func storeResults(store cache.Store, results []llm.ReviewResult, key func(*extractor.ExtractedFunction) string) {
+	for i := range results {
+		result := &results[i]
+		if result.Error != nil {
+			continue
+		}
+
+		if err := store.Put(cache.NewEntry(key(&result.Function), result)); err != nil {
+			fmt.Printf("Warning: failed to cache review of %s: %v\n", result.Function.Name, err)
+		}
+	}
+}
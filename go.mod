@@ -3,6 +3,7 @@ module github.com/iq2i/ainspector
 go 1.24.0
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.9.2
 	github.com/google/go-github/v57 v57.0.0
 	github.com/sourcegraph/go-diff v0.7.0
 	github.com/spf13/cobra v1.10.2
@@ -24,10 +25,10 @@ require (
 	github.com/tree-sitter/tree-sitter-typescript v0.23.2
 	gitlab.com/gitlab-org/api/client-go v1.15.0
 	golang.org/x/oauth2 v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/bmatcuk/doublestar/v4 v4.9.2 // indirect
 	github.com/google/go-querystring v1.2.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-retryablehttp v0.7.8 // indirect
@@ -35,7 +36,6 @@ require (
 	github.com/mattn/go-pointer v0.0.1 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	golang.org/x/time v0.14.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 // Replace directives to fix module path mismatches in tree-sitter packages
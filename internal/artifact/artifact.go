@@ -0,0 +1,252 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/cache"
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/diff"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// DefaultPath is the review artifact file used when none is specified
+const DefaultPath = "ainspector-review.json"
+
+// Version is the current version of the artifact file format
+const Version = 1
+
+// Review holds the changes of a review to make on a PR/MR
+type Review struct {
+	Comments       []provider.ReviewComment   // New comments
+	Outdated       []provider.ExistingComment // Comments of changed functions whose issue is fixed
+	OutdatedAction string                     // What to do with outdated comments (config.OutdatedResolve, OutdatedReply or OutdatedOff)
+	Duplicates     []provider.ExistingComment // Open comments whose issue was reported again, with their new body
+	Bump           bool                       // Whether to reply to duplicates that the issue is still present
+	Findings       []provider.Annotation      // All the findings, including the ones not commented on
+	Check          *provider.CheckRun         // Check run reporting the findings, if enabled
+}
+
+// Artifact is a review written to a file by a workflow without write access
+// to the PR/MR (e.g. for pull requests from forks), to be posted by a trusted
+// workflow. Its content is untrusted: existing comments are only referenced
+// by ID, and the review is validated against the PR/MR before posting.
+type Artifact struct {
+	Version    int    `json:"version"`
+	Provider   string `json:"provider"`
+	Repository string `json:"repository"`
+	PRNumber   int    `json:"pr_number"`
+	HeadSHA    string `json:"head_sha"`
+
+	Comments       []Comment   `json:"comments"`
+	Outdated       []int64     `json:"outdated,omitempty"`
+	OutdatedAction string      `json:"outdated_action"`
+	Duplicates     []Duplicate `json:"duplicates,omitempty"`
+	Bump           bool        `json:"bump,omitempty"`
+	Findings       []Finding   `json:"findings,omitempty"`
+}
+
+// Comment is a new comment of an artifact
+type Comment struct {
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	Body       string `json:"body"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Duplicate is an open comment of an artifact whose issue was reported again
+type Duplicate struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// Finding is a finding of an artifact, from which the trusted workflow
+// creates the check run and the commit status
+type Finding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Details  string `json:"details,omitempty"`
+}
+
+// New creates the artifact of the review of the PR/MR of env
+func New(env *ci.Environment, review *Review) *Artifact {
+	a := &Artifact{
+		Version:        Version,
+		Provider:       env.Provider,
+		Repository:     env.Owner + "/" + env.Repo,
+		PRNumber:       env.PRNumber,
+		HeadSHA:        env.HeadSHA,
+		Comments:       make([]Comment, 0, len(review.Comments)),
+		OutdatedAction: review.OutdatedAction,
+		Bump:           review.Bump,
+	}
+	for _, c := range review.Comments {
+		a.Comments = append(a.Comments, Comment(c))
+	}
+	for _, c := range review.Outdated {
+		a.Outdated = append(a.Outdated, c.ID)
+	}
+	for _, c := range review.Duplicates {
+		a.Duplicates = append(a.Duplicates, Duplicate{ID: c.ID, Body: c.Body})
+	}
+	for _, f := range review.Findings {
+		a.Findings = append(a.Findings, Finding(f))
+	}
+
+	return a
+}
+
+// Load reads an artifact file
+func Load(path string) (*Artifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var a Artifact
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse review artifact %s: %w", path, err)
+	}
+	if a.Version != Version {
+		return nil, fmt.Errorf("review artifact %s has unsupported version %d (expected %d)", path, a.Version, Version)
+	}
+
+	return &a, nil
+}
+
+// Save writes the artifact to a file
+func (a *Artifact) Save(path string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Validate checks that the artifact is the review of the PR/MR of env (whose
+// number is taken from the artifact if unknown) at headSHA, its current head
+// commit
+func (a *Artifact) Validate(env *ci.Environment, headSHA string) error {
+	if a.Provider != env.Provider {
+		return fmt.Errorf("artifact is for %s, not %s", a.Provider, env.Provider)
+	}
+	if repository := env.Owner + "/" + env.Repo; a.Repository != repository {
+		return fmt.Errorf("artifact is for repository %s, not %s", a.Repository, repository)
+	}
+	if a.PRNumber <= 0 {
+		return fmt.Errorf("artifact has no PR/MR number")
+	}
+	if env.PRNumber != 0 && a.PRNumber != env.PRNumber {
+		return fmt.Errorf("artifact is for PR/MR #%d, not #%d", a.PRNumber, env.PRNumber)
+	}
+
+	// The commit of the CI run is trusted, unlike the one of the artifact
+	if env.HeadSHA != "" && a.HeadSHA != env.HeadSHA {
+		return fmt.Errorf("artifact is for commit %s, not %s", a.HeadSHA, env.HeadSHA)
+	}
+	if a.HeadSHA == "" || a.HeadSHA != headSHA {
+		return fmt.Errorf("artifact is for commit %q but the head of PR/MR #%d is %s", a.HeadSHA, a.PRNumber, headSHA)
+	}
+
+	return nil
+}
+
+// Review returns the review of the artifact, checking that its comments are
+// on lines of the diff of the PR/MR files, that its findings are on these
+// files, and that it only marks or updates open ainspector comments, without
+// changing their text. The check run of the review is left to the caller, to
+// be created from the findings with the trusted configuration.
+func (a *Artifact) Review(files []provider.ModifiedFile, existing []provider.ExistingComment) (*Review, error) {
+	switch a.OutdatedAction {
+	case config.OutdatedResolve, config.OutdatedReply, config.OutdatedOff:
+	default:
+		return nil, fmt.Errorf("invalid outdated action %q", a.OutdatedAction)
+	}
+
+	review := &Review{
+		OutdatedAction: a.OutdatedAction,
+		Bump:           a.Bump,
+	}
+	var problems []string
+
+	// Comments can only be posted on the lines shown in the diff
+	lines := make(map[string]map[int]bool, len(files))
+	for _, f := range files {
+		if f.Status == "deleted" {
+			continue
+		}
+		modified, err := diff.ParsePatch(f.Patch)
+		if err != nil {
+			continue
+		}
+		lines[f.Path] = make(map[int]bool, len(modified.Added)+len(modified.Context))
+		for _, line := range modified.Added {
+			lines[f.Path][line] = true
+		}
+		for _, line := range modified.Context {
+			lines[f.Path][line] = true
+		}
+	}
+	for _, c := range a.Comments {
+		if !lines[c.Path][c.Line] {
+			problems = append(problems, fmt.Sprintf("comment on %s:%d is not on a line of the diff", c.Path, c.Line))
+			continue
+		}
+		review.Comments = append(review.Comments, provider.ReviewComment(c))
+	}
+
+	// Findings can be on any line of the files
+	for _, f := range a.Findings {
+		if _, ok := lines[f.Path]; !ok || f.Line <= 0 {
+			problems = append(problems, fmt.Sprintf("finding on %s:%d is not on a file of the PR/MR", f.Path, f.Line))
+			continue
+		}
+		if !config.IsValidSeverity(f.Severity) {
+			problems = append(problems, fmt.Sprintf("finding on %s:%d has invalid severity %q", f.Path, f.Line, f.Severity))
+			continue
+		}
+		review.Findings = append(review.Findings, provider.Annotation(f))
+	}
+
+	// Outdated and duplicate comments must be open ainspector comments
+	byID := make(map[int64]provider.ExistingComment)
+	for _, c := range cache.OpenFindings(existing) {
+		byID[c.ID] = c
+	}
+	for _, id := range a.Outdated {
+		c, ok := byID[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("outdated comment %d is not an open ainspector comment of the PR/MR", id))
+			continue
+		}
+		review.Outdated = append(review.Outdated, c)
+	}
+	for _, d := range a.Duplicates {
+		c, ok := byID[d.ID]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("duplicate comment %d is not an open ainspector comment of the PR/MR", d.ID))
+			continue
+		}
+
+		// Only the hash marker of the comment may change
+		hash := cache.ExtractHash(d.Body)
+		if hash == "" || cache.ReplaceMarker(c.Body, cache.FormatMarker(hash, cache.ExtractFunctionID(d.Body), cache.ExtractFingerprint(d.Body))) != d.Body {
+			problems = append(problems, fmt.Sprintf("duplicate comment %d changes more than the hash marker", d.ID))
+			continue
+		}
+		c.Body = d.Body
+		review.Duplicates = append(review.Duplicates, c)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid review artifact:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return review, nil
+}
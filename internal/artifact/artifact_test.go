@@ -0,0 +1,312 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/cache"
+	"github.com/iq2i/ainspector/internal/ci"
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+const headSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func testEnv() *ci.Environment {
+	return &ci.Environment{
+		Provider: "github",
+		Owner:    "owner",
+		Repo:     "repo",
+		PRNumber: 12,
+		HeadSHA:  headSHA,
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	review := &Review{
+		Comments: []provider.ReviewComment{
+			{Path: "main.go", Line: 3, Body: "Unchecked error\n\n" + cache.FormatHashMarker("aaaaaaaaaaaa"), Suggestion: "if err != nil {"},
+		},
+		Outdated:       []provider.ExistingComment{{ID: 1, Body: "old"}},
+		OutdatedAction: config.OutdatedResolve,
+		Duplicates:     []provider.ExistingComment{{ID: 2, Body: "still there"}},
+		Bump:           true,
+		Findings:       []provider.Annotation{{Path: "main.go", Line: 3, Severity: "critical", Title: "main", Message: "Unchecked error"}},
+		Check:          &provider.CheckRun{Conclusion: "failure"},
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := New(testEnv(), review).Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Version != Version || a.Provider != "github" || a.Repository != "owner/repo" || a.PRNumber != 12 || a.HeadSHA != headSHA {
+		t.Errorf("unexpected header: %+v", a)
+	}
+	if len(a.Comments) != 1 || a.Comments[0] != Comment(review.Comments[0]) {
+		t.Errorf("unexpected comments: %+v", a.Comments)
+	}
+	if len(a.Outdated) != 1 || a.Outdated[0] != 1 || a.OutdatedAction != config.OutdatedResolve {
+		t.Errorf("unexpected outdated comments: %v (%s)", a.Outdated, a.OutdatedAction)
+	}
+	if len(a.Duplicates) != 1 || a.Duplicates[0] != (Duplicate{ID: 2, Body: "still there"}) || !a.Bump {
+		t.Errorf("unexpected duplicates: %+v (bump: %v)", a.Duplicates, a.Bump)
+	}
+	if len(a.Findings) != 1 || a.Findings[0] != Finding(review.Findings[0]) {
+		t.Errorf("unexpected findings: %+v", a.Findings)
+	}
+
+	// The check run is created by the trusted workflow
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "conclusion") {
+		t.Errorf("expected no check run in the artifact, got %s", data)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"invalid.json": "not json",
+		"future.json":  `{"version": 2}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"invalid.json", "future.json", "missing.json"} {
+		if _, err := Load(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(a *Artifact, env *ci.Environment)
+		head    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			head: headSHA,
+		},
+		{
+			name:   "PR number from the artifact",
+			modify: func(a *Artifact, env *ci.Environment) { env.PRNumber = 0 },
+			head:   headSHA,
+		},
+		{
+			name:    "other provider",
+			modify:  func(a *Artifact, env *ci.Environment) { a.Provider = "gitlab" },
+			head:    headSHA,
+			wantErr: "artifact is for gitlab",
+		},
+		{
+			name:    "other repository",
+			modify:  func(a *Artifact, env *ci.Environment) { a.Repository = "owner/other" },
+			head:    headSHA,
+			wantErr: "artifact is for repository owner/other",
+		},
+		{
+			name:    "other PR",
+			modify:  func(a *Artifact, env *ci.Environment) { a.PRNumber = 13 },
+			head:    headSHA,
+			wantErr: "artifact is for PR/MR #13",
+		},
+		{
+			name:    "no PR",
+			modify:  func(a *Artifact, env *ci.Environment) { a.PRNumber, env.PRNumber = 0, 0 },
+			head:    headSHA,
+			wantErr: "no PR/MR number",
+		},
+		{
+			name:    "other commit than the CI run",
+			modify:  func(a *Artifact, env *ci.Environment) { a.HeadSHA = "f00" },
+			head:    "f00",
+			wantErr: "artifact is for commit f00",
+		},
+		{
+			name:    "PR updated since",
+			head:    "fedcba9876543210fedcba9876543210fedcba98",
+			wantErr: "but the head of PR/MR #12 is fedcba98",
+		},
+		{
+			name:    "no commit",
+			modify:  func(a *Artifact, env *ci.Environment) { a.HeadSHA, env.HeadSHA = "", "" },
+			head:    "",
+			wantErr: "artifact is for commit \"\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := testEnv()
+			a := New(env, &Review{})
+			if tt.modify != nil {
+				tt.modify(a, env)
+			}
+
+			err := a.Validate(env, tt.head)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReview(t *testing.T) {
+	files := []provider.ModifiedFile{
+		{Path: "main.go", Status: "modified", Patch: "@@ -1,3 +1,4 @@\n line1\n+added\n line2\n line3"},
+		{Path: "old.go", Status: "deleted", Patch: "@@ -1,1 +0,0 @@\n-gone"},
+	}
+
+	marker := cache.FormatHashMarker("aaaaaaaaaaaa")
+	newMarker := cache.FormatHashMarker("bbbbbbbbbbbb")
+	existing := []provider.ExistingComment{
+		{ID: 1, Path: "main.go", Line: 2, Body: "**[errors]** Unchecked error\n\n" + marker},
+		{ID: 2, Path: "main.go", Line: 3, Body: "Missing test\n\n" + marker},
+		{ID: 3, InReplyTo: 1, Path: "main.go", Line: 2, Body: "I disagree"},
+		{ID: 4, Path: "main.go", Line: 1, Body: "A comment from a human"},
+		{ID: 5, Path: "main.go", Line: 4, Body: "Fixed issue\n\n" + marker, Resolved: true},
+	}
+
+	tests := []struct {
+		name         string
+		artifact     Artifact
+		wantErr      []string
+		wantComments int
+	}{
+		{
+			name: "valid",
+			artifact: Artifact{
+				Comments: []Comment{
+					{Path: "main.go", Line: 2, Body: "On an added line"},
+					{Path: "main.go", Line: 4, Body: "On a context line", Suggestion: "line3()"},
+				},
+				Outdated:       []int64{2},
+				OutdatedAction: config.OutdatedReply,
+				Duplicates:     []Duplicate{{ID: 1, Body: "**[errors]** Unchecked error\n\n" + newMarker}},
+			},
+			wantComments: 2,
+		},
+		{
+			name: "comments outside of the diff",
+			artifact: Artifact{
+				Comments: []Comment{
+					{Path: "main.go", Line: 10, Body: "Out of the hunks"},
+					{Path: "other.go", Line: 1, Body: "Not in the PR"},
+					{Path: "old.go", Line: 1, Body: "Deleted file"},
+				},
+				OutdatedAction: config.OutdatedOff,
+			},
+			wantErr: []string{"main.go:10", "other.go:1", "old.go:1"},
+		},
+		{
+			name: "comments other than open ainspector comments",
+			artifact: Artifact{
+				Outdated:       []int64{3, 4, 5, 99},
+				OutdatedAction: config.OutdatedResolve,
+				Duplicates:     []Duplicate{{ID: 4, Body: "A comment from a human\n\n" + newMarker}},
+			},
+			wantErr: []string{"outdated comment 3", "outdated comment 4", "outdated comment 5", "outdated comment 99", "duplicate comment 4"},
+		},
+		{
+			name: "duplicate with a new text",
+			artifact: Artifact{
+				OutdatedAction: config.OutdatedResolve,
+				Duplicates: []Duplicate{
+					{ID: 1, Body: "Click this link\n\n" + newMarker},
+					{ID: 2, Body: "Missing test"},
+				},
+			},
+			wantErr: []string{"duplicate comment 1 changes more than the hash marker", "duplicate comment 2 changes more than the hash marker"},
+		},
+		{
+			name: "findings",
+			artifact: Artifact{
+				OutdatedAction: config.OutdatedOff,
+				Findings: []Finding{
+					{Path: "main.go", Line: 10, Severity: "info", Message: "Outside of the hunks"},
+				},
+			},
+		},
+		{
+			name: "invalid findings",
+			artifact: Artifact{
+				OutdatedAction: config.OutdatedOff,
+				Findings: []Finding{
+					{Path: "other.go", Line: 1, Severity: "info", Message: "Not in the PR"},
+					{Path: "main.go", Line: 0, Severity: "info", Message: "No line"},
+					{Path: "main.go", Line: 1, Severity: "fatal", Message: "Unknown severity"},
+				},
+			},
+			wantErr: []string{"finding on other.go:1", "finding on main.go:0", `invalid severity "fatal"`},
+		},
+		{
+			name:     "invalid outdated action",
+			artifact: Artifact{OutdatedAction: "delete"},
+			wantErr:  []string{`invalid outdated action "delete"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, err := tt.artifact.Review(files, existing)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("expected error containing %q, got %v", want, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(review.Comments) != tt.wantComments {
+				t.Errorf("expected %d comments, got %d", tt.wantComments, len(review.Comments))
+			}
+			if review.OutdatedAction != tt.artifact.OutdatedAction {
+				t.Errorf("expected outdated action %s, got %s", tt.artifact.OutdatedAction, review.OutdatedAction)
+			}
+			if review.Check != nil {
+				t.Errorf("expected no check run, got %+v", review.Check)
+			}
+			if tt.artifact.Findings != nil {
+				if len(review.Findings) != len(tt.artifact.Findings) {
+					t.Errorf("unexpected findings: %+v", review.Findings)
+				}
+				return
+			}
+			// Existing comments come from the PR/MR, with their thread and position
+			if len(review.Outdated) != 1 || review.Outdated[0] != existing[1] {
+				t.Errorf("unexpected outdated comments: %+v", review.Outdated)
+			}
+			if len(review.Duplicates) != 1 || review.Duplicates[0].ID != 1 || review.Duplicates[0].Line != 2 || review.Duplicates[0].Body != tt.artifact.Duplicates[0].Body {
+				t.Errorf("unexpected duplicates: %+v", review.Duplicates)
+			}
+		})
+	}
+}
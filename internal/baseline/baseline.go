@@ -0,0 +1,148 @@
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultPath is the baseline file used when none is specified
+const DefaultPath = ".ainspector-baseline.json"
+
+// Version is the current version of the baseline file format
+const Version = 1
+
+// FingerprintLength is the length of the short fingerprint
+const FingerprintLength = 16
+
+// Baseline holds the findings accepted when ainspector was enabled on a
+// repository. Findings matching the baseline are not reported.
+type Baseline struct {
+	Version  int     `json:"version"`
+	Findings []Entry `json:"findings"`
+
+	index map[string]bool
+}
+
+// Entry is an accepted finding
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	Function    string `json:"function"`
+	Rule        string `json:"rule,omitempty"`
+	Description string `json:"description"`
+}
+
+// New creates an empty baseline
+func New() *Baseline {
+	return &Baseline{Version: Version, index: make(map[string]bool)}
+}
+
+// Load reads a baseline file.
+// Returns an empty baseline (not an error) if the file doesn't exist
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil
+		}
+		return nil, err
+	}
+
+	b := New()
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Version > Version {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d (expected %d)", path, b.Version, Version)
+	}
+
+	// Fingerprints are computed again, as baselines written by older versions
+	// identified findings of a rule by the rule alone
+	for i := range b.Findings {
+		entry := &b.Findings[i]
+		entry.Fingerprint = Fingerprint(entry.Path, entry.Function, entry.Rule, entry.Description)
+		b.index[entry.Fingerprint] = true
+	}
+	return b, nil
+}
+
+// Save writes the baseline to a file, sorted so that diffs stay readable
+func (b *Baseline) Save(path string) error {
+	sort.Slice(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		if x.Path != y.Path {
+			return x.Path < y.Path
+		}
+		if x.Function != y.Function {
+			return x.Function < y.Function
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Add records a finding. Returns false if an identical finding is already recorded.
+func (b *Baseline) Add(path, function, rule, description string) bool {
+	fingerprint := Fingerprint(path, function, rule, description)
+	if b.index[fingerprint] {
+		return false
+	}
+
+	b.index[fingerprint] = true
+	b.Findings = append(b.Findings, Entry{
+		Fingerprint: fingerprint,
+		Path:        path,
+		Function:    function,
+		Rule:        rule,
+		Description: description,
+	})
+	return true
+}
+
+// Contains reports whether a finding matches the baseline
+func (b *Baseline) Contains(path, function, rule, description string) bool {
+	return b.index[Fingerprint(path, function, rule, description)]
+}
+
+// Len returns the number of accepted findings
+func (b *Baseline) Len() int {
+	return len(b.Findings)
+}
+
+// Fingerprint identifies a finding independently of its line number, by its
+// rule and normalised description, so that line shifts don't matter. The
+// description is always part of it: baselining a finding of a rule doesn't
+// hide new findings of the same rule in the function.
+func Fingerprint(path, function, rule, description string) string {
+	hash := sha256.Sum256([]byte(path + "\x00" + function + "\x00" + rule + "\x00" + Normalize(description)))
+	return hex.EncodeToString(hash[:])[:FingerprintLength]
+}
+
+// Normalize lowercases a description and drops punctuation, digits (line
+// numbers, counts) and extra whitespace
+func Normalize(description string) string {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(description)) {
+		word := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return r
+			}
+			return -1
+		}, field)
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
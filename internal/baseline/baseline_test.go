@@ -0,0 +1,144 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"SQL injection on line 42!", "sql injection on line"},
+		{"  Missing   error\nhandling.  ", "missing error handling"},
+		{"Use `fmt.Errorf` (with %w)", "use fmterrorf with w"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("main.go", "run", "", "SQL injection on line 42")
+
+	if len(base) != FingerprintLength {
+		t.Errorf("expected fingerprint of length %d, got %q", FingerprintLength, base)
+	}
+	if got := Fingerprint("main.go", "run", "", "sql injection on line 57."); got != base {
+		t.Error("expected line numbers, case and punctuation to be ignored")
+	}
+	if got := Fingerprint("other.go", "run", "", "SQL injection on line 42"); got == base {
+		t.Error("expected path to change the fingerprint")
+	}
+	if got := Fingerprint("main.go", "main", "", "SQL injection on line 42"); got == base {
+		t.Error("expected function to change the fingerprint")
+	}
+	if Fingerprint("main.go", "run", "sql", "Query built from input on line 3") != Fingerprint("main.go", "run", "sql", "query built from input on line 8") {
+		t.Error("expected findings of a rule to ignore line numbers")
+	}
+	if Fingerprint("main.go", "run", "sql", "Query built from user input") == Fingerprint("main.go", "run", "sql", "Table name built from a header") {
+		t.Error("expected different findings of the same rule to have different fingerprints")
+	}
+	if Fingerprint("main.go", "run", "sql", "SQL injection on line 42") == base {
+		t.Error("expected rule to change the fingerprint")
+	}
+}
+
+func TestBaseline_AddContains(t *testing.T) {
+	b := New()
+
+	if !b.Add("main.go", "run", "", "Unchecked error") {
+		t.Error("expected first Add to record the finding")
+	}
+	if b.Add("main.go", "run", "", "unchecked error.") {
+		t.Error("expected equivalent finding not to be recorded twice")
+	}
+	if b.Len() != 1 {
+		t.Errorf("expected 1 finding, got %d", b.Len())
+	}
+
+	if !b.Contains("main.go", "run", "", "Unchecked ERROR") {
+		t.Error("expected matching finding to be contained")
+	}
+	if b.Contains("main.go", "run", "", "Another issue") {
+		t.Error("expected other finding not to be contained")
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, DefaultPath)
+
+	t.Run("missing file returns empty baseline", func(t *testing.T) {
+		b, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b.Len() != 0 {
+			t.Errorf("expected empty baseline, got %d findings", b.Len())
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		b := New()
+		b.Add("z.go", "b", "", "Second")
+		b.Add("a.go", "a", "sql", "First")
+		if err := b.Save(path); err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if strings.Index(string(data), "a.go") > strings.Index(string(data), "z.go") {
+			t.Error("expected findings to be sorted by path")
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if loaded.Len() != 2 {
+			t.Fatalf("expected 2 findings, got %d", loaded.Len())
+		}
+		if !loaded.Contains("a.go", "a", "sql", "first.") {
+			t.Error("expected loaded baseline to contain the rule finding")
+		}
+		if !loaded.Contains("z.go", "b", "", "second") {
+			t.Error("expected loaded baseline to contain the description finding")
+		}
+	})
+
+	t.Run("stale fingerprints are computed again", func(t *testing.T) {
+		_ = os.WriteFile(path, []byte(`{"version": 1, "findings": [{"fingerprint": "0123456789abcdef", "path": "a.go", "function": "a", "rule": "sql", "description": "First"}]}`), 0644)
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !loaded.Contains("a.go", "a", "sql", "First") {
+			t.Error("expected the finding to match its recomputed fingerprint")
+		}
+		if loaded.Contains("a.go", "a", "sql", "Another query") {
+			t.Error("expected other findings of the rule not to match")
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		_ = os.WriteFile(path, []byte("not json"), 0644)
+		if _, err := Load(path); err == nil {
+			t.Error("expected error for invalid file")
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_ = os.WriteFile(path, []byte(`{"version": 99, "findings": []}`), 0644)
+		if _, err := Load(path); err == nil {
+			t.Error("expected error for unsupported version")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/baseline"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// Thresholds for findings to be considered duplicates of an existing comment
+const (
+	// DuplicateLineDistance is the maximum number of lines between the findings
+	DuplicateLineDistance = 5
+	// DuplicateSimilarity is the minimum similarity of the descriptions, from
+	// 0 (no word in common) to 1 (same words)
+	DuplicateSimilarity = 0.6
+)
+
+// ruleRegex matches the rule reference starting a comment: **[rule-id]**
+var ruleRegex = regexp.MustCompile(`^\*\*\[([^\]]+)\]\*\*\s*`)
+
+// FindDuplicate returns the finding among open (see OpenFindings) reporting
+// the same issue as a new finding: on the same file, a nearby line, and for
+// the same rule or with a similar description
+func FindDuplicate(open []provider.ExistingComment, path string, line int, rule, description string) (provider.ExistingComment, bool) {
+	for _, c := range open {
+		if c.Path != path || abs(c.Line-line) > DuplicateLineDistance {
+			continue
+		}
+
+		existingRule, existingDescription := parseFinding(c.Body)
+		if rule != "" && existingRule != "" {
+			if rule == existingRule {
+				return c, true
+			}
+			continue
+		}
+
+		if Similarity(description, existingDescription) >= DuplicateSimilarity {
+			return c, true
+		}
+	}
+
+	return provider.ExistingComment{}, false
+}
+
+// Similarity returns the similarity of two descriptions: the Jaccard index of
+// their normalized words
+func Similarity(a, b string) float64 {
+	wordsA := wordSet(a)
+	wordsB := wordSet(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+// parseFinding extracts the rule and the description of an ainspector comment
+func parseFinding(body string) (rule, description string) {
+	if i := strings.Index(body, "<!-- ainspector:"); i >= 0 {
+		body = body[:i]
+	}
+	body = strings.TrimSpace(body)
+
+	if m := ruleRegex.FindStringSubmatch(body); m != nil {
+		return m[1], body[len(m[0]):]
+	}
+	return "", body
+}
+
+// wordSet returns the set of normalized words of a description
+func wordSet(description string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(baseline.Normalize(description)) {
+		words[word] = true
+	}
+	return words
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+func TestFindDuplicate(t *testing.T) {
+	marker := FormatHashMarker("aaaaaaaaaaaa")
+	open := []provider.ExistingComment{
+		{ID: 1, Path: "main.go", Line: 10, Body: "Error returned by Close is not checked.\n\n" + marker + "\n\n```suggestion\ndefer f.Close()\n```"},
+		{ID: 2, Path: "main.go", Line: 40, Body: "**[sql-prepared]** Query built by string concatenation\n\n" + marker},
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		line        int
+		rule        string
+		description string
+		wantID      int64
+	}{
+		{name: "same description", path: "main.go", line: 10, description: "Error returned by Close is not checked.", wantID: 1},
+		{name: "reworded nearby", path: "main.go", line: 13, description: "The error returned by Close is not checked", wantID: 1},
+		{name: "too far", path: "main.go", line: 20, description: "Error returned by Close is not checked."},
+		{name: "other file", path: "util.go", line: 10, description: "Error returned by Close is not checked."},
+		{name: "different issue", path: "main.go", line: 11, description: "Variable shadows the package name"},
+		{name: "same rule", path: "main.go", line: 42, rule: "sql-prepared", description: "Use a prepared statement", wantID: 2},
+		{name: "other rule", path: "main.go", line: 40, rule: "no-panic", description: "Query built by string concatenation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FindDuplicate(open, tt.path, tt.line, tt.rule, tt.description)
+			if ok != (tt.wantID != 0) || got.ID != tt.wantID {
+				t.Errorf("FindDuplicate() = %d, %v, want %d", got.ID, ok, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Unchecked error", "unchecked error!", 1},
+		{"Unchecked error", "Possible nil dereference", 0},
+		{"Unchecked error on line 12", "Unchecked error on line 40", 1},
+		{"a b c d", "a b", 0.5},
+		{"", "Unchecked error", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
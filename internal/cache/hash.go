@@ -4,8 +4,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"regexp"
+	"strings"
 
+	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
 )
 
 const (
@@ -15,9 +18,18 @@ const (
 	HashSuffix = " -->"
 	// HashLength is the length of the short hash (like git short SHA)
 	HashLength = 12
+	// InvalidatedPrefix replaces HashPrefix in the markers of invalidated
+	// reviews, so that the function is reviewed again
+	InvalidatedPrefix = "<!-- ainspector:invalidated:"
 )
 
-var hashRegex = regexp.MustCompile(`<!-- ainspector:fn:([a-f0-9]{12}) -->`)
+// hashRegex matches hash markers, optionally carrying the rules and prompt
+// fingerprints and the ID of the function:
+// <!-- ainspector:fn:HASH:RULES:PROMPT@FUNCTION -->
+var hashRegex = regexp.MustCompile(`<!-- ainspector:fn:([a-f0-9]{12})(?::([a-f0-9]+):([a-f0-9]+))?(?:@([a-f0-9]{12}))? -->`)
+
+// markerRegex matches hash markers, including invalidated ones
+var markerRegex = regexp.MustCompile(`<!-- ainspector:(?:fn|invalidated):[a-f0-9:@]+ -->`)
 
 // FunctionHash generates a unique hash for an extracted function.
 // The hash is based on file path, function name, content, and diff to ensure
@@ -28,12 +40,75 @@ func FunctionHash(fn *extractor.ExtractedFunction) string {
 	return hex.EncodeToString(hash[:])[:HashLength]
 }
 
+// TokenHash generates a hash of an extracted function that ignores
+// whitespace, comments and line shifts: formatting the code or rebasing the
+// PR/MR doesn't trigger a re-review. Falls back to FunctionHash for functions
+// without a token hash.
+func TokenHash(fn *extractor.ExtractedFunction) string {
+	if fn.TokenHash == "" {
+		return FunctionHash(fn)
+	}
+	data := fn.FilePath + ":" + fn.Name + ":" + fn.TokenHash
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:HashLength]
+}
+
+// Hasher returns the hash function of a hashing mode (config.HashExact or
+// config.HashTokens)
+func Hasher(mode string) func(fn *extractor.ExtractedFunction) string {
+	if mode == config.HashTokens {
+		return TokenHash
+	}
+	return FunctionHash
+}
+
+// FunctionID identifies a function across its changes, by its file path and
+// name, independently of the hashing mode
+func FunctionID(fn *extractor.ExtractedFunction) string {
+	hash := sha256.Sum256([]byte(fn.FilePath + ":" + fn.Name))
+	return hex.EncodeToString(hash[:])[:HashLength]
+}
+
 // FormatHashMarker creates the HTML comment marker for embedding in comments.
 // The marker is invisible in rendered markdown on GitHub/GitLab.
 func FormatHashMarker(hash string) string {
 	return HashPrefix + hash + HashSuffix
 }
 
+// FormatMarker creates the HTML comment marker for a function hash, the ID
+// of the function (optional) and the fingerprint of the settings the function
+// was reviewed with
+func FormatMarker(hash, function string, fp llm.Fingerprint) string {
+	marker := HashPrefix + hash
+	if !fp.IsZero() {
+		marker += ":" + fp.Rules + ":" + fp.Prompt
+	}
+	if function != "" {
+		marker += "@" + function
+	}
+	return marker + HashSuffix
+}
+
+// ExtractFingerprint extracts the fingerprint from a comment body.
+// Returns a zero fingerprint if the marker has none.
+func ExtractFingerprint(commentBody string) llm.Fingerprint {
+	matches := hashRegex.FindStringSubmatch(commentBody)
+	if len(matches) < 4 || matches[2] == "" {
+		return llm.Fingerprint{}
+	}
+	return llm.Fingerprint{Rules: matches[2], Prompt: matches[3]}
+}
+
+// ExtractFunctionID extracts the function ID from a comment body.
+// Returns empty string if the marker has none.
+func ExtractFunctionID(commentBody string) string {
+	matches := hashRegex.FindStringSubmatch(commentBody)
+	if len(matches) < 5 {
+		return ""
+	}
+	return matches[4]
+}
+
 // ExtractHash extracts the hash from a comment body.
 // Returns empty string if no valid hash marker is found.
 func ExtractHash(commentBody string) string {
@@ -43,3 +118,18 @@ func ExtractHash(commentBody string) string {
 	}
 	return matches[1]
 }
+
+// InvalidateMarkers rewrites the hash markers of a comment body so that they
+// are no longer recognized, keeping the hash for reference
+func InvalidateMarkers(commentBody string) string {
+	return strings.ReplaceAll(commentBody, HashPrefix, InvalidatedPrefix)
+}
+
+// ReplaceMarker replaces the hash markers of a comment body with marker, or
+// appends marker if the body has none
+func ReplaceMarker(commentBody, marker string) string {
+	if !markerRegex.MatchString(commentBody) {
+		return commentBody + "\n\n" + marker
+	}
+	return markerRegex.ReplaceAllLiteralString(commentBody, marker)
+}
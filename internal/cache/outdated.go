@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// ResolvedMarker is appended to the replies posted on the comments of
+// functions changed since their review, so that a thread is only marked once
+const ResolvedMarker = "<!-- ainspector:resolved -->"
+
+// OpenFindings returns the ainspector comments starting a thread that is
+// neither resolved nor marked as fixed
+func OpenFindings(comments []provider.ExistingComment) []provider.ExistingComment {
+	marked := make(map[int64]bool)
+	for _, c := range comments {
+		if c.InReplyTo != 0 && strings.Contains(c.Body, ResolvedMarker) {
+			marked[c.InReplyTo] = true
+		}
+	}
+
+	var open []provider.ExistingComment
+	for _, c := range comments {
+		if c.InReplyTo != 0 || c.Resolved || marked[c.ID] {
+			continue
+		}
+		if strings.Contains(c.Body, HashPrefix) || strings.Contains(c.Body, InvalidatedPrefix) {
+			open = append(open, c)
+		}
+	}
+
+	return open
+}
+
+// OutdatedComments returns the open findings of functions changed since their
+// review, presumably fixing the issue: functions extracted again whose hash
+// differs from the one of the marker. Both hashing modes are compared, so that
+// switching modes doesn't outdate every comment. Comments of functions that
+// are not extracted (unchanged, ignored or disabled) and comments without
+// function ID are never outdated.
+func OutdatedComments(comments []provider.ExistingComment, functions []extractor.ExtractedFunction) []provider.ExistingComment {
+	current := make(map[string]map[string]bool, len(functions))
+	for i := range functions {
+		fn := &functions[i]
+		id := FunctionID(fn)
+		if current[id] == nil {
+			current[id] = make(map[string]bool)
+		}
+		current[id][FunctionHash(fn)] = true
+		current[id][TokenHash(fn)] = true
+	}
+
+	var outdated []provider.ExistingComment
+	for _, c := range OpenFindings(comments) {
+		hash := ExtractHash(c.Body)
+		hashes, ok := current[ExtractFunctionID(c.Body)]
+		if hash != "" && ok && !hashes[hash] {
+			outdated = append(outdated, c)
+		}
+	}
+	return outdated
+}
+
+// FixedComments returns the outdated comments (see OutdatedComments) whose
+// issue is no longer reported by the new review results. Comments matching a
+// new finding (see FindDuplicate) stay open, whether or not the finding is
+// posted again, and so do the comments of functions whose review failed.
+func FixedComments(outdated []provider.ExistingComment, results []llm.ReviewResult) []provider.ExistingComment {
+	failed := make(map[string]bool)
+	for i := range results {
+		if results[i].Error != nil {
+			failed[FunctionID(&results[i].Function)] = true
+		}
+	}
+
+	var fixed []provider.ExistingComment
+	for _, c := range outdated {
+		if failed[ExtractFunctionID(c.Body)] || reportedAgain(c, results) {
+			continue
+		}
+		fixed = append(fixed, c)
+	}
+	return fixed
+}
+
+// reportedAgain reports whether one of the findings of results reports the
+// issue of an existing comment
+func reportedAgain(c provider.ExistingComment, results []llm.ReviewResult) bool {
+	for _, result := range results {
+		if !result.HasIssues() {
+			continue
+		}
+		for _, s := range result.Suggestions {
+			if _, ok := FindDuplicate([]provider.ExistingComment{c}, result.Function.FilePath, s.Line, s.Rule, s.Description); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PendingFindings returns the open findings (see OpenFindings) that are
+// neither fixed nor reported again by the new review results: the findings of
+// functions skipped as already reviewed, or whose review failed
+func PendingFindings(open, fixed []provider.ExistingComment, results []llm.ReviewResult) []provider.ExistingComment {
+	isFixed := make(map[int64]bool, len(fixed))
+	for _, c := range fixed {
+		isFixed[c.ID] = true
+	}
+
+	var pending []provider.ExistingComment
+	for _, c := range open {
+		if !isFixed[c.ID] && !reportedAgain(c, results) {
+			pending = append(pending, c)
+		}
+	}
+	return pending
+}
+
+// FindingAnnotations returns the check run annotations of the findings
+// reported by comments of previous runs on the given files. Comments no longer
+// on a line of the files (e.g. outdated GitHub comments) are skipped. Comments
+// don't record the severity of their finding: it is the severity of the rule
+// they reference, or the default severity.
+func FindingAnnotations(comments []provider.ExistingComment, files []provider.ModifiedFile, cfg *config.Config) []provider.Annotation {
+	modified := make(map[string]bool, len(files))
+	for _, f := range files {
+		modified[f.Path] = true
+	}
+
+	annotations := make([]provider.Annotation, 0, len(comments))
+	for _, c := range comments {
+		if !modified[c.Path] || c.Line <= 0 {
+			continue
+		}
+
+		rule, description := parseFinding(c.Body)
+
+		annotation := provider.Annotation{
+			Path:     c.Path,
+			Line:     c.Line,
+			Severity: config.DefaultSeverity,
+			Title:    "Open review comment",
+			Message:  description,
+		}
+		if rule != "" {
+			if r := config.FindRule(cfg.ForPath(c.Path).Rules, rule); r != nil {
+				annotation.Severity = r.Severity
+			}
+			annotation.Message = fmt.Sprintf("[%s] %s", rule, description)
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+func TestOutdatedComments(t *testing.T) {
+	current := extractor.ExtractedFunction{Name: "Current", FilePath: "main.go", Content: "func Current() {}"}
+	changed := extractor.ExtractedFunction{Name: "Changed", FilePath: "main.go", Content: "func Changed() { fixed() }"}
+	before := changed
+	before.Content = "func Changed() { broken() }"
+
+	marker := func(fn *extractor.ExtractedFunction) string {
+		return FormatMarker(FunctionHash(fn), FunctionID(fn), llm.Fingerprint{})
+	}
+
+	comments := []provider.ExistingComment{
+		{ID: 1, Body: "Still there\n\n" + marker(&current)},
+		{ID: 2, Body: "Fixed\n\n" + marker(&before)},
+		{ID: 3, Body: "Already resolved\n\n" + marker(&before), Resolved: true},
+		{ID: 4, Body: "Already marked\n\n" + marker(&before)},
+		{ID: 5, InReplyTo: 4, Body: "Fixed in abc1234\n\n" + ResolvedMarker},
+		{ID: 6, InReplyTo: 2, Body: "Human reply quoting\n\n" + marker(&before)},
+		{ID: 7, Body: "Human comment"},
+		{ID: 8, Body: "Invalidated\n\n" + InvalidateMarkers(marker(&before))},
+		{ID: 9, Body: "Without function ID\n\n" + FormatHashMarker(FunctionHash(&before))},
+	}
+
+	outdated := OutdatedComments(comments, []extractor.ExtractedFunction{current, changed})
+	if len(outdated) != 1 || outdated[0].ID != 2 {
+		t.Errorf("expected only comment 2 to be outdated, got %+v", outdated)
+	}
+}
+
+func TestOutdatedComments_HashModeSwitch(t *testing.T) {
+	fn := extractor.ExtractedFunction{Name: "Parse", FilePath: "parse.go", Content: "func Parse() {}", TokenHash: "0123456789abcdef"}
+
+	// Reviewed with one hashing mode, extracted again with the other one
+	comments := []provider.ExistingComment{
+		{ID: 1, Body: "Exact\n\n" + FormatMarker(FunctionHash(&fn), FunctionID(&fn), llm.Fingerprint{})},
+		{ID: 2, Body: "Tokens\n\n" + FormatMarker(TokenHash(&fn), FunctionID(&fn), llm.Fingerprint{})},
+	}
+
+	if outdated := OutdatedComments(comments, []extractor.ExtractedFunction{fn}); len(outdated) != 0 {
+		t.Errorf("expected no outdated comments after switching the hashing mode, got %+v", outdated)
+	}
+}
+
+func TestOutdatedComments_NotExtracted(t *testing.T) {
+	// The file is now ignored, or the function disabled: it isn't extracted
+	ignored := extractor.ExtractedFunction{Name: "Generated", FilePath: "gen/types.go", Content: "func Generated() {}"}
+	other := extractor.ExtractedFunction{Name: "Main", FilePath: "main.go", Content: "func Main() {}"}
+
+	comments := []provider.ExistingComment{
+		{ID: 1, Body: "Issue\n\n" + FormatMarker("aaaaaaaaaaaa", FunctionID(&ignored), llm.Fingerprint{})},
+	}
+
+	if outdated := OutdatedComments(comments, []extractor.ExtractedFunction{other}); len(outdated) != 0 {
+		t.Errorf("expected the comments of functions not extracted to be kept, got %+v", outdated)
+	}
+}
+
+func TestOpenFindings(t *testing.T) {
+	comments := []provider.ExistingComment{
+		{ID: 1, Body: "Open\n\n" + FormatHashMarker("aaaaaaaaaaaa")},
+		{ID: 2, Body: "Resolved\n\n" + FormatHashMarker("bbbbbbbbbbbb"), Resolved: true},
+		{ID: 3, Body: "Human comment"},
+		{ID: 4, Body: "Cleared\n\n" + InvalidateMarkers(FormatHashMarker("cccccccccccc"))},
+		{ID: 5, InReplyTo: 1, Body: "Reply"},
+	}
+
+	open := OpenFindings(comments)
+	if len(open) != 2 || open[0].ID != 1 || open[1].ID != 4 {
+		t.Errorf("expected comments 1 and 4 to be open findings, got %+v", open)
+	}
+}
+
+func TestFixedComments(t *testing.T) {
+	before := extractor.ExtractedFunction{Name: "Query", FilePath: "db.go", Content: "func Query() { raw() }"}
+	changed := before
+	changed.Content = "func Query() { prepared() }"
+
+	outdated := []provider.ExistingComment{
+		{ID: 1, Path: "db.go", Line: 10, Body: "**[sql-prepared]** Raw SQL query\n\n" + FormatMarker(FunctionHash(&before), FunctionID(&before), llm.Fingerprint{})},
+		{ID: 2, Path: "db.go", Line: 12, Body: "Unchecked error of the query\n\n" + FormatMarker(FunctionHash(&before), FunctionID(&before), llm.Fingerprint{})},
+	}
+
+	tests := []struct {
+		name   string
+		result llm.ReviewResult
+		want   []int64
+	}{
+		{
+			name:   "clean review",
+			result: llm.ReviewResult{Function: changed, RawReview: "LGTM"},
+			want:   []int64{1, 2},
+		},
+		{
+			name: "issues reported again",
+			result: llm.ReviewResult{Function: changed, Suggestions: []llm.Suggestion{
+				{Line: 11, Rule: "sql-prepared", Description: "Query built by concatenation"},
+				{Line: 13, Description: "Unchecked error of the query"},
+			}},
+		},
+		{
+			name: "other issue",
+			result: llm.ReviewResult{Function: changed, Suggestions: []llm.Suggestion{
+				{Line: 11, Description: "Unchecked error of the query"},
+			}},
+			want: []int64{1},
+		},
+		{
+			name:   "failed review",
+			result: llm.ReviewResult{Function: changed, Error: errors.New("rate limited")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, c := range FixedComments(outdated, []llm.ReviewResult{tt.result}) {
+				got = append(got, c.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("fixed comments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPendingFindings(t *testing.T) {
+	fn := extractor.ExtractedFunction{Name: "Query", FilePath: "db.go", Content: "func Query() {}"}
+	marker := FormatMarker(FunctionHash(&fn), FunctionID(&fn), llm.Fingerprint{})
+
+	open := []provider.ExistingComment{
+		{ID: 1, Path: "db.go", Line: 10, Body: "**[sql-prepared]** Raw SQL query\n\n" + marker},
+		{ID: 2, Path: "db.go", Line: 30, Body: "Unchecked error of the query\n\n" + marker},
+		{ID: 3, Path: "main.go", Line: 5, Body: "Fixed issue\n\n" + marker},
+	}
+	fixed := []provider.ExistingComment{open[2]}
+	results := []llm.ReviewResult{{Function: fn, Suggestions: []llm.Suggestion{
+		{Line: 11, Rule: "sql-prepared", Description: "Query built by concatenation"},
+	}}}
+
+	pending := PendingFindings(open, fixed, results)
+	if len(pending) != 1 || pending[0].ID != 2 {
+		t.Errorf("expected only comment 2 to be pending, got %+v", pending)
+	}
+}
+
+func TestFindingAnnotations(t *testing.T) {
+	cfg := &config.Config{Rules: []config.Rule{
+		{ID: "sql-prepared", Text: "Use prepared statements", Severity: config.SeverityCritical},
+	}}
+	marker := FormatHashMarker("aaaaaaaaaaaa")
+	comments := []provider.ExistingComment{
+		{ID: 1, Path: "db.go", Line: 10, Body: "**[sql-prepared]** Raw SQL query\n\n" + marker},
+		{ID: 2, Path: "db.go", Line: 30, Body: "Unchecked error\n\n" + marker},
+		{ID: 3, Path: "db.go", Line: 40, Body: "**[removed-rule]** Old rule\n\n" + marker},
+		{ID: 4, Path: "db.go", Body: "Outdated position\n\n" + marker},
+		{ID: 5, Path: "reverted.go", Line: 3, Body: "File no longer modified\n\n" + marker},
+	}
+	files := []provider.ModifiedFile{{Path: "db.go"}}
+
+	want := []provider.Annotation{
+		{Path: "db.go", Line: 10, Severity: config.SeverityCritical, Title: "Open review comment", Message: "[sql-prepared] Raw SQL query"},
+		{Path: "db.go", Line: 30, Severity: config.DefaultSeverity, Title: "Open review comment", Message: "Unchecked error"},
+		{Path: "db.go", Line: 40, Severity: config.DefaultSeverity, Title: "Open review comment", Message: "[removed-rule] Old rule"},
+	}
+	if got := FindingAnnotations(comments, files, cfg); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected annotations %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+)
+
+// Default limits of a DirStore
+const (
+	DefaultTTL     = 30 * 24 * time.Hour
+	DefaultMaxSize = 100 << 20 // 100 MB
+)
+
+// Entry is a review result stored in the cache, including clean (LGTM) results
+type Entry struct {
+	Key         string           `json:"key"`
+	FilePath    string           `json:"file_path"`
+	Function    string           `json:"function"`
+	StartLine   int              `json:"start_line,omitempty"`
+	Suggestions []llm.Suggestion `json:"suggestions,omitempty"`
+	RawReview   string           `json:"raw_review,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// NewEntry creates a cache entry from a successful review result
+func NewEntry(key string, result *llm.ReviewResult) *Entry {
+	return &Entry{
+		Key:         key,
+		FilePath:    result.Function.FilePath,
+		Function:    result.Function.Name,
+		StartLine:   result.Function.StartLine,
+		Suggestions: result.Suggestions,
+		RawReview:   result.RawReview,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Result returns the stored review result for fn. Suggestion lines are moved
+// along with the function when it was shifted since the review.
+func (e *Entry) Result(fn extractor.ExtractedFunction) llm.ReviewResult {
+	suggestions := e.Suggestions
+	if offset := fn.StartLine - e.StartLine; e.StartLine > 0 && offset != 0 {
+		suggestions = make([]llm.Suggestion, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			if s.Line > 0 {
+				s.Line += offset
+			}
+			suggestions[i] = s
+		}
+	}
+
+	return llm.ReviewResult{
+		Function:    fn,
+		Suggestions: suggestions,
+		RawReview:   e.RawReview,
+	}
+}
+
+// Store persists review results between runs
+type Store interface {
+	// Get returns the entry stored under key, if any
+	Get(key string) (*Entry, bool)
+	// Put stores an entry under its key
+	Put(entry *Entry) error
+	// Delete removes the entry stored under key, if any
+	Delete(key string) error
+	// Prune evicts expired entries and enforces the size limit
+	Prune() error
+}
+
+// StoreKey returns the key of a function's review result: the function hash
+// (FunctionHash or TokenHash) combined with the model, the prompt templates
+// version and the rules fingerprint, so that changing any of them triggers a
+// new review. Unlike review comments, stored results don't follow the
+// invalidation policy.
+func StoreKey(hash, model, promptsVersion string, fp llm.Fingerprint) string {
+	data := strings.Join([]string{hash, model, promptsVersion, fp.Rules}, "\x00")
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// DirStore is a Store keeping one JSON file per entry in a directory, e.g. a
+// local cache directory or a path cached between CI jobs. Entries expire after
+// TTL; when the directory exceeds MaxSize bytes, the least recently used
+// entries are evicted.
+type DirStore struct {
+	Dir     string
+	TTL     time.Duration
+	MaxSize int64
+}
+
+// NewDirStore creates a store in dir with the default limits, creating the
+// directory if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &DirStore{Dir: dir, TTL: DefaultTTL, MaxSize: DefaultMaxSize}, nil
+}
+
+// DefaultDir returns the cache directory used when none is configured:
+// $AINSPECTOR_CACHE_DIR, or the ainspector directory in the user cache directory
+func DefaultDir() string {
+	if dir := os.Getenv("AINSPECTOR_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "ainspector")
+	}
+	return ".ainspector-cache"
+}
+
+// Get returns the entry stored under key. Expired and unreadable entries are
+// treated as missing.
+func (s *DirStore) Get(key string) (*Entry, bool) {
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	if s.TTL > 0 && time.Since(entry.CreatedAt) > s.TTL {
+		_ = os.Remove(path)
+		return nil, false
+	}
+
+	// Record the access for least recently used eviction
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return &entry, true
+}
+
+// Put stores an entry, replacing the file atomically
+func (s *DirStore) Put(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(entry.Key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the entry stored under key, if any
+func (s *DirStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the entries created longer than TTL ago, like Get, then the
+// least recently used entries until the directory fits in MaxSize
+func (s *DirStore) Prune() error {
+	dirEntries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(s.Dir, d.Name())
+		if s.TTL > 0 && s.expired(path) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if s.MaxSize <= 0 || total <= s.MaxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= s.MaxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+		total -= f.size
+	}
+
+	return nil
+}
+
+// expired reports whether the entry stored in path was created longer than
+// TTL ago. Unreadable entries are expired.
+func (s *DirStore) expired(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+
+	var entry struct {
+		CreatedAt time.Time `json:"created_at"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return true
+	}
+	return time.Since(entry.CreatedAt) > s.TTL
+}
+
+// path returns the file of an entry
+func (s *DirStore) path(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
+)
+
+func newTestStore(t *testing.T) *DirStore {
+	t.Helper()
+	store, err := NewDirStore(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+func TestStoreKey(t *testing.T) {
+	fn := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}"}
+	hash := FunctionHash(fn)
+	fp := llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"}
+
+	key := StoreKey(hash, "gpt-4o", "v1", fp)
+	if key != StoreKey(hash, "gpt-4o", "v1", fp) {
+		t.Error("expected key to be deterministic")
+	}
+	if key == StoreKey(hash, "gpt-4o-mini", "v1", fp) {
+		t.Error("expected model to change the key")
+	}
+	if key == StoreKey(hash, "gpt-4o", "v2", fp) {
+		t.Error("expected prompt version to change the key")
+	}
+	if key == StoreKey(hash, "gpt-4o", "v1", llm.Fingerprint{Rules: "fedcba98", Prompt: "4567ef01"}) {
+		t.Error("expected rules to change the key")
+	}
+	if key == StoreKey(TokenHash(&extractor.ExtractedFunction{Name: "run", FilePath: "main.go", TokenHash: "0123456789ab"}), "gpt-4o", "v1", fp) {
+		t.Error("expected function hash to change the key")
+	}
+}
+
+func TestDirStore_ModelChangeMisses(t *testing.T) {
+	store := newTestStore(t)
+	fn := extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}"}
+	fp := llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"}
+
+	key := StoreKey(FunctionHash(&fn), "gpt-4o", "v1", fp)
+	if err := store.Put(NewEntry(key, &llm.ReviewResult{Function: fn, RawReview: "LGTM"})); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+
+	if _, ok := store.Get(key); !ok {
+		t.Error("expected the same settings to hit the store")
+	}
+	if _, ok := store.Get(StoreKey(FunctionHash(&fn), "gpt-4o-mini", "v1", fp)); ok {
+		t.Error("expected a model change to miss the store")
+	}
+}
+
+func TestDirStore_PutGet(t *testing.T) {
+	store := newTestStore(t)
+	fn := extractor.ExtractedFunction{Name: "run", FilePath: "main.go"}
+
+	if _, ok := store.Get("missing"); ok {
+		t.Error("expected miss for unknown key")
+	}
+
+	results := map[string]*llm.ReviewResult{
+		"clean":  {Function: fn, RawReview: "LGTM"},
+		"issues": {Function: fn, Suggestions: []llm.Suggestion{{Line: 3, Description: "Unchecked error", Severity: "warning"}}},
+	}
+
+	for key, result := range results {
+		if err := store.Put(NewEntry(key, result)); err != nil {
+			t.Fatalf("failed to put %s: %v", key, err)
+		}
+	}
+
+	entry, ok := store.Get("clean")
+	if !ok {
+		t.Fatal("expected clean result to be stored")
+	}
+	if got := entry.Result(fn); got.HasIssues() || got.RawReview != "LGTM" {
+		t.Errorf("unexpected clean result: %+v", got)
+	}
+
+	entry, ok = store.Get("issues")
+	if !ok {
+		t.Fatal("expected result with issues to be stored")
+	}
+	got := entry.Result(fn)
+	if len(got.Suggestions) != 1 || got.Suggestions[0].Description != "Unchecked error" || got.Function.Name != "run" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestDirStore_TTL(t *testing.T) {
+	store := newTestStore(t)
+	store.TTL = time.Hour
+
+	entry := &Entry{Key: "old", CreatedAt: time.Now().Add(-2 * time.Hour)}
+	if err := store.Put(entry); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+
+	if _, ok := store.Get("old"); ok {
+		t.Error("expected expired entry to be a miss")
+	}
+	if _, err := os.Stat(store.path("old")); !os.IsNotExist(err) {
+		t.Error("expected expired entry to be removed")
+	}
+}
+
+func TestDirStore_CorruptedEntry(t *testing.T) {
+	store := newTestStore(t)
+	_ = os.WriteFile(store.path("bad"), []byte("not json"), 0644)
+
+	if _, ok := store.Get("bad"); ok {
+		t.Error("expected corrupted entry to be a miss")
+	}
+}
+
+func TestDirStore_Prune(t *testing.T) {
+	store := newTestStore(t)
+	store.TTL = time.Hour
+
+	now := time.Now()
+	for i, key := range []string{"expired", "oldest", "recent", "newest"} {
+		// Entries expire on their creation time, not on their access time
+		created := now
+		if key == "expired" {
+			created = now.Add(-2 * time.Hour)
+		}
+		if err := store.Put(&Entry{Key: key, CreatedAt: created}); err != nil {
+			t.Fatalf("failed to put: %v", err)
+		}
+		// Access times: oldest to newest, the expired entry being the most recent
+		accessed := now.Add(time.Duration(i-3) * time.Minute)
+		if key == "expired" {
+			accessed = now
+		}
+		_ = os.Chtimes(store.path(key), accessed, accessed)
+	}
+
+	info, _ := os.Stat(store.path("newest"))
+	store.MaxSize = 2 * info.Size()
+
+	if err := store.Prune(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range map[string]bool{"expired": false, "oldest": false, "recent": true, "newest": true} {
+		_, err := os.Stat(store.path(key))
+		if exists := err == nil; exists != want {
+			t.Errorf("entry %s: exists = %v, want %v", key, exists, want)
+		}
+	}
+}
+
+func TestDirStore_PruneRestoredEntries(t *testing.T) {
+	store := newTestStore(t)
+	store.TTL = time.Hour
+	store.MaxSize = 0
+
+	// A CI cache restore gives the files an old modification time
+	if err := store.Put(&Entry{Key: "restored", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(store.path("restored"), old, old)
+
+	if err := store.Prune(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.Get("restored"); !ok {
+		t.Error("expected entry created within the TTL to be kept")
+	}
+}
+
+func TestEntry_ResultShiftedFunction(t *testing.T) {
+	reviewed := extractor.ExtractedFunction{Name: "run", FilePath: "main.go", StartLine: 10}
+	entry := NewEntry("key", &llm.ReviewResult{
+		Function:    reviewed,
+		Suggestions: []llm.Suggestion{{Line: 12, Description: "Unchecked error"}, {Description: "General remark"}},
+	})
+
+	tests := []struct {
+		name      string
+		startLine int
+		want      []int
+	}{
+		{name: "same position", startLine: 10, want: []int{12, 0}},
+		{name: "moved down", startLine: 15, want: []int{17, 0}},
+		{name: "moved up", startLine: 4, want: []int{6, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := reviewed
+			fn.StartLine = tt.startLine
+
+			got := entry.Result(fn)
+			for i, line := range tt.want {
+				if got.Suggestions[i].Line != line {
+					t.Errorf("suggestion %d line = %d, want %d", i, got.Suggestions[i].Line, line)
+				}
+			}
+		})
+	}
+
+	if entry.Suggestions[0].Line != 12 {
+		t.Error("expected stored suggestions to be left unchanged")
+	}
+}
+
+func TestDirStore_Delete(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Put(&Entry{Key: "key", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+
+	if err := store.Delete("key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.Get("key"); ok {
+		t.Error("expected deleted entry to be a miss")
+	}
+	if err := store.Delete("key"); err != nil {
+		t.Errorf("expected deleting a missing entry to succeed, got %v", err)
+	}
+}
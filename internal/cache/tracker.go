@@ -1,26 +1,39 @@
 package cache
 
 import (
+	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
 )
 
 // ReviewedComment represents a previously posted review comment with its hash
 type ReviewedComment struct {
-	Path string
-	Line int
-	Hash string
-	Body string
+	Path        string
+	Line        int
+	Hash        string
+	Fingerprint llm.Fingerprint
+	Body        string
 }
 
 // Tracker tracks which functions have already been reviewed in a PR/MR
 type Tracker struct {
-	reviewed map[string]bool // map[hash]bool
+	reviewed map[string][]llm.Fingerprint // map[hash]fingerprints
+
+	// Hash returns the hash of a function, as found in the comment markers.
+	// Defaults to FunctionHash.
+	Hash func(fn *extractor.ExtractedFunction) string
+	// Fingerprint returns the current fingerprint of a function. When nil,
+	// reviews are only invalidated by code changes.
+	Fingerprint func(fn *extractor.ExtractedFunction) llm.Fingerprint
+	// Policy is the invalidation policy (config.InvalidateRules, InvalidateAny
+	// or InvalidateNever). Defaults to InvalidateRules.
+	Policy string
 }
 
 // NewTracker creates a new review tracker
 func NewTracker() *Tracker {
 	return &Tracker{
-		reviewed: make(map[string]bool),
+		reviewed: make(map[string][]llm.Fingerprint),
 	}
 }
 
@@ -29,15 +42,35 @@ func NewTracker() *Tracker {
 func (t *Tracker) LoadFromComments(comments []ReviewedComment) {
 	for _, c := range comments {
 		if c.Hash != "" {
-			t.reviewed[c.Hash] = true
+			t.reviewed[c.Hash] = append(t.reviewed[c.Hash], c.Fingerprint)
 		}
 	}
 }
 
-// IsReviewed checks if a function has already been reviewed
+// IsReviewed checks if a function has already been reviewed with settings
+// that are still valid under the invalidation policy. Reviews without a
+// fingerprint (posted by older versions) are considered valid.
 func (t *Tracker) IsReviewed(fn *extractor.ExtractedFunction) bool {
-	hash := FunctionHash(fn)
-	return t.reviewed[hash]
+	hash := FunctionHash
+	if t.Hash != nil {
+		hash = t.Hash
+	}
+
+	fingerprints, ok := t.reviewed[hash(fn)]
+	if !ok {
+		return false
+	}
+	if t.Fingerprint == nil || t.Policy == config.InvalidateNever {
+		return true
+	}
+
+	current := t.Fingerprint(fn)
+	for _, fp := range fingerprints {
+		if fp.IsZero() || InvalidationKey(t.Policy, fp) == InvalidationKey(t.Policy, current) {
+			return true
+		}
+	}
+	return false
 }
 
 // FilterUnreviewed returns only functions that haven't been reviewed yet
@@ -55,3 +88,18 @@ func (t *Tracker) FilterUnreviewed(functions []extractor.ExtractedFunction) []ex
 func (t *Tracker) ReviewedCount() int {
 	return len(t.reviewed)
 }
+
+// InvalidationKey returns the part of a fingerprint whose changes invalidate
+// a review under policy: the rules fingerprint for InvalidateRules (the
+// default), the whole fingerprint for InvalidateAny and nothing for
+// InvalidateNever
+func InvalidationKey(policy string, fp llm.Fingerprint) string {
+	switch policy {
+	case config.InvalidateNever:
+		return ""
+	case config.InvalidateAny:
+		return fp.Prompt
+	default:
+		return fp.Rules
+	}
+}
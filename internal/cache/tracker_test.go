@@ -1,9 +1,12 @@
 package cache
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
+	"github.com/iq2i/ainspector/internal/llm"
 )
 
 func TestFunctionHash_Deterministic(t *testing.T) {
@@ -237,3 +240,191 @@ func TestTracker_IsReviewed(t *testing.T) {
 		t.Error("Function should be reviewed after loading hash")
 	}
 }
+
+func TestFormatMarker_Fingerprint(t *testing.T) {
+	fp := llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"}
+	marker := FormatMarker("abc123def456", "", fp)
+
+	if marker != "<!-- ainspector:fn:abc123def456:0123abcd:4567ef01 -->" {
+		t.Errorf("FormatMarker = %q", marker)
+	}
+	if got := ExtractHash("Comment\n\n" + marker); got != "abc123def456" {
+		t.Errorf("ExtractHash = %q, want abc123def456", got)
+	}
+	if got := ExtractFingerprint("Comment\n\n" + marker); got != fp {
+		t.Errorf("ExtractFingerprint = %+v, want %+v", got, fp)
+	}
+
+	if got := FormatMarker("abc123def456", "", llm.Fingerprint{}); got != FormatHashMarker("abc123def456") {
+		t.Errorf("expected legacy marker without fingerprint, got %q", got)
+	}
+	if got := ExtractFingerprint(FormatHashMarker("abc123def456")); !got.IsZero() {
+		t.Errorf("expected zero fingerprint for legacy marker, got %+v", got)
+	}
+}
+
+func TestFormatMarker_FunctionID(t *testing.T) {
+	fn := &extractor.ExtractedFunction{Name: "Parse", FilePath: "parse.go"}
+	fp := llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"}
+
+	for _, marker := range []string{
+		FormatMarker("abc123def456", FunctionID(fn), fp),
+		FormatMarker("abc123def456", FunctionID(fn), llm.Fingerprint{}),
+	} {
+		if got := ExtractHash(marker); got != "abc123def456" {
+			t.Errorf("ExtractHash(%q) = %q", marker, got)
+		}
+		if got := ExtractFunctionID(marker); got != FunctionID(fn) {
+			t.Errorf("ExtractFunctionID(%q) = %q, want %q", marker, got, FunctionID(fn))
+		}
+		if got := ReplaceMarker("Comment\n\n"+marker, FormatHashMarker("bbbbbbbbbbbb")); got != "Comment\n\n"+FormatHashMarker("bbbbbbbbbbbb") {
+			t.Errorf("ReplaceMarker = %q", got)
+		}
+	}
+	if got := ExtractFingerprint(FormatMarker("abc123def456", FunctionID(fn), fp)); got != fp {
+		t.Errorf("ExtractFingerprint = %+v, want %+v", got, fp)
+	}
+	if got := ExtractFunctionID(FormatHashMarker("abc123def456")); got != "" {
+		t.Errorf("expected no function ID for legacy marker, got %q", got)
+	}
+
+	// The ID only depends on the location of the function
+	changed := &extractor.ExtractedFunction{Name: "Parse", FilePath: "parse.go", Content: "func Parse() {}"}
+	if FunctionID(changed) != FunctionID(fn) {
+		t.Error("expected the function ID to ignore the content")
+	}
+}
+
+func TestTracker_InvalidationPolicy(t *testing.T) {
+	fn := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}"}
+	hash := FunctionHash(fn)
+
+	reviewed := llm.Fingerprint{Rules: "rules001", Prompt: "prompt01"}
+	current := map[string]llm.Fingerprint{
+		"unchanged":      reviewed,
+		"prompt changed": {Rules: "rules001", Prompt: "prompt02"},
+		"rules changed":  {Rules: "rules002", Prompt: "prompt03"},
+	}
+
+	tests := []struct {
+		policy string
+		want   map[string]bool
+	}{
+		{config.InvalidateRules, map[string]bool{"unchanged": true, "prompt changed": true, "rules changed": false}},
+		{"", map[string]bool{"unchanged": true, "prompt changed": true, "rules changed": false}},
+		{config.InvalidateAny, map[string]bool{"unchanged": true, "prompt changed": false, "rules changed": false}},
+		{config.InvalidateNever, map[string]bool{"unchanged": true, "prompt changed": true, "rules changed": true}},
+	}
+
+	for _, tt := range tests {
+		for change, want := range tt.want {
+			t.Run(tt.policy+"/"+change, func(t *testing.T) {
+				tracker := NewTracker()
+				tracker.Policy = tt.policy
+				tracker.Fingerprint = func(*extractor.ExtractedFunction) llm.Fingerprint { return current[change] }
+				tracker.LoadFromComments([]ReviewedComment{{Hash: hash, Fingerprint: reviewed}})
+
+				if got := tracker.IsReviewed(fn); got != want {
+					t.Errorf("IsReviewed() = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTracker_LegacyMarkersStayValid(t *testing.T) {
+	fn := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}"}
+
+	tracker := NewTracker()
+	tracker.Policy = config.InvalidateAny
+	tracker.Fingerprint = func(*extractor.ExtractedFunction) llm.Fingerprint {
+		return llm.Fingerprint{Rules: "rules001", Prompt: "prompt01"}
+	}
+	tracker.LoadFromComments([]ReviewedComment{{Hash: FunctionHash(fn)}})
+
+	if !tracker.IsReviewed(fn) {
+		t.Error("expected review without fingerprint to stay valid")
+	}
+}
+
+func TestTokenHash(t *testing.T) {
+	fn := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}", Diff: "+func run() {}", TokenHash: "0123456789ab"}
+	reformatted := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {\n}", Diff: "+func run() {\n+}", StartLine: 12, TokenHash: "0123456789ab"}
+	changed := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() { x() }", TokenHash: "ba9876543210"}
+	moved := &extractor.ExtractedFunction{Name: "run", FilePath: "other.go", Content: "func run() {}", TokenHash: "0123456789ab"}
+
+	if TokenHash(fn) != TokenHash(reformatted) {
+		t.Error("expected formatting and line shifts to keep the hash")
+	}
+	if TokenHash(fn) == TokenHash(changed) {
+		t.Error("expected code changes to change the hash")
+	}
+	if TokenHash(fn) == TokenHash(moved) {
+		t.Error("expected path to change the hash")
+	}
+
+	legacy := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}"}
+	if TokenHash(legacy) != FunctionHash(legacy) {
+		t.Error("expected fallback to FunctionHash without token hash")
+	}
+}
+
+func TestTracker_TokenHash(t *testing.T) {
+	reviewed := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {}", TokenHash: "0123456789ab"}
+	reformatted := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Content: "func run() {\n}", TokenHash: "0123456789ab"}
+
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{config.HashExact, false},
+		{config.HashTokens, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			tracker := NewTracker()
+			tracker.Hash = Hasher(tt.mode)
+			tracker.LoadFromComments([]ReviewedComment{{Hash: tracker.Hash(reviewed)}})
+
+			if got := tracker.IsReviewed(reformatted); got != tt.want {
+				t.Errorf("IsReviewed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidateMarkers(t *testing.T) {
+	fp := llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"}
+	body := "Unchecked error\n\n" + FormatMarker("abc123def456", "", fp)
+
+	invalidated := InvalidateMarkers(body)
+	if got := ExtractHash(invalidated); got != "" {
+		t.Errorf("expected invalidated marker to be ignored, got hash %q", got)
+	}
+	if !strings.Contains(invalidated, "Unchecked error") || !strings.Contains(invalidated, "abc123def456") {
+		t.Errorf("expected comment and hash to be kept, got %q", invalidated)
+	}
+}
+
+func TestReplaceMarker(t *testing.T) {
+	marker := FormatMarker("bbbbbbbbbbbb", "", llm.Fingerprint{Rules: "0123abcd", Prompt: "4567ef01"})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"hash marker", "Issue\n\n" + FormatHashMarker("aaaaaaaaaaaa") + "\n\n```suggestion\nx\n```", "Issue\n\n" + marker + "\n\n```suggestion\nx\n```"},
+		{"invalidated marker", "Issue\n\n" + InvalidateMarkers(FormatHashMarker("aaaaaaaaaaaa")), "Issue\n\n" + marker},
+		{"no marker", "Issue", "Issue\n\n" + marker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplaceMarker(tt.body, marker); got != tt.want {
+				t.Errorf("ReplaceMarker() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ci
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -11,16 +12,32 @@ import (
 
 // Environment represents the detected CI environment
 type Environment struct {
-	Provider   string // "github" or "gitlab"
-	Owner      string // Repository owner
-	Repo       string // Repository name
-	PRNumber   int    // Pull request / Merge request number
+	Provider   string // "github", "gitea", "gitlab", "bitbucket", "bitbucket-server", "azure" or "gerrit"
+	Owner      string // Repository owner (Bitbucket workspace, Azure DevOps project)
+	Repo       string // Repository name (Gerrit project)
+	PRNumber   int    // Pull request / Merge request / Gerrit change number
 	Token      string // API token
+	Username   string // Username for basic authentication (Bitbucket app passwords, Gerrit HTTP passwords)
 	ServerHost string // Server host (for self-hosted instances)
+	ServerURL  string // Server base URL (for GitHub, Gitea, Bitbucket Server, Azure DevOps and Gerrit)
+	APIURL     string // REST API base URL (for GitHub Enterprise Server)
+	CABundle   string // CA certificates file trusted for internal TLS (for GitHub Enterprise Server)
+	HeadSHA    string // Head commit of the PR/MR, if known
+	RunURL     string // Page of the CI run or job performing the review, if known (for GitHub and GitLab)
+
+	// GitHub App credentials, used instead of Token when AppID is set
+	AppID             int64
+	AppPrivateKey     string // PEM encoded private key
+	AppInstallationID int64  // Looked up from the repository if zero
 }
 
 // Detect detects the CI environment from environment variables
 func Detect() (*Environment, error) {
+	// Check for Gitea Actions, which also sets the GitHub Actions variables
+	if os.Getenv("GITEA_ACTIONS") == "true" || os.Getenv("FORGEJO_ACTIONS") == "true" {
+		return detectGitea()
+	}
+
 	// Check for GitHub Actions
 	if os.Getenv("GITHUB_ACTIONS") == "true" {
 		return detectGitHub()
@@ -31,7 +48,27 @@ func Detect() (*Environment, error) {
 		return detectGitLab()
 	}
 
-	return nil, fmt.Errorf("not running in a supported CI environment (GitHub Actions or GitLab CI)")
+	// Check for Bitbucket Pipelines
+	if os.Getenv("BITBUCKET_BUILD_NUMBER") != "" {
+		return detectBitbucket()
+	}
+
+	// Check for Azure Pipelines
+	if strings.EqualFold(os.Getenv("TF_BUILD"), "true") {
+		return detectAzure()
+	}
+
+	// Check for a Gerrit change (Jenkins Gerrit Trigger)
+	if os.Getenv("GERRIT_CHANGE_NUMBER") != "" {
+		return detectGerrit()
+	}
+
+	// Check for a Bitbucket Server instance (e.g. from Jenkins or Bamboo)
+	if os.Getenv("BITBUCKET_SERVER_URL") != "" {
+		return detectBitbucketServer()
+	}
+
+	return nil, fmt.Errorf("not running in a supported CI environment (GitHub Actions, Gitea Actions, GitLab CI, Bitbucket Pipelines, Azure Pipelines, Gerrit Trigger or Bitbucket Server)")
 }
 
 // detectGitHub detects GitHub Actions environment
@@ -47,28 +84,165 @@ func detectGitHub() (*Environment, error) {
 		return nil, fmt.Errorf("invalid GITHUB_REPOSITORY format: %s", repository)
 	}
 
-	// Get PR number from GITHUB_REF (refs/pull/123/merge) or event file
+	// Get PR number from GITHUB_REF (refs/pull/123/merge) or event file.
+	// workflow_run events of fork PRs don't have it: the trusted workflows
+	// publishing review artifacts take it from the artifact.
 	prNumber, err := getGitHubPRNumber()
-	if err != nil {
+	if err != nil && os.Getenv("GITHUB_EVENT_NAME") != "workflow_run" {
 		return nil, err
 	}
 
-	// Get token
+	// Get token, unless authenticating as a GitHub App
 	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
+	useApp := os.Getenv("GITHUB_APP_ID") != ""
+	if token == "" && !useApp {
 		return nil, fmt.Errorf("GITHUB_TOKEN not set")
 	}
 
-	return &Environment{
+	// Get server and API URLs (for GitHub Enterprise Server)
+	serverURL := strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/")
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+	parsed, err := url.Parse(serverURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid GITHUB_SERVER_URL: %s", serverURL)
+	}
+	apiURL := strings.TrimSuffix(os.Getenv("GITHUB_API_URL"), "/")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+
+	env := &Environment{
 		Provider:   "github",
 		Owner:      parts[0],
 		Repo:       parts[1],
 		PRNumber:   prNumber,
 		Token:      token,
-		ServerHost: "github.com",
+		ServerHost: parsed.Host,
+		ServerURL:  serverURL,
+		APIURL:     apiURL,
+		CABundle:   os.Getenv("GITHUB_CA_BUNDLE"),
+		HeadSHA:    getGitHubHeadSHA(),
+	}
+	if runID := os.Getenv("GITHUB_RUN_ID"); runID != "" {
+		env.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", serverURL, repository, runID)
+	}
+	if useApp {
+		if err := getGitHubApp(env); err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// getGitHubApp reads the credentials of the GitHub App to authenticate as,
+// whose private key is given either inline or as a file
+func getGitHubApp(env *Environment) error {
+	appID, err := strconv.ParseInt(os.Getenv("GITHUB_APP_ID"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid GITHUB_APP_ID: %s", os.Getenv("GITHUB_APP_ID"))
+	}
+	env.AppID = appID
+
+	env.AppPrivateKey = os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	if path := os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"); env.AppPrivateKey == "" && path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read GITHUB_APP_PRIVATE_KEY_PATH: %w", err)
+		}
+		env.AppPrivateKey = string(key)
+	}
+	if env.AppPrivateKey == "" {
+		return fmt.Errorf("GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH not set")
+	}
+
+	if installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID"); installationID != "" {
+		env.AppInstallationID, err = strconv.ParseInt(installationID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid GITHUB_APP_INSTALLATION_ID: %s", installationID)
+		}
+	}
+
+	return nil
+}
+
+// detectGitea detects Gitea Actions environment (including Forgejo Actions),
+// which mimics the GitHub Actions variables and event files
+func detectGitea() (*Environment, error) {
+	serverURL := strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/")
+	parsed, err := url.Parse(serverURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid GITHUB_SERVER_URL: %q", serverURL)
+	}
+
+	// Get repository (format: owner/repo)
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if repository == "" {
+		return nil, fmt.Errorf("GITHUB_REPOSITORY not set")
+	}
+
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid GITHUB_REPOSITORY format: %s", repository)
+	}
+
+	prNumber, err := getGitHubPRNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get token (prefer GITEA_TOKEN, fallback to GITHUB_TOKEN)
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("GITEA_TOKEN or GITHUB_TOKEN not set")
+	}
+
+	return &Environment{
+		Provider:   "gitea",
+		Owner:      parts[0],
+		Repo:       parts[1],
+		PRNumber:   prNumber,
+		Token:      token,
+		ServerHost: parsed.Host,
+		ServerURL:  serverURL,
+		HeadSHA:    getGitHubHeadSHA(),
 	}, nil
 }
 
+// getGitHubHeadSHA returns the head commit of the pull request. GITHUB_SHA is
+// the merge commit in pull_request events, so the event file is preferred.
+func getGitHubHeadSHA() string {
+	if eventPath := os.Getenv("GITHUB_EVENT_PATH"); eventPath != "" {
+		if data, err := os.ReadFile(eventPath); err == nil {
+			var event struct {
+				PullRequest struct {
+					Head struct {
+						SHA string `json:"sha"`
+					} `json:"head"`
+				} `json:"pull_request"`
+				WorkflowRun struct {
+					HeadSHA string `json:"head_sha"`
+				} `json:"workflow_run"`
+			}
+			if err := json.Unmarshal(data, &event); err == nil {
+				if event.PullRequest.Head.SHA != "" {
+					return event.PullRequest.Head.SHA
+				}
+				if event.WorkflowRun.HeadSHA != "" {
+					return event.WorkflowRun.HeadSHA
+				}
+			}
+		}
+	}
+
+	return os.Getenv("GITHUB_SHA")
+}
+
 // getGitHubPRNumber extracts the PR number from GitHub Actions environment
 func getGitHubPRNumber() (int, error) {
 	// First, try to get from GITHUB_REF (refs/pull/123/merge)
@@ -90,7 +264,12 @@ func getGitHubPRNumber() (int, error) {
 				PullRequest struct {
 					Number int `json:"number"`
 				} `json:"pull_request"`
-				Number int `json:"number"`
+				Number      int `json:"number"`
+				WorkflowRun struct {
+					PullRequests []struct {
+						Number int `json:"number"`
+					} `json:"pull_requests"`
+				} `json:"workflow_run"`
 			}
 			if err := json.Unmarshal(data, &event); err == nil {
 				if event.PullRequest.Number > 0 {
@@ -99,6 +278,10 @@ func getGitHubPRNumber() (int, error) {
 				if event.Number > 0 {
 					return event.Number, nil
 				}
+				// Only set for PRs from branches of the repository
+				if prs := event.WorkflowRun.PullRequests; len(prs) == 1 {
+					return prs[0].Number, nil
+				}
 			}
 		}
 	}
@@ -152,5 +335,244 @@ func detectGitLab() (*Environment, error) {
 		PRNumber:   mrNumber,
 		Token:      token,
 		ServerHost: serverHost,
+		HeadSHA:    getGitLabHeadSHA(),
+		RunURL:     os.Getenv("CI_JOB_URL"),
+	}, nil
+}
+
+// getGitLabHeadSHA returns the head commit of the merge request. CI_COMMIT_SHA
+// is the merge commit in merged results pipelines.
+func getGitLabHeadSHA() string {
+	if sha := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA"); sha != "" {
+		return sha
+	}
+	return os.Getenv("CI_COMMIT_SHA")
+}
+
+// detectBitbucket detects Bitbucket Pipelines environment
+func detectBitbucket() (*Environment, error) {
+	// Get repository (format: workspace/repo)
+	repository := os.Getenv("BITBUCKET_REPO_FULL_NAME")
+	if repository == "" {
+		return nil, fmt.Errorf("BITBUCKET_REPO_FULL_NAME not set")
+	}
+
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid BITBUCKET_REPO_FULL_NAME format: %s", repository)
+	}
+
+	// Get PR number
+	prID := os.Getenv("BITBUCKET_PR_ID")
+	if prID == "" {
+		return nil, fmt.Errorf("BITBUCKET_PR_ID not set: not running in a pull-requests pipeline")
+	}
+
+	prNumber, err := strconv.Atoi(prID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BITBUCKET_PR_ID: %s", prID)
+	}
+
+	// Get credentials (prefer an access token, fallback to an app password)
+	token := os.Getenv("BITBUCKET_TOKEN")
+	username := ""
+	if token == "" {
+		username = os.Getenv("BITBUCKET_USERNAME")
+		token = os.Getenv("BITBUCKET_APP_PASSWORD")
+		if username == "" || token == "" {
+			return nil, fmt.Errorf("BITBUCKET_TOKEN or BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD not set")
+		}
+	}
+
+	return &Environment{
+		Provider:   "bitbucket",
+		Owner:      parts[0],
+		Repo:       parts[1],
+		PRNumber:   prNumber,
+		Token:      token,
+		Username:   username,
+		ServerHost: "bitbucket.org",
+		HeadSHA:    os.Getenv("BITBUCKET_COMMIT"),
+	}, nil
+}
+
+// detectBitbucketServer detects a Bitbucket Server or Data Center instance.
+// These have no CI of their own, so the pull request is described by
+// BITBUCKET_* variables, with fallbacks on the Jenkins multibranch ones.
+func detectBitbucketServer() (*Environment, error) {
+	serverURL := strings.TrimSuffix(os.Getenv("BITBUCKET_SERVER_URL"), "/")
+	parsed, err := url.Parse(serverURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid BITBUCKET_SERVER_URL: %s", serverURL)
+	}
+
+	project := os.Getenv("BITBUCKET_PROJECT_KEY")
+	if project == "" {
+		return nil, fmt.Errorf("BITBUCKET_PROJECT_KEY not set")
+	}
+	repo := os.Getenv("BITBUCKET_REPO_SLUG")
+	if repo == "" {
+		return nil, fmt.Errorf("BITBUCKET_REPO_SLUG not set")
+	}
+
+	// Get PR number (CHANGE_ID is set by Jenkins for pull requests)
+	prID := os.Getenv("BITBUCKET_PR_ID")
+	if prID == "" {
+		prID = os.Getenv("CHANGE_ID")
+	}
+	if prID == "" {
+		return nil, fmt.Errorf("BITBUCKET_PR_ID not set: not running for a pull request")
+	}
+
+	prNumber, err := strconv.Atoi(prID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BITBUCKET_PR_ID: %s", prID)
+	}
+
+	// Get personal access token
+	token := os.Getenv("BITBUCKET_SERVER_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("BITBUCKET_SERVER_TOKEN not set")
+	}
+
+	headSHA := os.Getenv("BITBUCKET_COMMIT")
+	if headSHA == "" {
+		headSHA = os.Getenv("GIT_COMMIT")
+	}
+
+	return &Environment{
+		Provider:   "bitbucket-server",
+		Owner:      project,
+		Repo:       repo,
+		PRNumber:   prNumber,
+		Token:      token,
+		ServerHost: parsed.Host,
+		ServerURL:  serverURL,
+		HeadSHA:    headSHA,
+	}, nil
+}
+
+// detectAzure detects Azure Pipelines environment
+func detectAzure() (*Environment, error) {
+	// Only Azure Repos pull requests are supported
+	if provider := os.Getenv("BUILD_REPOSITORY_PROVIDER"); provider != "" && provider != "TfsGit" {
+		return nil, fmt.Errorf("unsupported BUILD_REPOSITORY_PROVIDER: %s (only Azure Repos is supported)", provider)
+	}
+
+	collectionURL := os.Getenv("SYSTEM_COLLECTIONURI")
+	parsed, err := url.Parse(collectionURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid SYSTEM_COLLECTIONURI: %q", collectionURL)
+	}
+
+	project := os.Getenv("SYSTEM_TEAMPROJECT")
+	if project == "" {
+		return nil, fmt.Errorf("SYSTEM_TEAMPROJECT not set")
+	}
+	repo := os.Getenv("BUILD_REPOSITORY_NAME")
+	if repo == "" {
+		return nil, fmt.Errorf("BUILD_REPOSITORY_NAME not set")
+	}
+
+	// Get PR number
+	prID := os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID")
+	if prID == "" {
+		return nil, fmt.Errorf("SYSTEM_PULLREQUEST_PULLREQUESTID not set: not running for a pull request")
+	}
+
+	prNumber, err := strconv.Atoi(prID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SYSTEM_PULLREQUEST_PULLREQUESTID: %s", prID)
+	}
+
+	// Get token (prefer the pipeline access token, fallback to a personal
+	// access token)
+	token := os.Getenv("SYSTEM_ACCESSTOKEN")
+	if token == "" {
+		token = os.Getenv("AZURE_DEVOPS_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("SYSTEM_ACCESSTOKEN or AZURE_DEVOPS_TOKEN not set")
+	}
+
+	return &Environment{
+		Provider:   "azure",
+		Owner:      project,
+		Repo:       repo,
+		PRNumber:   prNumber,
+		Token:      token,
+		ServerHost: parsed.Host,
+		ServerURL:  strings.TrimSuffix(collectionURL, "/"),
+		HeadSHA:    os.Getenv("SYSTEM_PULLREQUEST_SOURCECOMMITID"),
+	}, nil
+}
+
+// detectGerrit detects a Gerrit change built by the Jenkins Gerrit Trigger
+func detectGerrit() (*Environment, error) {
+	changeNumber := os.Getenv("GERRIT_CHANGE_NUMBER")
+	number, err := strconv.Atoi(changeNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GERRIT_CHANGE_NUMBER: %s", changeNumber)
+	}
+
+	project := os.Getenv("GERRIT_PROJECT")
+	if project == "" {
+		return nil, fmt.Errorf("GERRIT_PROJECT not set")
+	}
+
+	serverURL, err := getGerritURL()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get credentials (an HTTP password, generated in the user settings)
+	username := os.Getenv("GERRIT_USERNAME")
+	password := os.Getenv("GERRIT_HTTP_PASSWORD")
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("GERRIT_USERNAME and GERRIT_HTTP_PASSWORD not set")
+	}
+
+	return &Environment{
+		Provider:   "gerrit",
+		Repo:       project,
+		PRNumber:   number,
+		Token:      password,
+		Username:   username,
+		ServerHost: serverURL.Host,
+		ServerURL:  strings.TrimSuffix(serverURL.String(), "/"),
+		HeadSHA:    os.Getenv("GERRIT_PATCHSET_REVISION"),
 	}, nil
 }
+
+// getGerritURL returns the base URL of the Gerrit instance: GERRIT_URL, or
+// the URL GERRIT_CHANGE_URL (e.g. https://gerrit.example.com/c/project/+/123
+// or https://gerrit.example.com/123) is relative to
+func getGerritURL() (*url.URL, error) {
+	if gerritURL := os.Getenv("GERRIT_URL"); gerritURL != "" {
+		parsed, err := url.Parse(gerritURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid GERRIT_URL: %s", gerritURL)
+		}
+		return parsed, nil
+	}
+
+	changeURL := os.Getenv("GERRIT_CHANGE_URL")
+	if changeURL == "" {
+		return nil, fmt.Errorf("GERRIT_URL or GERRIT_CHANGE_URL not set")
+	}
+	parsed, err := url.Parse(changeURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid GERRIT_CHANGE_URL: %s", changeURL)
+	}
+
+	if i := strings.Index(parsed.Path, "/c/"); i >= 0 {
+		parsed.Path = parsed.Path[:i]
+	} else {
+		parsed.Path = parsed.Path[:strings.LastIndex(strings.TrimSuffix(parsed.Path, "/"), "/")+1]
+	}
+	parsed.RawPath = ""
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	return parsed, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -33,8 +34,20 @@ func setEnv(t *testing.T, envs map[string]string) func() {
 func clearCIEnvVars(t *testing.T) func() {
 	t.Helper()
 	envVars := []string{
-		"GITHUB_ACTIONS", "GITHUB_REPOSITORY", "GITHUB_REF", "GITHUB_TOKEN", "GITHUB_EVENT_PATH",
+		"GITHUB_ACTIONS", "GITHUB_REPOSITORY", "GITHUB_REF", "GITHUB_TOKEN", "GITHUB_EVENT_PATH", "GITHUB_SHA",
+		"GITHUB_EVENT_NAME", "GITHUB_RUN_ID", "CI_JOB_URL",
 		"GITLAB_CI", "CI_PROJECT_PATH", "CI_MERGE_REQUEST_IID", "GITLAB_TOKEN", "CI_JOB_TOKEN", "CI_SERVER_HOST",
+		"CI_COMMIT_SHA", "CI_MERGE_REQUEST_SOURCE_BRANCH_SHA",
+		"BITBUCKET_BUILD_NUMBER", "BITBUCKET_REPO_FULL_NAME", "BITBUCKET_PR_ID", "BITBUCKET_COMMIT",
+		"BITBUCKET_TOKEN", "BITBUCKET_USERNAME", "BITBUCKET_APP_PASSWORD",
+		"BITBUCKET_SERVER_URL", "BITBUCKET_PROJECT_KEY", "BITBUCKET_REPO_SLUG", "BITBUCKET_SERVER_TOKEN",
+		"CHANGE_ID", "GIT_COMMIT",
+		"GITEA_ACTIONS", "FORGEJO_ACTIONS", "GITEA_TOKEN", "GITHUB_SERVER_URL", "GITHUB_API_URL", "GITHUB_CA_BUNDLE",
+		"GITHUB_APP_ID", "GITHUB_APP_PRIVATE_KEY", "GITHUB_APP_PRIVATE_KEY_PATH", "GITHUB_APP_INSTALLATION_ID",
+		"TF_BUILD", "BUILD_REPOSITORY_PROVIDER", "SYSTEM_COLLECTIONURI", "SYSTEM_TEAMPROJECT", "BUILD_REPOSITORY_NAME",
+		"SYSTEM_PULLREQUEST_PULLREQUESTID", "SYSTEM_PULLREQUEST_SOURCECOMMITID", "SYSTEM_ACCESSTOKEN", "AZURE_DEVOPS_TOKEN",
+		"GERRIT_CHANGE_NUMBER", "GERRIT_PATCHSET_REVISION", "GERRIT_PROJECT", "GERRIT_CHANGE_URL", "GERRIT_URL",
+		"GERRIT_USERNAME", "GERRIT_HTTP_PASSWORD",
 	}
 
 	originalVals := make(map[string]string)
@@ -91,6 +104,153 @@ func TestDetect_GitHub(t *testing.T) {
 	if env.ServerHost != "github.com" {
 		t.Errorf("expected server host 'github.com', got %s", env.ServerHost)
 	}
+	if env.APIURL != "https://api.github.com" {
+		t.Errorf("expected API URL 'https://api.github.com', got %s", env.APIURL)
+	}
+	if env.RunURL != "" {
+		t.Errorf("expected no run URL without GITHUB_RUN_ID, got %s", env.RunURL)
+	}
+}
+
+func TestDetect_GitHubEnterpriseServer(t *testing.T) {
+	cleanup := clearCIEnvVars(t)
+	defer cleanup()
+
+	envCleanup := setEnv(t, map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_REPOSITORY": "owner/repo",
+		"GITHUB_REF":        "refs/pull/123/merge",
+		"GITHUB_TOKEN":      "test-token",
+		"GITHUB_SERVER_URL": "https://github.example.com",
+		"GITHUB_API_URL":    "https://github.example.com/api/v3/",
+		"GITHUB_CA_BUNDLE":  "/etc/ssl/internal-ca.pem",
+		"GITHUB_RUN_ID":     "789",
+	})
+	defer envCleanup()
+
+	env, err := Detect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if env.Provider != "github" || env.ServerHost != "github.example.com" || env.ServerURL != "https://github.example.com" {
+		t.Errorf("unexpected server: %+v", env)
+	}
+	if env.APIURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected API URL 'https://github.example.com/api/v3', got %s", env.APIURL)
+	}
+	if env.CABundle != "/etc/ssl/internal-ca.pem" {
+		t.Errorf("expected CA bundle '/etc/ssl/internal-ca.pem', got %s", env.CABundle)
+	}
+	if env.RunURL != "https://github.example.com/owner/repo/actions/runs/789" {
+		t.Errorf("expected run URL 'https://github.example.com/owner/repo/actions/runs/789', got %s", env.RunURL)
+	}
+}
+
+func TestDetect_GitHubApp(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(keyPath, []byte("key from file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		env              map[string]string
+		wantErr          string
+		wantKey          string
+		wantInstallation int64
+	}{
+		{
+			name: "inline key",
+			env: map[string]string{
+				"GITHUB_APP_ID":              "42",
+				"GITHUB_APP_PRIVATE_KEY":     "inline key",
+				"GITHUB_APP_INSTALLATION_ID": "7",
+			},
+			wantKey:          "inline key",
+			wantInstallation: 7,
+		},
+		{
+			name: "key file",
+			env: map[string]string{
+				"GITHUB_APP_ID":               "42",
+				"GITHUB_APP_PRIVATE_KEY_PATH": keyPath,
+			},
+			wantKey: "key from file",
+		},
+		{
+			name:    "missing key",
+			env:     map[string]string{"GITHUB_APP_ID": "42"},
+			wantErr: "GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH not set",
+		},
+		{
+			name: "missing key file",
+			env: map[string]string{
+				"GITHUB_APP_ID":               "42",
+				"GITHUB_APP_PRIVATE_KEY_PATH": filepath.Join(t.TempDir(), "missing.pem"),
+			},
+			wantErr: "failed to read GITHUB_APP_PRIVATE_KEY_PATH",
+		},
+		{
+			name: "invalid app ID",
+			env: map[string]string{
+				"GITHUB_APP_ID":          "my-app",
+				"GITHUB_APP_PRIVATE_KEY": "inline key",
+			},
+			wantErr: "invalid GITHUB_APP_ID",
+		},
+		{
+			name: "invalid installation ID",
+			env: map[string]string{
+				"GITHUB_APP_ID":              "42",
+				"GITHUB_APP_PRIVATE_KEY":     "inline key",
+				"GITHUB_APP_INSTALLATION_ID": "none",
+			},
+			wantErr: "invalid GITHUB_APP_INSTALLATION_ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			vars := map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_REF":        "refs/pull/123/merge",
+			}
+			for key, value := range tt.env {
+				vars[key] = value
+			}
+			envCleanup := setEnv(t, vars)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.AppID != 42 {
+				t.Errorf("expected app ID 42, got %d", env.AppID)
+			}
+			if env.AppPrivateKey != tt.wantKey {
+				t.Errorf("expected private key %q, got %q", tt.wantKey, env.AppPrivateKey)
+			}
+			if env.AppInstallationID != tt.wantInstallation {
+				t.Errorf("expected installation ID %d, got %d", tt.wantInstallation, env.AppInstallationID)
+			}
+			if env.Token != "" {
+				t.Errorf("expected no token, got %s", env.Token)
+			}
+		})
+	}
 }
 
 func TestDetect_GitLab(t *testing.T) {
@@ -103,6 +263,7 @@ func TestDetect_GitLab(t *testing.T) {
 		"CI_MERGE_REQUEST_IID": "456",
 		"GITLAB_TOKEN":         "gitlab-token",
 		"CI_SERVER_HOST":       "gitlab.example.com",
+		"CI_JOB_URL":           "https://gitlab.example.com/group/project/-/jobs/42",
 	})
 	defer envCleanup()
 
@@ -129,6 +290,9 @@ func TestDetect_GitLab(t *testing.T) {
 	if env.ServerHost != "gitlab.example.com" {
 		t.Errorf("expected server host 'gitlab.example.com', got %s", env.ServerHost)
 	}
+	if env.RunURL != "https://gitlab.example.com/group/project/-/jobs/42" {
+		t.Errorf("expected run URL of the job, got %s", env.RunURL)
+	}
 }
 
 func TestDetect_NoCIEnvironment(t *testing.T) {
@@ -139,7 +303,7 @@ func TestDetect_NoCIEnvironment(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when no CI environment is detected")
 	}
-	if err.Error() != "not running in a supported CI environment (GitHub Actions or GitLab CI)" {
+	if err.Error() != "not running in a supported CI environment (GitHub Actions, Gitea Actions, GitLab CI, Bitbucket Pipelines, Azure Pipelines, Gerrit Trigger or Bitbucket Server)" {
 		t.Errorf("unexpected error message: %v", err)
 	}
 }
@@ -292,6 +456,132 @@ func TestDetectGitHub_PRNumberFromEventFile(t *testing.T) {
 	}
 }
 
+func TestDetectGitHub_HeadSHA(t *testing.T) {
+	cleanup := clearCIEnvVars(t)
+	defer cleanup()
+
+	eventFile := filepath.Join(t.TempDir(), "event.json")
+	data, _ := json.Marshal(map[string]interface{}{
+		"pull_request": map[string]interface{}{
+			"number": 12,
+			"head":   map[string]interface{}{"sha": "headsha"},
+		},
+	})
+	_ = os.WriteFile(eventFile, data, 0644)
+
+	envCleanup := setEnv(t, map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_REPOSITORY": "owner/repo",
+		"GITHUB_EVENT_PATH": eventFile,
+		"GITHUB_TOKEN":      "token",
+		"GITHUB_SHA":        "mergesha",
+	})
+	defer envCleanup()
+
+	env, err := Detect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env.HeadSHA != "headsha" {
+		t.Errorf("expected head SHA from event file, got %q", env.HeadSHA)
+	}
+}
+
+func TestDetectGitHub_WorkflowRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		pullRequests []map[string]interface{}
+		wantPR       int
+	}{
+		{
+			name:         "PR from a branch of the repository",
+			pullRequests: []map[string]interface{}{{"number": 12}},
+			wantPR:       12,
+		},
+		{
+			name:         "PR from a fork",
+			pullRequests: []map[string]interface{}{},
+			wantPR:       0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			eventFile := filepath.Join(t.TempDir(), "event.json")
+			data, _ := json.Marshal(map[string]interface{}{
+				"workflow_run": map[string]interface{}{
+					"head_sha":      "runsha",
+					"pull_requests": tt.pullRequests,
+				},
+			})
+			_ = os.WriteFile(eventFile, data, 0644)
+
+			envCleanup := setEnv(t, map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_REF":        "refs/heads/main",
+				"GITHUB_EVENT_NAME": "workflow_run",
+				"GITHUB_EVENT_PATH": eventFile,
+				"GITHUB_TOKEN":      "token",
+				"GITHUB_SHA":        "mainsha",
+			})
+			defer envCleanup()
+
+			env, err := Detect()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if env.PRNumber != tt.wantPR {
+				t.Errorf("expected PR number %d, got %d", tt.wantPR, env.PRNumber)
+			}
+			if env.HeadSHA != "runsha" {
+				t.Errorf("expected head SHA of the workflow run, got %q", env.HeadSHA)
+			}
+		})
+	}
+}
+
+func TestDetectGitLab_HeadSHA(t *testing.T) {
+	tests := []struct {
+		name string
+		envs map[string]string
+		want string
+	}{
+		{"commit", map[string]string{"CI_COMMIT_SHA": "commitsha"}, "commitsha"},
+		{"merged results", map[string]string{"CI_COMMIT_SHA": "mergesha", "CI_MERGE_REQUEST_SOURCE_BRANCH_SHA": "headsha"}, "headsha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"GITLAB_CI":            "true",
+				"CI_PROJECT_PATH":      "group/project",
+				"CI_MERGE_REQUEST_IID": "3",
+				"GITLAB_TOKEN":         "token",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if env.HeadSHA != tt.want {
+				t.Errorf("HeadSHA = %q, want %q", env.HeadSHA, tt.want)
+			}
+		})
+	}
+}
+
 func TestDetectGitHub_PRNumberFromEventFileTopLevel(t *testing.T) {
 	cleanup := clearCIEnvVars(t)
 	defer cleanup()
@@ -522,3 +812,372 @@ func TestEnvironment_Fields(t *testing.T) {
 		t.Errorf("server host mismatch")
 	}
 }
+
+func TestDetect_Bitbucket(t *testing.T) {
+	tests := []struct {
+		name         string
+		envs         map[string]string
+		wantToken    string
+		wantUsername string
+		wantErr      string
+	}{
+		{
+			name:      "access token",
+			envs:      map[string]string{"BITBUCKET_TOKEN": "token"},
+			wantToken: "token",
+		},
+		{
+			name:         "app password",
+			envs:         map[string]string{"BITBUCKET_USERNAME": "user", "BITBUCKET_APP_PASSWORD": "password"},
+			wantToken:    "password",
+			wantUsername: "user",
+		},
+		{
+			name:    "missing credentials",
+			envs:    map[string]string{"BITBUCKET_USERNAME": "user"},
+			wantErr: "BITBUCKET_TOKEN",
+		},
+		{
+			name:    "not a pull request",
+			envs:    map[string]string{"BITBUCKET_TOKEN": "token", "BITBUCKET_PR_ID": ""},
+			wantErr: "BITBUCKET_PR_ID not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"BITBUCKET_BUILD_NUMBER":   "42",
+				"BITBUCKET_REPO_FULL_NAME": "workspace/repo",
+				"BITBUCKET_PR_ID":          "7",
+				"BITBUCKET_COMMIT":         "abc123",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.Provider != "bitbucket" || env.Owner != "workspace" || env.Repo != "repo" || env.PRNumber != 7 || env.HeadSHA != "abc123" {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+			if env.Token != tt.wantToken || env.Username != tt.wantUsername {
+				t.Errorf("credentials = %q/%q, want %q/%q", env.Username, env.Token, tt.wantUsername, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestDetect_BitbucketServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		wantPR  int
+		wantSHA string
+		wantErr string
+	}{
+		{
+			name:    "explicit variables",
+			envs:    map[string]string{"BITBUCKET_PR_ID": "7", "BITBUCKET_COMMIT": "abc123"},
+			wantPR:  7,
+			wantSHA: "abc123",
+		},
+		{
+			name:    "jenkins variables",
+			envs:    map[string]string{"CHANGE_ID": "8", "GIT_COMMIT": "def456"},
+			wantPR:  8,
+			wantSHA: "def456",
+		},
+		{
+			name:    "not a pull request",
+			wantErr: "BITBUCKET_PR_ID not set",
+		},
+		{
+			name:    "missing token",
+			envs:    map[string]string{"BITBUCKET_PR_ID": "7", "BITBUCKET_SERVER_TOKEN": ""},
+			wantErr: "BITBUCKET_SERVER_TOKEN not set",
+		},
+		{
+			name:    "invalid server URL",
+			envs:    map[string]string{"BITBUCKET_PR_ID": "7", "BITBUCKET_SERVER_URL": "bitbucket.example.com"},
+			wantErr: "invalid BITBUCKET_SERVER_URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"BITBUCKET_SERVER_URL":   "https://git.example.com/bitbucket/",
+				"BITBUCKET_PROJECT_KEY":  "PROJ",
+				"BITBUCKET_REPO_SLUG":    "repo",
+				"BITBUCKET_SERVER_TOKEN": "token",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.Provider != "bitbucket-server" || env.Owner != "PROJ" || env.Repo != "repo" || env.Token != "token" {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+			if env.ServerURL != "https://git.example.com/bitbucket" || env.ServerHost != "git.example.com" {
+				t.Errorf("server = %q (%q), want https://git.example.com/bitbucket (git.example.com)", env.ServerURL, env.ServerHost)
+			}
+			if env.PRNumber != tt.wantPR || env.HeadSHA != tt.wantSHA {
+				t.Errorf("PR = %d at %q, want %d at %q", env.PRNumber, env.HeadSHA, tt.wantPR, tt.wantSHA)
+			}
+		})
+	}
+}
+
+func TestDetect_Gitea(t *testing.T) {
+	tests := []struct {
+		name      string
+		envs      map[string]string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:      "gitea actions",
+			envs:      map[string]string{"GITEA_ACTIONS": "true", "GITHUB_TOKEN": "token"},
+			wantToken: "token",
+		},
+		{
+			name:      "forgejo actions with dedicated token",
+			envs:      map[string]string{"FORGEJO_ACTIONS": "true", "GITEA_TOKEN": "gitea", "GITHUB_TOKEN": "token"},
+			wantToken: "gitea",
+		},
+		{
+			name:    "missing token",
+			envs:    map[string]string{"GITEA_ACTIONS": "true"},
+			wantErr: "GITEA_TOKEN or GITHUB_TOKEN not set",
+		},
+		{
+			name:    "missing server URL",
+			envs:    map[string]string{"GITEA_ACTIONS": "true", "GITHUB_TOKEN": "token", "GITHUB_SERVER_URL": ""},
+			wantErr: "invalid GITHUB_SERVER_URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_SERVER_URL": "https://forge.example.com/",
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_REF":        "refs/pull/5/head",
+				"GITHUB_SHA":        "abc123",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.Provider != "gitea" || env.Owner != "owner" || env.Repo != "repo" || env.PRNumber != 5 || env.HeadSHA != "abc123" {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+			if env.ServerURL != "https://forge.example.com" || env.ServerHost != "forge.example.com" {
+				t.Errorf("server = %q (%q), want https://forge.example.com (forge.example.com)", env.ServerURL, env.ServerHost)
+			}
+			if env.Token != tt.wantToken {
+				t.Errorf("token = %q, want %q", env.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestDetect_Azure(t *testing.T) {
+	tests := []struct {
+		name      string
+		envs      map[string]string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:      "system access token",
+			envs:      map[string]string{"SYSTEM_ACCESSTOKEN": "system", "AZURE_DEVOPS_TOKEN": "pat"},
+			wantToken: "system",
+		},
+		{
+			name:      "personal access token",
+			envs:      map[string]string{"AZURE_DEVOPS_TOKEN": "pat"},
+			wantToken: "pat",
+		},
+		{
+			name:    "missing token",
+			wantErr: "SYSTEM_ACCESSTOKEN or AZURE_DEVOPS_TOKEN not set",
+		},
+		{
+			name:    "not a pull request",
+			envs:    map[string]string{"SYSTEM_ACCESSTOKEN": "system", "SYSTEM_PULLREQUEST_PULLREQUESTID": ""},
+			wantErr: "SYSTEM_PULLREQUEST_PULLREQUESTID not set",
+		},
+		{
+			name:    "github repository",
+			envs:    map[string]string{"SYSTEM_ACCESSTOKEN": "system", "BUILD_REPOSITORY_PROVIDER": "GitHub"},
+			wantErr: "unsupported BUILD_REPOSITORY_PROVIDER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"TF_BUILD":                          "True",
+				"BUILD_REPOSITORY_PROVIDER":         "TfsGit",
+				"SYSTEM_COLLECTIONURI":              "https://dev.azure.com/org/",
+				"SYSTEM_TEAMPROJECT":                "Project",
+				"BUILD_REPOSITORY_NAME":             "repo",
+				"SYSTEM_PULLREQUEST_PULLREQUESTID":  "12",
+				"SYSTEM_PULLREQUEST_SOURCECOMMITID": "abc123",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.Provider != "azure" || env.Owner != "Project" || env.Repo != "repo" || env.PRNumber != 12 || env.HeadSHA != "abc123" {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+			if env.ServerURL != "https://dev.azure.com/org" || env.ServerHost != "dev.azure.com" {
+				t.Errorf("server = %q (%q), want https://dev.azure.com/org (dev.azure.com)", env.ServerURL, env.ServerHost)
+			}
+			if env.Token != tt.wantToken {
+				t.Errorf("token = %q, want %q", env.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestDetect_Gerrit(t *testing.T) {
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "change URL",
+			envs:    map[string]string{"GERRIT_CHANGE_URL": "https://gerrit.example.com/c/platform/tools/+/42"},
+			wantURL: "https://gerrit.example.com",
+		},
+		{
+			name:    "legacy change URL with context path",
+			envs:    map[string]string{"GERRIT_CHANGE_URL": "https://example.com/gerrit/42"},
+			wantURL: "https://example.com/gerrit",
+		},
+		{
+			name:    "explicit URL",
+			envs:    map[string]string{"GERRIT_URL": "https://review.example.com/", "GERRIT_CHANGE_URL": "https://gerrit.example.com/42"},
+			wantURL: "https://review.example.com",
+		},
+		{
+			name:    "missing URL",
+			wantErr: "GERRIT_URL or GERRIT_CHANGE_URL not set",
+		},
+		{
+			name:    "missing credentials",
+			envs:    map[string]string{"GERRIT_CHANGE_URL": "https://gerrit.example.com/42", "GERRIT_HTTP_PASSWORD": ""},
+			wantErr: "GERRIT_USERNAME and GERRIT_HTTP_PASSWORD not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearCIEnvVars(t)
+			defer cleanup()
+
+			envs := map[string]string{
+				"GERRIT_CHANGE_NUMBER":     "42",
+				"GERRIT_PATCHSET_REVISION": "abc123",
+				"GERRIT_PROJECT":           "platform/tools",
+				"GERRIT_USERNAME":          "bot",
+				"GERRIT_HTTP_PASSWORD":     "secret",
+			}
+			for key, value := range tt.envs {
+				envs[key] = value
+			}
+			envCleanup := setEnv(t, envs)
+			defer envCleanup()
+
+			env, err := Detect()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if env.Provider != "gerrit" || env.Repo != "platform/tools" || env.PRNumber != 42 || env.HeadSHA != "abc123" {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+			if env.Username != "bot" || env.Token != "secret" {
+				t.Errorf("credentials = %q/%q, want bot/secret", env.Username, env.Token)
+			}
+			if env.ServerURL != tt.wantURL {
+				t.Errorf("ServerURL = %q, want %q", env.ServerURL, tt.wantURL)
+			}
+		})
+	}
+}
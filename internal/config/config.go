@@ -1,73 +1,316 @@
 package config
 
 import (
+	"context"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"strings"
 )
 
 // Config represents the ainspector configuration
 type Config struct {
-	Ignore  IgnoreConfig  `yaml:"ignore"`
-	Context ContextConfig `yaml:"context"`
-	Rules   []string      `yaml:"rules"`
+	Extends   Extends                   `yaml:"extends,omitempty"`
+	Ignore    IgnoreConfig              `yaml:"ignore,omitempty"`
+	Context   ContextConfig             `yaml:"context,omitempty"`
+	Rules     []Rule                    `yaml:"rules,omitempty"`
+	Prompts   PromptsConfig             `yaml:"prompts,omitempty"`
+	Languages map[string]LanguageConfig `yaml:"languages,omitempty"`
+	Cache     CacheConfig               `yaml:"cache,omitempty"`
+	Comments  CommentsConfig            `yaml:"comments,omitempty"`
+	Checks    ChecksConfig              `yaml:"checks,omitempty"`
+	Status    StatusConfig              `yaml:"status,omitempty"`
+
+	// nested holds the configuration files found in subdirectories, keyed by
+	// directory; merged caches the effective configuration of each directory
+	nested map[string]*Config
+	merged map[string]*Config
 }
 
 // IgnoreConfig holds patterns for files to ignore during review
 type IgnoreConfig struct {
 	// Paths contains glob patterns (supports ** for recursive matching)
-	Paths []string `yaml:"paths"`
+	Paths []string `yaml:"paths,omitempty"`
 }
 
 // ContextConfig holds patterns for files to include in project context
 type ContextConfig struct {
 	// Include contains glob patterns for files/folders to include in context
-	Include []string `yaml:"include"`
+	Include []string `yaml:"include,omitempty"`
 	// Exclude contains glob patterns to exclude (takes priority over Include)
-	Exclude []string `yaml:"exclude"`
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// remote holds the content of the files included by configurations
+	// extended from other repositories, keyed by their remote reference
+	remote map[string]string
+}
+
+// PromptsConfig holds paths to prompt templates overriding the built-in ones
+type PromptsConfig struct {
+	// System is the path to a text/template file used for the system prompt
+	System string `yaml:"system,omitempty"`
+	// User is the path to a text/template file used for the user prompt
+	User string `yaml:"user,omitempty"`
+
+	// remote holds the content of the templates set by configurations
+	// extended from other repositories, keyed by their remote reference
+	remote map[string]string
+}
+
+// ReadTemplate returns the content of a prompt template, which is either a
+// local file or a template of a configuration extended from another repository
+func (p *PromptsConfig) ReadTemplate(filePath string) (string, error) {
+	if content, ok := p.remote[filePath]; ok {
+		return content, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Invalidation policies of previous reviews when the review settings change
+const (
+	// InvalidateRules re-reviews functions when the rules or the language
+	// checklist applying to them change
+	InvalidateRules = "rules"
+	// InvalidateAny also re-reviews functions when the model, the prompt
+	// templates or the project context change
+	InvalidateAny = "any"
+	// InvalidateNever only re-reviews functions when their code changes
+	InvalidateNever = "never"
+)
+
+// Function hashing modes, deciding which code changes trigger a new review
+const (
+	// HashExact re-reviews functions on any change of their content or diff
+	HashExact = "exact"
+	// HashTokens ignores whitespace, comments and line shifts
+	HashTokens = "tokens"
+)
+
+// CacheConfig controls when previous reviews are reused
+type CacheConfig struct {
+	// Invalidate is the invalidation policy (rules, any or never, default rules)
+	Invalidate string `yaml:"invalidate,omitempty"`
+	// Hash is the function hashing mode (exact or tokens, default exact)
+	Hash string `yaml:"hash,omitempty"`
+}
+
+// InvalidatePolicy returns the invalidation policy, defaulting to InvalidateRules
+func (c CacheConfig) InvalidatePolicy() string {
+	if c.Invalidate == "" {
+		return InvalidateRules
+	}
+	return c.Invalidate
+}
+
+// HashMode returns the function hashing mode, defaulting to HashExact
+func (c CacheConfig) HashMode() string {
+	if c.Hash == "" {
+		return HashExact
+	}
+	return c.Hash
+}
+
+// Handling of the comments of functions changed since their review
+const (
+	// OutdatedResolve replies that the issue is fixed and resolves the thread
+	OutdatedResolve = "resolve"
+	// OutdatedReply replies that the issue is fixed, leaving the thread open
+	OutdatedReply = "reply"
+	// OutdatedOff leaves the comments untouched
+	OutdatedOff = "off"
+)
+
+// Handling of findings already reported by an open comment
+const (
+	// DuplicatesSkip doesn't post the finding again
+	DuplicatesSkip = "skip"
+	// DuplicatesBump replies in the existing thread that the issue is still present
+	DuplicatesBump = "bump"
+	// DuplicatesOff posts the finding again
+	DuplicatesOff = "off"
+)
+
+// CommentsConfig controls how ainspector manages its review comments
+type CommentsConfig struct {
+	// Outdated is the handling of the comments of functions changed since
+	// their review (resolve, reply or off, default resolve)
+	Outdated string `yaml:"outdated,omitempty"`
+	// Duplicates is the handling of findings already reported by an open
+	// comment (skip, bump or off, default skip)
+	Duplicates string `yaml:"duplicates,omitempty"`
+}
+
+// OutdatedAction returns the handling of outdated comments, defaulting to OutdatedResolve
+func (c CommentsConfig) OutdatedAction() string {
+	if c.Outdated == "" {
+		return OutdatedResolve
+	}
+	return c.Outdated
+}
+
+// DuplicatesAction returns the handling of duplicate findings, defaulting to DuplicatesSkip
+func (c CommentsConfig) DuplicatesAction() string {
+	if c.Duplicates == "" {
+		return DuplicatesSkip
+	}
+	return c.Duplicates
+}
+
+// FailNever is the fail_on setting of checks and commit statuses that never fail
+const FailNever = "never"
+
+// ChecksConfig controls the check run reporting the findings on the head
+// commit, on the git hosts supporting it (GitHub)
+type ChecksConfig struct {
+	// Enabled creates a check run with an annotation per finding (default false)
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Comments also posts the findings as review comments when the check run
+	// is enabled (default true)
+	Comments *bool `yaml:"comments,omitempty"`
+	// FailOn is the lowest severity of the findings failing the check
+	// (critical, warning, info or never, default critical)
+	FailOn string `yaml:"fail_on,omitempty"`
+}
+
+// IsEnabled reports whether a check run should be created
+func (c ChecksConfig) IsEnabled() bool {
+	return c.Enabled != nil && *c.Enabled
+}
+
+// CommentsEnabled reports whether the findings should be posted as review
+// comments, which can only be disabled in favor of the check run
+func (c ChecksConfig) CommentsEnabled() bool {
+	return !c.IsEnabled() || c.Comments == nil || *c.Comments
+}
+
+// FailSeverity returns the lowest severity failing the check, defaulting to
+// SeverityCritical
+func (c ChecksConfig) FailSeverity() string {
+	return failSeverity(c.FailOn)
+}
+
+// Fails reports whether a finding of the given severity fails the check
+func (c ChecksConfig) Fails(severity string) bool {
+	return fails(c.FailOn, severity)
+}
+
+// StatusConfig controls the commit status reporting the review on the head
+// commit of the PR/MR
+type StatusConfig struct {
+	// FailOn is the lowest severity of the findings failing the commit status
+	// (critical, warning, info or never, default critical)
+	FailOn string `yaml:"fail_on,omitempty"`
+}
+
+// FailSeverity returns the lowest severity failing the commit status,
+// defaulting to SeverityCritical
+func (s StatusConfig) FailSeverity() string {
+	return failSeverity(s.FailOn)
+}
+
+// Fails reports whether a finding of the given severity fails the commit status
+func (s StatusConfig) Fails(severity string) bool {
+	return fails(s.FailOn, severity)
+}
+
+// failSeverity returns a fail_on setting, defaulting to SeverityCritical
+func failSeverity(failOn string) string {
+	if failOn == "" {
+		return SeverityCritical
+	}
+	return failOn
+}
+
+// fails reports whether a finding of the given severity is at least as severe
+// as a fail_on setting
+func fails(failOn, severity string) bool {
+	rank := map[string]int{SeverityInfo: 1, SeverityWarning: 2, SeverityCritical: 3}
+	failOn = failSeverity(failOn)
+	return failOn != FailNever && rank[severity] >= rank[failOn]
+}
+
+// LanguageConfig customizes the review checklist of a language
+type LanguageConfig struct {
+	// BuiltinRules enables the built-in checklist for the language (default true)
+	BuiltinRules *bool `yaml:"builtin_rules,omitempty"`
+	// ExtraRules are appended to the checklist of the language
+	ExtraRules []string `yaml:"extra_rules,omitempty"`
+}
+
+// BuiltinRulesEnabled reports whether the built-in checklist should be used
+func (l LanguageConfig) BuiltinRulesEnabled() bool {
+	return l.BuiltinRules == nil || *l.BuiltinRules
+}
+
+// Language returns the checklist configuration for a language (case-insensitive)
+func (c *Config) Language(name string) LanguageConfig {
+	for key, lang := range c.Languages {
+		if strings.EqualFold(key, name) {
+			return lang
+		}
+	}
+	return LanguageConfig{}
 }
 
 // configFileNames lists the supported configuration file names in order of priority
 var configFileNames = []string{"ainspector.yaml", "ainspector.yml"}
 
-// Load reads the configuration from ainspector.yaml or ainspector.yml
+// Load reads the configuration from ainspector.yaml or ainspector.yml, along
+// with the nested configuration files found in subdirectories (see ForPath).
+// Configurations extending files of other repositories can't be loaded; use
+// LoadWithFetcher instead.
 // Returns an empty config (not an error) if no config file exists
 func Load() (*Config, error) {
+	return LoadWithFetcher(context.Background(), nil)
+}
+
+// LoadWithFetcher is like Load, but fetches the configurations extended from
+// other repositories with fetcher
+func LoadWithFetcher(ctx context.Context, fetcher RemoteFetcher) (*Config, error) {
+	l := &loader{ctx: ctx, fetcher: fetcher}
+
+	cfg, err := l.loadRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadNested(l, "."); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadRoot reads the configuration file of the working directory
+func (l *loader) loadRoot() (*Config, error) {
 	for _, filename := range configFileNames {
-		data, err := os.ReadFile(filename)
-		if err != nil {
+		if _, err := os.Stat(filename); err != nil {
 			if os.IsNotExist(err) {
 				continue
 			}
 			return nil, err
 		}
 
-		var cfg Config
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return nil, err
-		}
-
-		return &cfg, nil
+		return l.loadFile(filename, nil)
 	}
 
 	// No config file found, return empty config
 	return &Config{}, nil
 }
 
-// LoadFromPath reads the configuration from a specific path
+// LoadFromPath reads the configuration from a specific path. Local extends are
+// resolved; remote ones can't be fetched and return an error.
 func LoadFromPath(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
+	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
 			return &Config{}, nil
 		}
 		return nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
-	}
-
-	return &cfg, nil
+	l := &loader{ctx: context.Background()}
+	return l.loadFile(path, nil)
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -103,8 +104,8 @@ func TestLoad(t *testing.T) {
 		if len(cfg.Rules) != 3 {
 			t.Errorf("expected 3 rules, got %d", len(cfg.Rules))
 		}
-		if cfg.Rules[0] != "Exceptions must not be used for flow control" {
-			t.Errorf("unexpected first rule: %s", cfg.Rules[0])
+		if cfg.Rules[0].Text != "Exceptions must not be used for flow control" {
+			t.Errorf("unexpected first rule: %s", cfg.Rules[0].Text)
 		}
 	})
 
@@ -145,6 +146,168 @@ func TestLoad(t *testing.T) {
 	})
 }
 
+func TestLoad_Prompts(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	content := `prompts:
+  system: .ainspector/system.tmpl
+  user: .ainspector/user.tmpl
+`
+	_ = os.WriteFile("ainspector.yaml", []byte(content), 0644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Prompts.System != ".ainspector/system.tmpl" {
+		t.Errorf("expected system prompt path, got %q", cfg.Prompts.System)
+	}
+	if cfg.Prompts.User != ".ainspector/user.tmpl" {
+		t.Errorf("expected user prompt path, got %q", cfg.Prompts.User)
+	}
+}
+
+func TestLoad_Languages(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	content := `languages:
+  javascript:
+    builtin_rules: false
+    extra_rules:
+      - Use the logger from src/log instead of console
+  css:
+    extra_rules:
+      - Avoid !important
+`
+	_ = os.WriteFile("ainspector.yaml", []byte(content), 0644)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	js := cfg.Language("javascript")
+	if js.BuiltinRulesEnabled() {
+		t.Error("expected built-in rules to be disabled for javascript")
+	}
+	if len(js.ExtraRules) != 1 {
+		t.Errorf("expected 1 extra rule for javascript, got %d", len(js.ExtraRules))
+	}
+
+	css := cfg.Language("CSS")
+	if !css.BuiltinRulesEnabled() {
+		t.Error("expected built-in rules to be enabled by default")
+	}
+	if len(css.ExtraRules) != 1 || css.ExtraRules[0] != "Avoid !important" {
+		t.Errorf("unexpected extra rules for css: %v", css.ExtraRules)
+	}
+
+	if !cfg.Language("go").BuiltinRulesEnabled() {
+		t.Error("expected built-in rules to be enabled for unconfigured languages")
+	}
+}
+
+func TestChecksConfig(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name         string
+		checks       ChecksConfig
+		wantEnabled  bool
+		wantComments bool
+		wantFails    []string
+	}{
+		{
+			name:         "default",
+			wantComments: true,
+			wantFails:    []string{SeverityCritical},
+		},
+		{
+			name:         "comments can't be disabled without checks",
+			checks:       ChecksConfig{Comments: &no},
+			wantComments: true,
+			wantFails:    []string{SeverityCritical},
+		},
+		{
+			name:        "checks only",
+			checks:      ChecksConfig{Enabled: &yes, Comments: &no, FailOn: SeverityWarning},
+			wantEnabled: true,
+			wantFails:   []string{SeverityCritical, SeverityWarning},
+		},
+		{
+			name:         "never fail",
+			checks:       ChecksConfig{Enabled: &yes, FailOn: FailNever},
+			wantEnabled:  true,
+			wantComments: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.checks.IsEnabled(); got != tt.wantEnabled {
+				t.Errorf("IsEnabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			if got := tt.checks.CommentsEnabled(); got != tt.wantComments {
+				t.Errorf("CommentsEnabled() = %v, want %v", got, tt.wantComments)
+			}
+
+			var fails []string
+			for _, severity := range []string{SeverityCritical, SeverityWarning, SeverityInfo} {
+				if tt.checks.Fails(severity) {
+					fails = append(fails, severity)
+				}
+			}
+			if strings.Join(fails, ",") != strings.Join(tt.wantFails, ",") {
+				t.Errorf("failing severities = %v, want %v", fails, tt.wantFails)
+			}
+		})
+	}
+}
+
+func TestStatusConfig_Fails(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    StatusConfig
+		wantFails []string
+	}{
+		{
+			name:      "default",
+			wantFails: []string{SeverityCritical},
+		},
+		{
+			name:      "info",
+			status:    StatusConfig{FailOn: SeverityInfo},
+			wantFails: []string{SeverityCritical, SeverityWarning, SeverityInfo},
+		},
+		{
+			name:   "never fail",
+			status: StatusConfig{FailOn: FailNever},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fails []string
+			for _, severity := range []string{SeverityCritical, SeverityWarning, SeverityInfo} {
+				if tt.status.Fails(severity) {
+					fails = append(fails, severity)
+				}
+			}
+			if strings.Join(fails, ",") != strings.Join(tt.wantFails, ",") {
+				t.Errorf("failing severities = %v, want %v", fails, tt.wantFails)
+			}
+		})
+	}
+}
+
 func TestLoadFromPath(t *testing.T) {
 	t.Run("loads from specific path", func(t *testing.T) {
 		tmpDir := t.TempDir()
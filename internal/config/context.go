@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -26,6 +27,11 @@ func (c *ContextConfig) CollectContextFiles(projectRoot string) (map[string]stri
 
 	// Process each include pattern
 	for _, pattern := range c.Include {
+		if _, ok := c.remote[pattern]; ok {
+			explicitFiles = append(explicitFiles, pattern)
+			continue
+		}
+
 		files, err := collectFromPattern(projectRoot, pattern)
 		if err != nil {
 			// Non-existent paths generate warnings, not errors
@@ -57,6 +63,11 @@ func (c *ContextConfig) CollectContextFiles(projectRoot string) (map[string]stri
 			continue
 		}
 
+		if content, ok := c.remote[relPath]; ok {
+			fileMap[relPath] = content
+			continue
+		}
+
 		fullPath := filepath.Join(projectRoot, relPath)
 		content, err := os.ReadFile(fullPath)
 		if err != nil {
@@ -88,13 +99,14 @@ func collectFromPattern(projectRoot, pattern string) ([]string, error) {
 	hasGlob := strings.ContainsAny(pattern, "*?[]")
 
 	if hasGlob {
-		// Use doublestar to match glob patterns
-		basePath := projectRoot
+		// Use doublestar to match glob patterns, walking from the static part
+		// of the pattern so that it can point outside of projectRoot
+		base, glob := doublestar.SplitPattern(filepath.ToSlash(pattern))
 		var matches []string
 
-		err := doublestar.GlobWalk(os.DirFS(basePath), pattern, func(path string, d fs.DirEntry) error {
+		err := doublestar.GlobWalk(os.DirFS(filepath.Join(projectRoot, base)), glob, func(match string, d fs.DirEntry) error {
 			if !d.IsDir() {
-				matches = append(matches, path)
+				matches = append(matches, path.Join(base, match))
 			}
 			return nil
 		})
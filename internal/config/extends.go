@@ -0,0 +1,386 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteFetcher fetches files from other repositories on the git host.
+// It is implemented by the git hosting providers.
+type RemoteFetcher interface {
+	// GetRepositoryFile returns the content of a file in a repository at the
+	// given ref. An empty ref means the default branch.
+	GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error)
+}
+
+// Extends lists the configurations a configuration file inherits from. It can
+// be written as a single string or as a list.
+type Extends []string
+
+// UnmarshalYAML accepts both a single string and a list of strings
+func (e *Extends) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*e = Extends{node.Value}
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*e = list
+	return nil
+}
+
+// RemoteRef identifies a configuration file in another repository, written as
+// owner/repo:path/to/file.yaml@ref (the @ref part is optional)
+type RemoteRef struct {
+	Repository string
+	Path       string
+	Ref        string
+}
+
+// String returns the reference in its owner/repo:path@ref form
+func (r RemoteRef) String() string {
+	if r.Ref == "" {
+		return r.Repository + ":" + r.Path
+	}
+	return r.Repository + ":" + r.Path + "@" + r.Ref
+}
+
+// ParseRemoteRef parses an extends entry pointing to another repository.
+// Returns false if the entry is a local path.
+func ParseRemoteRef(s string) (RemoteRef, bool) {
+	idx := strings.Index(s, ":")
+	if idx <= 0 {
+		return RemoteRef{}, false
+	}
+
+	repository := s[:idx]
+	if !strings.Contains(repository, "/") || strings.HasPrefix(repository, ".") || strings.HasPrefix(repository, "/") {
+		return RemoteRef{}, false
+	}
+
+	ref := RemoteRef{Repository: repository, Path: s[idx+1:]}
+	if at := strings.LastIndex(ref.Path, "@"); at >= 0 {
+		ref.Ref = ref.Path[at+1:]
+		ref.Path = ref.Path[:at]
+	}
+	ref.Path = strings.TrimPrefix(ref.Path, "/")
+	if ref.Path == "" {
+		return RemoteRef{}, false
+	}
+
+	return ref, true
+}
+
+// loader reads configuration files and resolves their extends chain
+type loader struct {
+	ctx     context.Context
+	fetcher RemoteFetcher
+}
+
+// loadFile reads a local configuration file and resolves its extends.
+// stack holds the files being loaded, used to detect cycles.
+func (l *loader) loadFile(filePath string, stack []string) (*Config, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.resolve(data, absPath, filePath, stack, func(entry string) (*Config, error) {
+		if ref, ok := ParseRemoteRef(entry); ok {
+			return l.loadRemote(ref, push(stack, absPath))
+		}
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(filepath.Dir(absPath), entry)
+		}
+		parent, err := l.loadFile(entry, push(stack, absPath))
+		if err != nil {
+			return nil, err
+		}
+		return parent.rebase(filepath.Dir(entry), filepath.Dir(absPath)), nil
+	})
+}
+
+// loadRemote fetches a configuration file from another repository and resolves
+// its extends. Relative local paths in a remote file refer to the same repository.
+func (l *loader) loadRemote(ref RemoteRef, stack []string) (*Config, error) {
+	if l.fetcher == nil {
+		return nil, fmt.Errorf("cannot extend %s: remote configurations are only available in CI", ref)
+	}
+
+	id := ref.String()
+	content, err := l.fetcher.GetRepositoryFile(l.ctx, ref.Repository, ref.Path, ref.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", id, err)
+	}
+
+	cfg, err := l.resolve([]byte(content), id, id, stack, func(entry string) (*Config, error) {
+		if remote, ok := ParseRemoteRef(entry); ok {
+			return l.loadRemote(remote, push(stack, id))
+		}
+		return l.loadRemote(ref.sibling(entry), push(stack, id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.fetchFiles(cfg, ref); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// sibling returns the reference to a path relative to the directory of r, in
+// the same repository and at the same ref
+func (r RemoteRef) sibling(p string) RemoteRef {
+	if !path.IsAbs(p) {
+		p = path.Join(path.Dir(r.Path), p)
+	}
+	return RemoteRef{Repository: r.Repository, Path: strings.TrimPrefix(p, "/"), Ref: r.Ref}
+}
+
+// fetchFiles fetches the prompt templates and context files of a configuration
+// extended from the remote file ref, as they can't be read from the working
+// tree. Their paths are replaced by remote references, and their content is
+// kept in the configuration. Files of configurations ref extends are already
+// fetched and skipped.
+func (l *loader) fetchFiles(cfg *Config, ref RemoteRef) error {
+	fetch := func(remote *map[string]string, p *string) error {
+		if _, ok := (*remote)[*p]; ok || *p == "" {
+			return nil
+		}
+		if strings.ContainsAny(*p, "*?[]") {
+			return fmt.Errorf("%s: glob patterns can't be used in configurations of other repositories: %s", ref, *p)
+		}
+
+		file := ref.sibling(*p)
+		content, err := l.fetcher.GetRepositoryFile(l.ctx, file.Repository, file.Path, file.Ref)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", file, err)
+		}
+
+		if *remote == nil {
+			*remote = make(map[string]string)
+		}
+		(*remote)[file.String()] = content
+		*p = file.String()
+		return nil
+	}
+
+	for _, p := range []*string{&cfg.Prompts.System, &cfg.Prompts.User} {
+		if err := fetch(&cfg.Prompts.remote, p); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.Context.Include {
+		if err := fetch(&cfg.Context.remote, &cfg.Context.Include[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rebase returns a copy of a configuration read from the directory from, with
+// the relative paths of its prompt templates and context files rewritten
+// relative to the directory to. Files fetched from other repositories are kept.
+func (c *Config) rebase(from, to string) *Config {
+	if from == to {
+		return c
+	}
+
+	rebased := *c
+	move := func(remote map[string]string, p string) string {
+		if _, ok := remote[p]; ok || p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		rel, err := filepath.Rel(to, filepath.Join(from, p))
+		if err != nil {
+			return filepath.Join(from, p)
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	rebased.Prompts.System = move(c.Prompts.remote, c.Prompts.System)
+	rebased.Prompts.User = move(c.Prompts.remote, c.Prompts.User)
+
+	rebased.Context.Include = make([]string, len(c.Context.Include))
+	for i, p := range c.Context.Include {
+		rebased.Context.Include[i] = move(c.Context.remote, p)
+	}
+
+	return &rebased
+}
+
+// resolve decodes a configuration file identified by id and merges it over the
+// configurations it extends, loaded in order with load. name is used in errors.
+func (l *loader) resolve(data []byte, id, name string, stack []string, load func(entry string) (*Config, error)) (*Config, error) {
+	for _, seen := range stack {
+		if seen == id {
+			return nil, fmt.Errorf("extends cycle detected: %s", strings.Join(append(stack, id), " -> "))
+		}
+	}
+
+	cfg, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if len(cfg.Extends) == 0 {
+		return cfg, nil
+	}
+
+	var base *Config
+	for _, entry := range cfg.Extends {
+		parent, err := load(entry)
+		if err != nil {
+			return nil, err
+		}
+		if base == nil {
+			base = parent
+		} else {
+			base = base.override(parent)
+		}
+	}
+
+	merged := base.override(cfg)
+	merged.Extends = cfg.Extends
+	return merged, nil
+}
+
+// override returns a new configuration where local takes precedence over c.
+// Lists are concatenated (base entries first), rules with the same ID are
+// replaced by the local rule, and scalar settings are overridden when set.
+func (c *Config) override(local *Config) *Config {
+	merged := &Config{
+		Ignore: IgnoreConfig{
+			Paths: appendUnique(c.Ignore.Paths, local.Ignore.Paths),
+		},
+		Context: ContextConfig{
+			Include: appendUnique(c.Context.Include, local.Context.Include),
+			Exclude: appendUnique(c.Context.Exclude, local.Context.Exclude),
+			remote:  mergeFiles(c.Context.remote, local.Context.remote),
+		},
+		Prompts:  c.Prompts,
+		Cache:    c.Cache,
+		Comments: c.Comments,
+		Checks:   c.Checks,
+		Status:   c.Status,
+	}
+
+	for _, rule := range c.Rules {
+		if FindRule(local.Rules, rule.ID) == nil {
+			merged.Rules = append(merged.Rules, rule)
+		}
+	}
+	merged.Rules = append(merged.Rules, local.Rules...)
+
+	merged.Prompts.remote = mergeFiles(c.Prompts.remote, local.Prompts.remote)
+	if local.Prompts.System != "" {
+		merged.Prompts.System = local.Prompts.System
+	}
+	if local.Prompts.User != "" {
+		merged.Prompts.User = local.Prompts.User
+	}
+	if local.Cache.Invalidate != "" {
+		merged.Cache.Invalidate = local.Cache.Invalidate
+	}
+	if local.Cache.Hash != "" {
+		merged.Cache.Hash = local.Cache.Hash
+	}
+	if local.Comments.Outdated != "" {
+		merged.Comments.Outdated = local.Comments.Outdated
+	}
+	if local.Comments.Duplicates != "" {
+		merged.Comments.Duplicates = local.Comments.Duplicates
+	}
+	if local.Checks.Enabled != nil {
+		merged.Checks.Enabled = local.Checks.Enabled
+	}
+	if local.Checks.Comments != nil {
+		merged.Checks.Comments = local.Checks.Comments
+	}
+	if local.Checks.FailOn != "" {
+		merged.Checks.FailOn = local.Checks.FailOn
+	}
+	if local.Status.FailOn != "" {
+		merged.Status.FailOn = local.Status.FailOn
+	}
+
+	merged.Languages = mergeLanguages(c.Languages, local.Languages)
+
+	return merged
+}
+
+// mergeLanguages merges language settings: child settings override
+// builtin_rules when set and append extra_rules
+func mergeLanguages(parent, child map[string]LanguageConfig) map[string]LanguageConfig {
+	if len(parent) == 0 && len(child) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]LanguageConfig, len(parent)+len(child))
+	for name, lang := range parent {
+		merged[strings.ToLower(name)] = lang
+	}
+	for name, lang := range child {
+		key := strings.ToLower(name)
+		base := merged[key]
+		if lang.BuiltinRules != nil {
+			base.BuiltinRules = lang.BuiltinRules
+		}
+		base.ExtraRules = append(append([]string(nil), base.ExtraRules...), lang.ExtraRules...)
+		merged[key] = base
+	}
+
+	return merged
+}
+
+// push returns a copy of stack with id appended
+func push(stack []string, id string) []string {
+	return append(append([]string(nil), stack...), id)
+}
+
+// appendUnique concatenates two lists, skipping entries already present
+func appendUnique(base, extra []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(base)+len(extra))
+	for _, list := range [][]string{base, extra} {
+		for _, item := range list {
+			if !seen[item] {
+				seen[item] = true
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// mergeFiles returns a map holding the files of both maps
+func mergeFiles(base, extra map[string]string) map[string]string {
+	if len(base) == 0 && len(extra) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(base)+len(extra))
+	for id, content := range base {
+		merged[id] = content
+	}
+	for id, content := range extra {
+		merged[id] = content
+	}
+	return merged
+}
@@ -0,0 +1,361 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeFetcher serves remote configuration files from memory
+type fakeFetcher struct {
+	files    map[string]string // "repository:path@ref" -> content
+	requests []string
+}
+
+func (f *fakeFetcher) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	key := repository + ":" + path + "@" + ref
+	f.requests = append(f.requests, key)
+	content, ok := f.files[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func TestParseRemoteRef(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RemoteRef
+		remote   bool
+	}{
+		{"org/ainspector-config:base.yaml@main", RemoteRef{"org/ainspector-config", "base.yaml", "main"}, true},
+		{"org/ainspector-config:configs/go.yaml", RemoteRef{"org/ainspector-config", "configs/go.yaml", ""}, true},
+		{"group/sub/project:base.yaml@v1.2.0", RemoteRef{"group/sub/project", "base.yaml", "v1.2.0"}, true},
+		{"../shared/base.yaml", RemoteRef{}, false},
+		{"base.yaml", RemoteRef{}, false},
+		{"/etc/ainspector/base.yaml", RemoteRef{}, false},
+		{`C:\configs\base.yaml`, RemoteRef{}, false},
+		{"org/repo:", RemoteRef{}, false},
+	}
+
+	for _, tt := range tests {
+		ref, ok := ParseRemoteRef(tt.input)
+		if ok != tt.remote {
+			t.Errorf("ParseRemoteRef(%q) remote = %v, want %v", tt.input, ok, tt.remote)
+			continue
+		}
+		if ref != tt.expected {
+			t.Errorf("ParseRemoteRef(%q) = %+v, want %+v", tt.input, ref, tt.expected)
+		}
+	}
+}
+
+func TestLoadFromPath_ExtendsLocal(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "shared/base.yaml", `ignore:
+  paths:
+    - vendor/
+rules:
+  - id: errors
+    text: Errors must be wrapped
+    severity: critical
+  - Base rule
+languages:
+  javascript:
+    builtin_rules: false
+    extra_rules: [base js rule]
+`)
+	createTestFile(t, tmpDir, "repo/ainspector.yaml", `extends: ../shared/base.yaml
+ignore:
+  paths:
+    - vendor/
+    - dist/
+rules:
+  - id: errors
+    text: Errors must be wrapped with %w
+    severity: warning
+  - Local rule
+languages:
+  javascript:
+    extra_rules: [local js rule]
+`)
+
+	cfg, err := LoadFromPath(filepath.Join(tmpDir, "repo", "ainspector.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(cfg.Ignore.Paths, ",") != "vendor/,dist/" {
+		t.Errorf("unexpected ignore paths: %v", cfg.Ignore.Paths)
+	}
+
+	var texts []string
+	for _, r := range cfg.Rules {
+		texts = append(texts, r.Text)
+	}
+	if strings.Join(texts, "|") != "Base rule|Errors must be wrapped with %w|Local rule" {
+		t.Errorf("unexpected rules: %v", texts)
+	}
+	if r := FindRule(cfg.Rules, "errors"); r == nil || r.Severity != SeverityWarning {
+		t.Errorf("local rule should override base rule with the same id, got %+v", r)
+	}
+
+	js := cfg.Language("javascript")
+	if js.BuiltinRulesEnabled() {
+		t.Error("builtin_rules from base should be kept when not overridden")
+	}
+	if strings.Join(js.ExtraRules, "|") != "base js rule|local js rule" {
+		t.Errorf("unexpected extra rules: %v", js.ExtraRules)
+	}
+}
+
+func TestLoadFromPath_ExtendsRebasesPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "shared/base.yaml", `prompts:
+  system: prompts/system.tmpl
+context:
+  include:
+    - docs/guide.md
+    - docs/*.txt
+`)
+	createTestFile(t, tmpDir, "shared/prompts/system.tmpl", "Shared system prompt")
+	createTestFile(t, tmpDir, "shared/docs/guide.md", "Shared guide")
+	createTestFile(t, tmpDir, "shared/docs/notes.txt", "Shared notes")
+	createTestFile(t, tmpDir, "repo/ainspector.yaml", "extends: ../shared/base.yaml\ncontext:\n  include: [README.md]\n")
+	createTestFile(t, tmpDir, "repo/README.md", "Local readme")
+
+	repoDir := filepath.Join(tmpDir, "repo")
+	cfg, err := LoadFromPath(filepath.Join(repoDir, "ainspector.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Prompts.System != "../shared/prompts/system.tmpl" {
+		t.Errorf("expected the prompt template to be relative to the repository, got %q", cfg.Prompts.System)
+	}
+	if content, err := cfg.Prompts.ReadTemplate(filepath.Join(repoDir, cfg.Prompts.System)); err != nil || content != "Shared system prompt" {
+		t.Errorf("unexpected template %q (error: %v)", content, err)
+	}
+
+	files, warnings, err := cfg.Context.CollectContextFiles(repoDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	expected := map[string]string{
+		"../shared/docs/guide.md":  "Shared guide",
+		"../shared/docs/notes.txt": "Shared notes",
+		"README.md":                "Local readme",
+	}
+	if len(files) != len(expected) {
+		t.Errorf("unexpected context files: %v", files)
+	}
+	for path, content := range expected {
+		if files[path] != content {
+			t.Errorf("expected %s to contain %q, got %q", path, content, files[path])
+		}
+	}
+}
+
+func TestLoadFromPath_ExtendsMultiple(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "a.yaml", "rules: [A]\nprompts:\n  system: a.tmpl\ncache:\n  invalidate: any\n")
+	createTestFile(t, tmpDir, "b.yaml", "cache:\n  hash: tokens\nrules: [B]\nprompts:\n  system: b.tmpl\n  user: b-user.tmpl\n")
+	createTestFile(t, tmpDir, "ainspector.yaml", "extends:\n  - a.yaml\n  - b.yaml\nrules: [C]\nprompts:\n  user: local-user.tmpl\n")
+
+	cfg, err := LoadFromPath(filepath.Join(tmpDir, "ainspector.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.Rules) != 3 || cfg.Rules[0].Text != "A" || cfg.Rules[1].Text != "B" || cfg.Rules[2].Text != "C" {
+		t.Errorf("expected rules in extends order, got %+v", cfg.Rules)
+	}
+	if cfg.Prompts.System != "b.tmpl" {
+		t.Errorf("later extends should override earlier ones, got %q", cfg.Prompts.System)
+	}
+	if cfg.Prompts.User != "local-user.tmpl" {
+		t.Errorf("local settings should override extends, got %q", cfg.Prompts.User)
+	}
+	if cfg.Cache.InvalidatePolicy() != InvalidateAny {
+		t.Errorf("expected cache settings to be inherited, got %q", cfg.Cache.Invalidate)
+	}
+	if cfg.Cache.HashMode() != HashTokens {
+		t.Errorf("expected cache settings to be merged, got %q", cfg.Cache.Hash)
+	}
+}
+
+func TestLoadFromPath_ExtendsMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "ainspector.yaml", "extends: missing.yaml\n")
+
+	_, err := LoadFromPath(filepath.Join(tmpDir, "ainspector.yaml"))
+	if err == nil {
+		t.Fatal("expected error for missing extended file")
+	}
+}
+
+func TestLoadFromPath_ExtendsCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "a.yaml", "extends: b.yaml\n")
+	createTestFile(t, tmpDir, "b.yaml", "extends: a.yaml\n")
+
+	_, err := LoadFromPath(filepath.Join(tmpDir, "a.yaml"))
+	if err == nil {
+		t.Fatal("expected error for extends cycle")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected cycle error, got: %v", err)
+	}
+}
+
+func TestLoadFromPath_ExtendsRemoteWithoutFetcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "ainspector.yaml", "extends: org/ainspector-config:base.yaml@main\n")
+
+	_, err := LoadFromPath(filepath.Join(tmpDir, "ainspector.yaml"))
+	if err == nil {
+		t.Fatal("expected error for remote extends without fetcher")
+	}
+}
+
+func TestLoadWithFetcher_ExtendsRemote(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("extends: org/ainspector-config:configs/service.yaml@main\nrules: [Local rule]\n"), 0644)
+
+	fetcher := &fakeFetcher{files: map[string]string{
+		"org/ainspector-config:configs/service.yaml@main": "extends: base.yaml\nrules: [Service rule]\n",
+		"org/ainspector-config:configs/base.yaml@main":    "extends: other/shared:common.yaml\nignore:\n  paths: [vendor/]\nrules: [Base rule]\n",
+		"other/shared:common.yaml@":                       "rules: [Common rule]\n",
+	}}
+
+	cfg, err := LoadWithFetcher(context.Background(), fetcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var texts []string
+	for _, r := range cfg.Rules {
+		texts = append(texts, r.Text)
+	}
+	if strings.Join(texts, "|") != "Common rule|Base rule|Service rule|Local rule" {
+		t.Errorf("unexpected rules: %v", texts)
+	}
+	if len(cfg.Ignore.Paths) != 1 || cfg.Ignore.Paths[0] != "vendor/" {
+		t.Errorf("unexpected ignore paths: %v", cfg.Ignore.Paths)
+	}
+	if len(fetcher.requests) != 3 {
+		t.Errorf("expected 3 remote requests, got %v", fetcher.requests)
+	}
+}
+
+func TestLoadWithFetcher_ExtendsRemoteFiles(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("extends: org/ainspector-config:configs/service.yaml@main\n"), 0644)
+
+	fetcher := &fakeFetcher{files: map[string]string{
+		"org/ainspector-config:configs/service.yaml@main":      "extends: base.yaml\nprompts:\n  user: prompts/user.tmpl\n",
+		"org/ainspector-config:configs/base.yaml@main":         "prompts:\n  system: /prompts/system.tmpl\ncontext:\n  include: [docs/guide.md]\n",
+		"org/ainspector-config:prompts/system.tmpl@main":       "Remote system prompt",
+		"org/ainspector-config:configs/prompts/user.tmpl@main": "Remote user prompt",
+		"org/ainspector-config:configs/docs/guide.md@main":     "Remote guide",
+	}}
+
+	cfg, err := LoadWithFetcher(context.Background(), fetcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	templates := map[string]string{
+		cfg.Prompts.System: "Remote system prompt",
+		cfg.Prompts.User:   "Remote user prompt",
+	}
+	for path, expected := range templates {
+		if content, err := cfg.Prompts.ReadTemplate(path); err != nil || content != expected {
+			t.Errorf("expected template %s to be %q, got %q (error: %v)", path, expected, content, err)
+		}
+	}
+
+	files, _, err := cfg.Context.CollectContextFiles(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files["org/ainspector-config:configs/docs/guide.md@main"] != "Remote guide" {
+		t.Errorf("unexpected context files: %v", files)
+	}
+}
+
+func TestLoadWithFetcher_ExtendsRemoteGlob(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("extends: org/ainspector-config:base.yaml\n"), 0644)
+
+	fetcher := &fakeFetcher{files: map[string]string{
+		"org/ainspector-config:base.yaml@": "context:\n  include: [docs/*.md]\n",
+	}}
+
+	_, err := LoadWithFetcher(context.Background(), fetcher)
+	if err == nil || !strings.Contains(err.Error(), "docs/*.md") {
+		t.Errorf("expected an error about the glob pattern, got %v", err)
+	}
+}
+
+func TestLoadWithFetcher_RemoteCycle(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("extends: org/config:a.yaml\n"), 0644)
+
+	fetcher := &fakeFetcher{files: map[string]string{
+		"org/config:a.yaml@": "extends: b.yaml\n",
+		"org/config:b.yaml@": "extends: org/config:a.yaml\n",
+	}}
+
+	_, err := LoadWithFetcher(context.Background(), fetcher)
+	if err == nil {
+		t.Fatal("expected error for remote extends cycle")
+	}
+	if !strings.Contains(err.Error(), "org/config:a.yaml -> org/config:b.yaml -> org/config:a.yaml") {
+		t.Errorf("expected cycle path in error, got: %v", err)
+	}
+}
+
+func TestLoadWithFetcher_RemoteError(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("extends: org/config:missing.yaml\n"), 0644)
+
+	_, err := LoadWithFetcher(context.Background(), &fakeFetcher{})
+	if err == nil {
+		t.Fatal("expected error when the remote file can't be fetched")
+	}
+	if !strings.Contains(err.Error(), "org/config:missing.yaml") {
+		t.Errorf("error should mention the remote file, got: %v", err)
+	}
+}
@@ -8,41 +8,49 @@ import (
 
 // ShouldIgnore checks if a file path should be ignored based on the configuration
 func (c *Config) ShouldIgnore(path string) bool {
-	// Normalize path separators
-	normalizedPath := strings.ReplaceAll(path, "\\", "/")
-
-	// Check glob patterns
-	for _, pattern := range c.Ignore.Paths {
-		normalizedPattern := strings.ReplaceAll(pattern, "\\", "/")
-
-		// Handle directory patterns (ending with /)
-		if strings.HasSuffix(normalizedPattern, "/") {
-			dirPattern := strings.TrimSuffix(normalizedPattern, "/")
-			// Check if path starts with directory or is inside directory
-			if strings.HasPrefix(normalizedPath, dirPattern+"/") || normalizedPath == dirPattern {
-				return true
-			}
-			// Also match with ** for nested paths
-			if matched, _ := doublestar.Match(dirPattern+"/**", normalizedPath); matched {
-				return true
-			}
-			continue
-		}
+	return matchAny(c.Ignore.Paths, path)
+}
 
-		// Standard glob matching with doublestar support
-		if matched, _ := doublestar.Match(normalizedPattern, normalizedPath); matched {
+// matchAny checks if a path matches any of the given patterns
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, path) {
 			return true
 		}
+	}
+	return false
+}
 
-		// Also check if pattern matches the basename
-		basename := normalizedPath
-		if idx := strings.LastIndex(normalizedPath, "/"); idx >= 0 {
-			basename = normalizedPath[idx+1:]
-		}
-		if matched, _ := doublestar.Match(normalizedPattern, basename); matched {
+// matchPattern checks if a path matches a single pattern. Patterns ending with /
+// match everything under that directory, other patterns are matched as globs
+// against the full path and against the basename.
+func matchPattern(pattern, path string) bool {
+	// Normalize path separators
+	normalizedPath := strings.ReplaceAll(path, "\\", "/")
+	normalizedPattern := strings.ReplaceAll(pattern, "\\", "/")
+
+	// Handle directory patterns (ending with /)
+	if strings.HasSuffix(normalizedPattern, "/") {
+		dirPattern := strings.TrimSuffix(normalizedPattern, "/")
+		// Check if path starts with directory or is inside directory
+		if strings.HasPrefix(normalizedPath, dirPattern+"/") || normalizedPath == dirPattern {
 			return true
 		}
+		// Also match with ** for nested paths
+		matched, _ := doublestar.Match(dirPattern+"/**", normalizedPath)
+		return matched
 	}
 
-	return false
+	// Standard glob matching with doublestar support
+	if matched, _ := doublestar.Match(normalizedPattern, normalizedPath); matched {
+		return true
+	}
+
+	// Also check if pattern matches the basename
+	basename := normalizedPath
+	if idx := strings.LastIndex(normalizedPath, "/"); idx >= 0 {
+		basename = normalizedPath[idx+1:]
+	}
+	matched, _ := doublestar.Match(normalizedPattern, basename)
+	return matched
 }
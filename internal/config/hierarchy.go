@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// skippedDirs lists directories never searched for nested configuration files
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// loadNested walks the tree under root and loads every nested configuration
+// file, keyed by its directory relative to root (using forward slashes).
+// Hidden directories, dependency directories and ignored paths are skipped.
+// Nested files setting repository-wide sections are rejected.
+func (c *Config) loadNested(l *loader, root string) error {
+	c.nested = make(map[string]*Config)
+	c.merged = make(map[string]*Config)
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] || c.ShouldIgnore(rel+"/") {
+			return filepath.SkipDir
+		}
+
+		for _, filename := range configFileNames {
+			filePath := filepath.Join(p, filename)
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			if err := validateNested(data); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			nested, err := l.loadFile(filePath, nil)
+			if err != nil {
+				return err
+			}
+			c.nested[rel] = nested
+			break
+		}
+
+		return nil
+	})
+}
+
+// NestedDirs returns the directories containing a nested configuration file,
+// relative to the repository root and sorted alphabetically
+func (c *Config) NestedDirs() []string {
+	dirs := make([]string, 0, len(c.nested))
+	for dir := range c.nested {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// ForPath returns the effective configuration for a file: the root
+// configuration merged with the nested configuration files of every directory
+// between the root and the file. Returns the configuration itself when no
+// nested configuration applies.
+func (c *Config) ForPath(filePath string) *Config {
+	if len(c.nested) == 0 {
+		return c
+	}
+	return c.ForDir(path.Dir(strings.ReplaceAll(filePath, "\\", "/")))
+}
+
+// ForDir returns the effective configuration for a directory relative to the
+// repository root, caching the result
+func (c *Config) ForDir(dir string) *Config {
+	if dir == "." || dir == "/" || dir == "" {
+		return c
+	}
+	if merged, ok := c.merged[dir]; ok {
+		return merged
+	}
+
+	effective := c.ForDir(path.Dir(dir))
+	if nested, ok := c.nested[dir]; ok {
+		effective = effective.merge(dir, nested)
+	}
+
+	c.merged[dir] = effective
+	return effective
+}
+
+// merge returns a new configuration combining c with the nested configuration
+// found in dir. Paths of the nested configuration are relative to dir: ignore
+// patterns and context files are rebased, and nested rules only apply to files
+// under dir. Prompt templates, the cache, the comments, the checks and the
+// status settings are repository-wide and rejected in nested files.
+func (c *Config) merge(dir string, nested *Config) *Config {
+	merged := &Config{
+		Ignore: IgnoreConfig{
+			Paths: appendRebased(c.Ignore.Paths, dir, nested.Ignore.Paths),
+		},
+		Context: ContextConfig{
+			Include: appendJoined(c.Context.Include, dir, nested.Context.Include, nested.Context.remote),
+			Exclude: appendJoined(c.Context.Exclude, dir, nested.Context.Exclude, nil),
+			remote:  mergeFiles(c.Context.remote, nested.Context.remote),
+		},
+		Rules:    append([]Rule(nil), c.Rules...),
+		Prompts:  c.Prompts,
+		Cache:    c.Cache,
+		Comments: c.Comments,
+		Checks:   c.Checks,
+		Status:   c.Status,
+	}
+
+	for _, rule := range nested.Rules {
+		if len(rule.Paths) == 0 {
+			rule.Paths = []string{dir + "/"}
+		} else {
+			rule.Paths = appendRebased(nil, dir, rule.Paths)
+		}
+		merged.Rules = append(merged.Rules, rule)
+	}
+
+	merged.Languages = mergeLanguages(c.Languages, nested.Languages)
+
+	return merged
+}
+
+// appendRebased appends path patterns relative to dir, rebased to the root.
+// Patterns without a slash match basenames anywhere below dir.
+func appendRebased(base []string, dir string, patterns []string) []string {
+	result := append([]string(nil), base...)
+	for _, pattern := range patterns {
+		result = append(result, rebasePattern(dir, pattern))
+	}
+	return result
+}
+
+// rebasePattern rewrites a pattern relative to dir into a pattern relative to the root
+func rebasePattern(dir, pattern string) string {
+	pattern = strings.TrimPrefix(strings.ReplaceAll(pattern, "\\", "/"), "./")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(trimmed, "/") && !strings.HasSuffix(pattern, "/") {
+		return dir + "/**/" + pattern
+	}
+	return dir + "/" + pattern
+}
+
+// appendJoined appends paths relative to dir, joined with dir. Files fetched
+// from other repositories, found in remote, are appended as is.
+func appendJoined(base []string, dir string, paths []string, remote map[string]string) []string {
+	result := append([]string(nil), base...)
+	for _, p := range paths {
+		if _, ok := remote[p]; ok {
+			result = append(result, p)
+			continue
+		}
+		result = append(result, path.Join(dir, strings.ReplaceAll(p, "\\", "/")))
+	}
+	return result
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoad_NestedConfigs(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	createTestFile(t, tmpDir, "ainspector.yaml", `ignore:
+  paths:
+    - vendor/
+rules:
+  - Global rule
+context:
+  include:
+    - README.md
+languages:
+  go:
+    extra_rules:
+      - root go rule
+`)
+	createTestFile(t, tmpDir, "services/api/ainspector.yaml", `ignore:
+  paths:
+    - generated/
+    - "*.pb.go"
+rules:
+  - id: api-errors
+    text: API handlers must return typed errors
+  - id: api-db
+    text: Use the query builder
+    paths:
+      - db/**
+context:
+  include:
+    - docs/api.md
+languages:
+  go:
+    builtin_rules: false
+    extra_rules:
+      - api go rule
+`)
+	createTestFile(t, tmpDir, "services/api/v2/ainspector.yml", `rules:
+  - v2 rule
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dirs := cfg.NestedDirs()
+	if len(dirs) != 2 || dirs[0] != "services/api" || dirs[1] != "services/api/v2" {
+		t.Fatalf("unexpected nested dirs: %v", dirs)
+	}
+
+	t.Run("root files use the root config", func(t *testing.T) {
+		if cfg.ForPath("main.go") != cfg {
+			t.Error("expected root config for a root file")
+		}
+		if cfg.ForPath("services/web/app.go") != cfg {
+			t.Error("expected root config for a directory without nested config")
+		}
+	})
+
+	t.Run("ignore paths are relative to the nested file", func(t *testing.T) {
+		api := cfg.ForPath("services/api/handler.go")
+		ignored := map[string]bool{
+			"vendor/pkg/a.go":                      true,
+			"services/api/generated/models.go":     true,
+			"services/api/proto/user.pb.go":        true,
+			"services/api/v2/user.pb.go":           true,
+			"services/api/handler.go":              false,
+			"services/web/generated/x.go":          false,
+			"services/api/internal/generated/y.go": false,
+		}
+		for path, want := range ignored {
+			if got := cfg.ForPath(path).ShouldIgnore(path); got != want {
+				t.Errorf("ShouldIgnore(%q) = %v, want %v", path, got, want)
+			}
+		}
+		if api.ShouldIgnore("services/web/user.pb.go") {
+			t.Error("nested basename patterns should only apply to the nested subtree")
+		}
+	})
+
+	t.Run("rules are appended and scoped to the subtree", func(t *testing.T) {
+		rules := cfg.ForPath("services/api/db/query.go").RulesFor("services/api/db/query.go", "go")
+		if len(rules) != 3 {
+			t.Fatalf("expected 3 rules, got %+v", rules)
+		}
+		if rules[0].Text != "Global rule" || rules[1].ID != "api-errors" || rules[2].ID != "api-db" {
+			t.Errorf("unexpected rules: %+v", rules)
+		}
+
+		rules = cfg.ForPath("services/api/handler.go").RulesFor("services/api/handler.go", "go")
+		if len(rules) != 2 {
+			t.Errorf("api-db rule should not apply outside db/, got %+v", rules)
+		}
+
+		rules = cfg.ForPath("services/api/v2/handler.go").RulesFor("services/api/v2/handler.go", "go")
+		if len(rules) != 3 || rules[2].Text != "v2 rule" {
+			t.Errorf("expected rules from every level, got %+v", rules)
+		}
+
+		if len(cfg.RulesFor("services/web/app.go", "go")) != 1 {
+			t.Error("nested rules should not leak to other directories")
+		}
+	})
+
+	t.Run("context files are resolved relative to the nested file", func(t *testing.T) {
+		include := cfg.ForPath("services/api/handler.go").Context.Include
+		if len(include) != 2 || include[0] != "README.md" || include[1] != "services/api/docs/api.md" {
+			t.Errorf("unexpected context include: %v", include)
+		}
+	})
+
+	t.Run("language checklists are merged", func(t *testing.T) {
+		lang := cfg.ForPath("services/api/handler.go").Language("go")
+		if lang.BuiltinRulesEnabled() {
+			t.Error("expected nested config to disable built-in rules")
+		}
+		if len(lang.ExtraRules) != 2 || lang.ExtraRules[0] != "root go rule" || lang.ExtraRules[1] != "api go rule" {
+			t.Errorf("unexpected extra rules: %v", lang.ExtraRules)
+		}
+		if len(cfg.Language("go").ExtraRules) != 1 {
+			t.Error("merging should not modify the root config")
+		}
+	})
+}
+
+func TestLoad_NestedConfigsSkippedDirs(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	createTestFile(t, tmpDir, "ainspector.yaml", "ignore:\n  paths:\n    - third_party/\n")
+	createTestFile(t, tmpDir, "node_modules/pkg/ainspector.yaml", "rules: [from node_modules]\n")
+	createTestFile(t, tmpDir, ".github/ainspector.yaml", "rules: [from hidden dir]\n")
+	createTestFile(t, tmpDir, "third_party/lib/ainspector.yaml", "rules: [from ignored dir]\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dirs := cfg.NestedDirs(); len(dirs) != 0 {
+		t.Errorf("expected no nested configs, got %v", dirs)
+	}
+}
+
+func TestLoad_InvalidNestedConfig(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	createTestFile(t, tmpDir, "pkg/ainspector.yaml", "rules: [unterminated\n")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid nested config")
+	}
+	if !strings.Contains(err.Error(), "pkg/ainspector.yaml") {
+		t.Errorf("error should mention the nested file, got: %v", err)
+	}
+}
+
+func TestLoad_NestedRepositoryWideFields(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	createTestFile(t, tmpDir, "ainspector.yaml", "checks:\n  enabled: true\n")
+	createTestFile(t, tmpDir, "pkg/ainspector.yaml", "rules:\n  - pkg rule\ncache:\n  invalidate: never\nchecks:\n  fail_on: info\n")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for repository-wide fields in a nested config")
+	}
+	for _, want := range []string{"pkg/ainspector.yaml", "line 3: cache can only be set in the root configuration file", "line 5: checks"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestRebasePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"generated/", "svc/generated/"},
+		{"*.pb.go", "svc/**/*.pb.go"},
+		{"**/*_test.go", "svc/**/*_test.go"},
+		{"internal/db/**", "svc/internal/db/**"},
+		{"./docs/", "svc/docs/"},
+	}
+
+	for _, tt := range tests {
+		if got := rebasePattern("svc", tt.pattern); got != tt.expected {
+			t.Errorf("rebasePattern(%q) = %q, want %q", tt.pattern, got, tt.expected)
+		}
+	}
+}
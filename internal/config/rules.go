@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity levels for findings and rules
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// DefaultSeverity is the severity assigned to rules that don't declare one
+const DefaultSeverity = SeverityWarning
+
+// Rule is a custom review rule, optionally scoped to some paths or languages.
+//
+// A rule can be written in ainspector.yaml either as a plain string, which
+// applies to every file, or as a mapping:
+//
+//   - id: sql-prepared
+//     text: SQL must use prepared statements
+//     paths: ["internal/db/**"]
+//     languages: [go]
+//     severity: critical
+type Rule struct {
+	// ID identifies the rule in review comments and suppressions
+	ID string `yaml:"id,omitempty"`
+	// Text is the rule enforced by the AI reviewer
+	Text string `yaml:"text,omitempty"`
+	// Paths restricts the rule to files matching these glob patterns
+	Paths []string `yaml:"paths,omitempty"`
+	// Languages restricts the rule to these languages (e.g. go, typescript)
+	Languages []string `yaml:"languages,omitempty"`
+	// Severity is the default severity of violations (critical, warning, info)
+	Severity string `yaml:"severity,omitempty"`
+}
+
+// UnmarshalYAML accepts both the plain string and the mapping form of a rule
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Text = node.Value
+		r.Severity = DefaultSeverity
+		return nil
+	}
+
+	if err := checkFields(node, "rule", ruleFields); err != nil {
+		return err
+	}
+
+	type rawRule Rule
+	var raw rawRule
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	*r = Rule(raw)
+	if r.Text == "" {
+		return fmt.Errorf("line %d: rule must have a text", node.Line)
+	}
+	if r.Severity == "" {
+		r.Severity = DefaultSeverity
+	}
+	if !IsValidSeverity(r.Severity) {
+		return fmt.Errorf("line %d: invalid severity %q for rule %q (expected critical, warning or info)", node.Line, r.Severity, r.Text)
+	}
+
+	return nil
+}
+
+// String returns the rule text
+func (r Rule) String() string {
+	return r.Text
+}
+
+// AppliesTo reports whether the rule applies to a file with the given path and language
+func (r Rule) AppliesTo(path, language string) bool {
+	if len(r.Languages) > 0 {
+		matched := false
+		for _, lang := range r.Languages {
+			if strings.EqualFold(lang, language) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.Paths) > 0 {
+		return matchAny(r.Paths, path)
+	}
+
+	return true
+}
+
+// RulesFor returns the rules that apply to a file with the given path and language
+func (c *Config) RulesFor(path, language string) []Rule {
+	return FilterRules(c.Rules, path, language)
+}
+
+// FilterRules returns the rules that apply to a file with the given path and language
+func FilterRules(rules []Rule, path, language string) []Rule {
+	var result []Rule
+	for _, rule := range rules {
+		if rule.AppliesTo(path, language) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// FindRule returns the rule with the given ID, or nil if none matches
+func FindRule(rules []Rule, id string) *Rule {
+	if id == "" {
+		return nil
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// NewRules converts plain rule texts into unscoped rules
+func NewRules(texts []string) []Rule {
+	if texts == nil {
+		return nil
+	}
+	rules := make([]Rule, 0, len(texts))
+	for _, text := range texts {
+		rules = append(rules, Rule{Text: text, Severity: DefaultSeverity})
+	}
+	return rules
+}
+
+// IsValidSeverity reports whether s is a known severity level
+func IsValidSeverity(s string) bool {
+	switch s {
+	case SeverityCritical, SeverityWarning, SeverityInfo:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromPath_ScopedRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "ainspector.yaml")
+	content := `rules:
+  - All exceptions must be logged
+  - id: sql-prepared
+    text: SQL must use prepared statements
+    paths:
+      - internal/db/**
+    languages: [go]
+    severity: critical
+  - id: no-console
+    text: No console.log in production code
+    languages: [javascript, typescript]
+`
+	_ = os.WriteFile(configPath, []byte(content), 0644)
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Rules) != 3 {
+		t.Fatalf("expected 3 rules, got %d", len(cfg.Rules))
+	}
+
+	plain := cfg.Rules[0]
+	if plain.Text != "All exceptions must be logged" || plain.ID != "" {
+		t.Errorf("unexpected plain rule: %+v", plain)
+	}
+	if plain.Severity != DefaultSeverity {
+		t.Errorf("expected default severity for plain rule, got %q", plain.Severity)
+	}
+
+	sql := cfg.Rules[1]
+	if sql.ID != "sql-prepared" || sql.Severity != SeverityCritical {
+		t.Errorf("unexpected scoped rule: %+v", sql)
+	}
+	if len(sql.Paths) != 1 || sql.Paths[0] != "internal/db/**" {
+		t.Errorf("unexpected paths: %v", sql.Paths)
+	}
+
+	if cfg.Rules[2].Severity != DefaultSeverity {
+		t.Errorf("expected default severity, got %q", cfg.Rules[2].Severity)
+	}
+}
+
+func TestLoadFromPath_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing text",
+			content: "rules:\n  - id: empty\n    paths: [src/]\n",
+			wantErr: "must have a text",
+		},
+		{
+			name:    "unknown severity",
+			content: "rules:\n  - text: Something\n    severity: blocker\n",
+			wantErr: "invalid severity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "ainspector.yaml")
+			_ = os.WriteFile(configPath, []byte(tt.content), 0644)
+
+			_, err := LoadFromPath(configPath)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRule_AppliesTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		path     string
+		language string
+		expected bool
+	}{
+		{
+			name:     "unscoped rule applies everywhere",
+			rule:     Rule{Text: "rule"},
+			path:     "web/app.ts",
+			language: "typescript",
+			expected: true,
+		},
+		{
+			name:     "matches doublestar path",
+			rule:     Rule{Text: "rule", Paths: []string{"internal/db/**"}},
+			path:     "internal/db/queries/user.go",
+			language: "go",
+			expected: true,
+		},
+		{
+			name:     "does not match other path",
+			rule:     Rule{Text: "rule", Paths: []string{"internal/db/**"}},
+			path:     "internal/api/handler.go",
+			language: "go",
+			expected: false,
+		},
+		{
+			name:     "matches directory pattern",
+			rule:     Rule{Text: "rule", Paths: []string{"frontend/"}},
+			path:     "frontend/src/index.js",
+			language: "javascript",
+			expected: true,
+		},
+		{
+			name:     "matches language case-insensitively",
+			rule:     Rule{Text: "rule", Languages: []string{"Go"}},
+			path:     "main.go",
+			language: "go",
+			expected: true,
+		},
+		{
+			name:     "does not match other language",
+			rule:     Rule{Text: "rule", Languages: []string{"javascript", "typescript"}},
+			path:     "main.go",
+			language: "go",
+			expected: false,
+		},
+		{
+			name:     "requires both path and language",
+			rule:     Rule{Text: "rule", Paths: []string{"internal/db/**"}, Languages: []string{"go"}},
+			path:     "internal/db/schema.sql.ts",
+			language: "typescript",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.AppliesTo(tt.path, tt.language); got != tt.expected {
+				t.Errorf("AppliesTo(%q, %q) = %v, want %v", tt.path, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_RulesFor(t *testing.T) {
+	cfg := Config{Rules: []Rule{
+		{Text: "global"},
+		{ID: "db", Text: "db only", Paths: []string{"internal/db/**"}},
+		{ID: "ts", Text: "ts only", Languages: []string{"typescript"}},
+	}}
+
+	rules := cfg.RulesFor("internal/db/user.go", "go")
+	if len(rules) != 2 || rules[0].Text != "global" || rules[1].ID != "db" {
+		t.Errorf("unexpected rules for db file: %+v", rules)
+	}
+
+	rules = cfg.RulesFor("web/app.ts", "typescript")
+	if len(rules) != 2 || rules[1].ID != "ts" {
+		t.Errorf("unexpected rules for ts file: %+v", rules)
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	rules := []Rule{{ID: "a", Text: "A"}, {ID: "b", Text: "B"}}
+
+	if r := FindRule(rules, "b"); r == nil || r.Text != "B" {
+		t.Errorf("expected rule b, got %+v", r)
+	}
+	if r := FindRule(rules, "missing"); r != nil {
+		t.Errorf("expected nil for unknown id, got %+v", r)
+	}
+	if r := FindRule(rules, ""); r != nil {
+		t.Errorf("expected nil for empty id, got %+v", r)
+	}
+}
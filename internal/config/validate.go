@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// ruleFields lists the keys allowed in the mapping form of a rule
+var ruleFields = []string{"id", "text", "paths", "languages", "severity"}
+
+// rootFields lists the repository-wide sections, only allowed in the root
+// configuration file
+var rootFields = []string{"prompts", "cache", "comments", "checks", "status"}
+
+// decode strictly decodes a configuration file: unknown fields and invalid
+// glob patterns are reported as errors with their line number
+func decode(data []byte) (*Config, error) {
+	var cfg Config
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			// Empty file
+			return &cfg, nil
+		}
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if err := validatePatterns(&root); err != nil {
+		return nil, err
+	}
+	if err := validateCache(&root, cfg.Cache); err != nil {
+		return nil, err
+	}
+	if err := validateComments(&root, cfg.Comments); err != nil {
+		return nil, err
+	}
+	if err := validateChecks(&root, cfg.Checks); err != nil {
+		return nil, err
+	}
+	if err := validateStatus(&root, cfg.Status); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// validatePatterns checks the syntax of every glob pattern in the document
+func validatePatterns(root *yaml.Node) error {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil
+	}
+	doc := root.Content[0]
+
+	var errs []error
+	check := func(field string, list *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range list.Content {
+			if item.Kind == yaml.ScalarNode && !doublestar.ValidatePattern(item.Value) {
+				errs = append(errs, fmt.Errorf("line %d: invalid glob pattern %q in %s", item.Line, item.Value, field))
+			}
+		}
+	}
+
+	check("ignore.paths", mappingValue(mappingValue(doc, "ignore"), "paths"))
+	check("context.include", mappingValue(mappingValue(doc, "context"), "include"))
+	check("context.exclude", mappingValue(mappingValue(doc, "context"), "exclude"))
+
+	if rules := mappingValue(doc, "rules"); rules != nil && rules.Kind == yaml.SequenceNode {
+		for _, rule := range rules.Content {
+			check("rules.paths", mappingValue(rule, "paths"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNested checks that a nested configuration file doesn't set
+// repository-wide sections, which would otherwise be silently dropped
+func validateNested(data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	doc := root.Content[0]
+
+	var errs []error
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key := doc.Content[i]
+		if slices.Contains(rootFields, key.Value) {
+			errs = append(errs, fmt.Errorf("line %d: %s can only be set in the root configuration file", key.Line, key.Value))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateCache checks the cache settings
+func validateCache(root *yaml.Node, cache CacheConfig) error {
+	switch cache.Invalidate {
+	case "", InvalidateRules, InvalidateAny, InvalidateNever:
+	default:
+		return fmt.Errorf("line %d: invalid cache.invalidate %q (expected rules, any or never)", fieldLine(root, "cache", "invalidate"), cache.Invalidate)
+	}
+
+	switch cache.Hash {
+	case "", HashExact, HashTokens:
+	default:
+		return fmt.Errorf("line %d: invalid cache.hash %q (expected exact or tokens)", fieldLine(root, "cache", "hash"), cache.Hash)
+	}
+
+	return nil
+}
+
+// validateComments checks the comments settings
+func validateComments(root *yaml.Node, comments CommentsConfig) error {
+	switch comments.Outdated {
+	case "", OutdatedResolve, OutdatedReply, OutdatedOff:
+	default:
+		return fmt.Errorf("line %d: invalid comments.outdated %q (expected resolve, reply or off)", fieldLine(root, "comments", "outdated"), comments.Outdated)
+	}
+
+	switch comments.Duplicates {
+	case "", DuplicatesSkip, DuplicatesBump, DuplicatesOff:
+	default:
+		return fmt.Errorf("line %d: invalid comments.duplicates %q (expected skip, bump or off)", fieldLine(root, "comments", "duplicates"), comments.Duplicates)
+	}
+
+	return nil
+}
+
+// validateChecks checks the check run settings
+func validateChecks(root *yaml.Node, checks ChecksConfig) error {
+	if checks.FailOn == "" || checks.FailOn == FailNever || IsValidSeverity(checks.FailOn) {
+		return nil
+	}
+
+	return fmt.Errorf("line %d: invalid checks.fail_on %q (expected critical, warning, info or never)", fieldLine(root, "checks", "fail_on"), checks.FailOn)
+}
+
+// validateStatus checks the commit status settings
+func validateStatus(root *yaml.Node, status StatusConfig) error {
+	if status.FailOn == "" || status.FailOn == FailNever || IsValidSeverity(status.FailOn) {
+		return nil
+	}
+
+	return fmt.Errorf("line %d: invalid status.fail_on %q (expected critical, warning, info or never)", fieldLine(root, "status", "fail_on"), status.FailOn)
+}
+
+// checkFields reports keys of a mapping node that are not in allowed
+func checkFields(node *yaml.Node, kind string, allowed []string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		known := false
+		for _, name := range allowed {
+			if key.Value == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("line %d: unknown field %q in %s (expected one of: %s)", key.Line, key.Value, kind, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldLine returns the line of a field of a top-level section of the
+// document, or 0 if the field isn't set
+func fieldLine(root *yaml.Node, section, field string) int {
+	if len(root.Content) == 0 {
+		return 0
+	}
+	if node := mappingValue(mappingValue(root.Content[0], section), field); node != nil {
+		return node.Line
+	}
+	return 0
+}
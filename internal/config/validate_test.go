@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+		},
+		{
+			name: "valid configuration",
+			content: `ignore:
+  paths: ["vendor/", "**/*.pb.go"]
+rules:
+  - "No panics"
+  - id: sql
+    text: Use prepared statements
+    paths: ["internal/db/**"]
+`,
+		},
+		{
+			name: "unknown top-level field",
+			content: `ignores:
+  paths: ["vendor/"]
+`,
+			wantErr: []string{"line 1", `field ignores not found`},
+		},
+		{
+			name: "unknown nested field",
+			content: `context:
+  includes: ["README.md"]
+`,
+			wantErr: []string{"line 2", `field includes not found`},
+		},
+		{
+			name: "unknown rule field",
+			content: `rules:
+  - id: sql
+    txt: Use prepared statements
+`,
+			wantErr: []string{"line 3", `unknown field "txt" in rule`},
+		},
+		{
+			name: "invalid ignore pattern",
+			content: `ignore:
+  paths:
+    - vendor/
+    - "src/[abc"
+`,
+			wantErr: []string{"line 4", `invalid glob pattern "src/[abc" in ignore.paths`},
+		},
+		{
+			name: "invalid cache policy",
+			content: `cache:
+  invalidate: sometimes
+`,
+			wantErr: []string{"line 2", `invalid cache.invalidate "sometimes"`},
+		},
+		{
+			name: "invalid cache hash mode",
+			content: `cache:
+  invalidate: any
+  hash: ast
+`,
+			wantErr: []string{"line 3", `invalid cache.hash "ast"`},
+		},
+		{
+			name: "invalid outdated comments handling",
+			content: `comments:
+  outdated: delete
+`,
+			wantErr: []string{"line 2", `invalid comments.outdated "delete"`},
+		},
+		{
+			name: "invalid duplicates handling",
+			content: `comments:
+  outdated: reply
+  duplicates: merge
+`,
+			wantErr: []string{"line 3", `invalid comments.duplicates "merge"`},
+		},
+		{
+			name: "checks",
+			content: `checks:
+  enabled: true
+  comments: false
+  fail_on: never
+`,
+		},
+		{
+			name: "invalid checks severity",
+			content: `checks:
+  enabled: true
+  fail_on: error
+`,
+			wantErr: []string{"line 3", `invalid checks.fail_on "error"`},
+		},
+		{
+			name: "status",
+			content: `status:
+  fail_on: warning
+`,
+		},
+		{
+			name: "invalid status severity",
+			content: `checks:
+  fail_on: never
+status:
+  fail_on: error
+`,
+			wantErr: []string{"line 4", `invalid status.fail_on "error"`},
+		},
+		{
+			name: "invalid patterns are all reported",
+			content: `context:
+  include: ["docs/{a,b"]
+rules:
+  - text: Rule
+    paths: ["api/[x"]
+`,
+			wantErr: []string{
+				`line 2: invalid glob pattern "docs/{a,b" in context.include`,
+				`line 5: invalid glob pattern "api/[x" in rules.paths`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decode([]byte(tt.content))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg == nil {
+					t.Fatal("expected non-nil config")
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got %q", want, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_StrictErrorsIncludeFile(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	createTestFile(t, tmpDir, "ainspector.yaml", "rules:\n  - No panics\n")
+	createTestFile(t, tmpDir, "services/api/ainspector.yaml", "rule:\n  - Typo\n")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "services/api/ainspector.yaml") || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected error to name the file and line, got %q", err.Error())
+	}
+}
+
+// TestSchema_CoversConfig checks that the published JSON schema documents
+// every field of the configuration file
+func TestSchema_CoversConfig(t *testing.T) {
+	data, err := os.ReadFile("../../schema/ainspector.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := typ.Field(i).Tag.Get("yaml")
+		if tag == "" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("schema is missing property %q", name)
+		}
+	}
+
+	for _, field := range ruleFields {
+		if !strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("schema is missing rule property %q", field)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 type ModifiedLines struct {
 	Added   []int // Line numbers of added lines (in new file)
 	Deleted []int // Line numbers of deleted lines (in old file)
+	Context []int // Line numbers of unchanged lines around the changes (in new file)
 }
 
 // ParsePatch parses a unified diff patch and returns the modified line numbers
@@ -31,6 +32,7 @@ func ParsePatch(patch string) (*ModifiedLines, error) {
 	result := &ModifiedLines{
 		Added:   make([]int, 0),
 		Deleted: make([]int, 0),
+		Context: make([]int, 0),
 	}
 
 	for _, fd := range fileDiffs {
@@ -51,12 +53,9 @@ func ParsePatch(patch string) (*ModifiedLines, error) {
 				case '-':
 					result.Deleted = append(result.Deleted, oldLine)
 					oldLine++
-				case ' ':
-					// Context line
-					newLine++
-					oldLine++
 				default:
-					// No prefix means context line too
+					// Context line (no prefix means context line too)
+					result.Context = append(result.Context, newLine)
 					newLine++
 					oldLine++
 				}
@@ -84,6 +84,9 @@ func TestParsePatch_MixedChanges(t *testing.T) {
 	if len(result.Deleted) != 1 || result.Deleted[0] != 2 {
 		t.Errorf("expected deleted line 2, got %v", result.Deleted)
 	}
+	if len(result.Context) != 3 || result.Context[0] != 1 || result.Context[1] != 3 || result.Context[2] != 4 {
+		t.Errorf("expected context lines [1 3 4], got %v", result.Context)
+	}
 }
 
 func TestParsePatch_MultipleHunks(t *testing.T) {
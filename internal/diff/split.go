@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+// FilePatch is the patch of one file of a multi-file diff
+type FilePatch struct {
+	Path    string // New path, or old path for deleted files
+	OldPath string // Old path, or new path for added files
+	Status  string // added, modified, deleted, renamed
+	Patch   string // Hunks of the file, without the file headers
+}
+
+// SplitFiles splits a multi-file unified diff (e.g. the output of git diff)
+// into the patch of each file, in the format of the GitHub and GitLab APIs
+func SplitFiles(multiFileDiff string) ([]FilePatch, error) {
+	fileDiffs, err := diff.ParseMultiFileDiff([]byte(multiFileDiff))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FilePatch, 0, len(fileDiffs))
+	for _, fd := range fileDiffs {
+		oldPath := trimPrefix(fd.OrigName, "a/")
+		newPath := trimPrefix(fd.NewName, "b/")
+
+		fp := FilePatch{Path: newPath, OldPath: oldPath, Status: "modified"}
+		switch {
+		case oldPath == "/dev/null":
+			fp.Status = "added"
+			fp.OldPath = newPath
+		case newPath == "/dev/null":
+			fp.Status = "deleted"
+			fp.Path = oldPath
+		case oldPath != newPath:
+			fp.Status = "renamed"
+		}
+
+		hunks, err := diff.PrintHunks(fd.Hunks)
+		if err != nil {
+			return nil, err
+		}
+		fp.Patch = string(hunks)
+
+		result = append(result, fp)
+	}
+
+	return result, nil
+}
+
+// trimPrefix removes the a/ or b/ prefix git adds to paths
+func trimPrefix(name, prefix string) string {
+	if name == "/dev/null" {
+		return name
+	}
+	return strings.TrimPrefix(name, prefix)
+}
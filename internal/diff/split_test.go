@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitFiles(t *testing.T) {
+	multiFileDiff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+ 
++// Added comment
+ func main() {}
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package main
++func added() {}
+diff --git a/old.go b/old.go
+deleted file mode 100644
+index 4444444..0000000
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+diff --git a/a.go b/b.go
+similarity index 90%
+rename from a.go
+rename to b.go
+index 5555555..6666666 100644
+--- a/a.go
++++ b/b.go
+@@ -1 +1 @@
+-package a
++package b
+`
+
+	files, err := SplitFiles(multiFileDiff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []FilePatch{
+		{Path: "main.go", OldPath: "main.go", Status: "modified"},
+		{Path: "new.go", OldPath: "new.go", Status: "added"},
+		{Path: "old.go", OldPath: "old.go", Status: "deleted"},
+		{Path: "b.go", OldPath: "a.go", Status: "renamed"},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("expected %d files, got %+v", len(want), files)
+	}
+	for i, w := range want {
+		got := files[i]
+		if got.Path != w.Path || got.OldPath != w.OldPath || got.Status != w.Status {
+			t.Errorf("file %d = %+v, want %+v", i, got, w)
+		}
+		if !strings.HasPrefix(got.Patch, "@@ ") {
+			t.Errorf("file %d: expected patch to start with a hunk header, got %q", i, got.Patch)
+		}
+	}
+
+	// The patches can be parsed like the patches of the GitHub API
+	lines, err := ParsePatch(files[0].Patch)
+	if err != nil {
+		t.Fatalf("failed to parse patch: %v", err)
+	}
+	if len(lines.Added) != 1 || lines.Added[0] != 3 {
+		t.Errorf("expected line 3 to be added, got %v", lines.Added)
+	}
+}
@@ -0,0 +1,210 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextLines is the number of unchanged lines around the changes of the
+// patches computed by Unified, as in git diff
+const ContextLines = 3
+
+// edit is a line of an edit script: ' ' (unchanged), '-' (deleted) or '+'
+// (added)
+type edit struct {
+	kind byte
+	text string
+}
+
+// Unified computes the patch turning oldContent into newContent, for the git
+// hosts whose API doesn't return patches. The patch is in the format of the
+// GitHub and GitLab APIs: hunks with ContextLines lines of context, without
+// the file headers.
+func Unified(oldContent, newContent string) string {
+	edits := editScript(splitLines(oldContent), splitLines(newContent))
+
+	var b strings.Builder
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Extend the hunk over the changes separated by at most twice the
+		// context, so that hunks don't overlap
+		end := i
+		for j := i; j < len(edits) && j-end <= 2*ContextLines+1; j++ {
+			if edits[j].kind != ' ' {
+				end = j
+			}
+		}
+		start := max(0, i-ContextLines)
+		stop := min(len(edits), end+ContextLines+1)
+
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, e := range edits[start:stop] {
+			body.WriteByte(e.kind)
+			body.WriteString(e.text)
+			body.WriteByte('\n')
+			if e.kind != '+' {
+				oldCount++
+			}
+			if e.kind != '-' {
+				newCount++
+			}
+		}
+
+		// Empty ranges start at the line before them
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n%s", oldStart, oldCount, newStart, newCount, body.String())
+
+		for _, e := range edits[i:stop] {
+			if e.kind != '+' {
+				oldLine++
+			}
+			if e.kind != '-' {
+				newLine++
+			}
+		}
+		i = stop
+	}
+
+	return b.String()
+}
+
+// splitLines splits content into lines, without their line breaks
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// editScript returns the shortest edit script turning a into b, with the
+// Myers algorithm
+func editScript(a, b []string) []edit {
+	// Lines common to the start and the end are kept out of the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+
+	return edits
+}
+
+// maxEdits bounds the number of edits searched by myers, whose memory grows
+// with its square. Beyond it, the lines are replaced as a whole.
+const maxEdits = 2000
+
+// myers returns the shortest edit script turning a into b, or a script
+// deleting a and adding b if they differ by more than maxEdits lines
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+2)
+
+	// Find the furthest reaching paths of each number of edits d, keeping the
+	// diagonals reachable at each step (-d-1 to d+1) to walk the shortest
+	// path back
+	var trace [][]int
+	found := false
+search:
+	for d := 0; d <= min(n+m, maxEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+
+	if !found {
+		return replaceLines(a, b)
+	}
+
+	// Walk the shortest path back from the end
+	var reversed []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[d+k] < v[d+k+2]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+1+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, edit{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, edit{'+', b[y-1]})
+			} else {
+				reversed = append(reversed, edit{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+// replaceLines returns the edit script deleting the lines of a and adding the
+// lines of b
+func replaceLines(a, b []string) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, edit{'-', line})
+	}
+	for _, line := range b {
+		edits = append(edits, edit{'+', line})
+	}
+	return edits
+}
@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+		want       string
+	}{
+		{
+			name:       "identical",
+			oldContent: "a\nb\n",
+			newContent: "a\nb\n",
+			want:       "",
+		},
+		{
+			name:       "added file",
+			newContent: "a\nb\n",
+			want:       "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:       "deleted file",
+			oldContent: "a\n",
+			want:       "@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name:       "changed line with context",
+			oldContent: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			newContent: "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			want:       "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:       "insertion",
+			oldContent: "a\nb\nc\n",
+			newContent: "a\nb\nnew\nc\n",
+			want:       "@@ -1,3 +1,4 @@\n a\n b\n+new\n c\n",
+		},
+		{
+			name:       "distant changes in separate hunks",
+			oldContent: lines(1, 20),
+			newContent: strings.Replace(strings.Replace(lines(1, 20), "2\n", "two\n", 1), "19\n", "nineteen\n", 1),
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n+nineteen\n 20\n",
+		},
+		{
+			name:       "close changes in one hunk",
+			oldContent: lines(1, 10),
+			newContent: strings.Replace(strings.Replace(lines(1, 10), "2\n", "two\n", 1), "9\n", "nine\n", 1),
+			want:       "@@ -1,10 +1,10 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified(tt.oldContent, tt.newContent)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnified_ParsePatch(t *testing.T) {
+	oldContent := "package main\n\nfunc a() {}\n\nfunc b() {}\n"
+	newContent := "package main\n\nimport \"fmt\"\n\nfunc a() {}\n\nfunc b() {\n\tfmt.Println()\n}\n"
+
+	lines, err := ParsePatch(Unified(oldContent, newContent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []int{3, 4, 7, 8, 9}
+	if fmt.Sprint(lines.Added) != fmt.Sprint(want) {
+		t.Errorf("Added = %v, want %v", lines.Added, want)
+	}
+	if fmt.Sprint(lines.Deleted) != fmt.Sprint([]int{5}) {
+		t.Errorf("Deleted = %v, want [5]", lines.Deleted)
+	}
+}
+
+func TestUnified_LargeRewrite(t *testing.T) {
+	oldContent := lines(1, maxEdits)
+	newContent := "header\n" + strings.ReplaceAll(lines(maxEdits+1, 2*maxEdits), "\n", "x\n") + "1\n"
+
+	patch := Unified(oldContent, newContent)
+
+	// Beyond maxEdits, the changed lines are replaced as a whole
+	header := fmt.Sprintf("@@ -1,%d +1,%d @@\n", maxEdits, maxEdits+2)
+	if !strings.HasPrefix(patch, header) {
+		t.Fatalf("expected the patch to start with %q, got %q", header, patch[:min(len(patch), 80)])
+	}
+
+	changed, err := ParsePatch(patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed.Added) != maxEdits+2 || len(changed.Deleted) != maxEdits {
+		t.Errorf("expected every line to be replaced, got %d added and %d deleted", len(changed.Added), len(changed.Deleted))
+	}
+}
+
+// lines returns the numbers from first to last, one per line
+func lines(first, last int) string {
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	return b.String()
+}
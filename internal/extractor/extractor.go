@@ -19,7 +19,11 @@ type ExtractedFunction struct {
 	Diff       string `json:"diff"` // The diff/patch for this specific function
 	FilePath   string `json:"file_path"`
 	Language   string `json:"language"`
-	ChangeType string `json:"change_type"` // "added", "modified", "deleted"
+	ChangeType string `json:"change_type"`          // "added", "modified", "deleted"
+	TokenHash  string `json:"token_hash,omitempty"` // Hash of the syntax tree, ignoring whitespace and comments
+
+	// Suppressions lists the lines silenced by inline suppression comments
+	Suppressions []Suppression `json:"suppressions,omitempty"`
 }
 
 // Extractor extracts modified functions from PR/MR files
@@ -56,7 +60,7 @@ func (e *Extractor) ExtractModifiedFunctions(ctx context.Context, files []provid
 		}
 
 		// Skip files matching ignore patterns from config
-		if e.config != nil && e.config.ShouldIgnore(file.Path) {
+		if e.config != nil && e.config.ForPath(file.Path).ShouldIgnore(file.Path) {
 			fmt.Printf("Skipping ignored file: %s\n", file.Path)
 			continue
 		}
@@ -92,16 +96,23 @@ func (e *Extractor) extractFromFile(ctx context.Context, file provider.ModifiedF
 		return nil, fmt.Errorf("failed to parse patch: %w", err)
 	}
 
-	// Parse the file to get all functions
-	functions, language, err := e.parser.Parse(file.Path, []byte(content))
+	// Parse the file to get all functions and comments
+	parsed, err := e.parser.ParseFile(file.Path, []byte(content))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
+	markers := parseMarkers(parsed.Comments)
 
 	// Filter functions that have modified lines
 	var result []ExtractedFunction
-	for _, fn := range functions {
+	for _, fn := range parsed.Functions {
 		if modifiedLines.HasModifiedLineInRange(fn.StartLine, fn.EndLine) {
+			// Skip functions disabled by an inline comment
+			if isDisabled(markers, fn, parsed.Functions) {
+				fmt.Printf("Skipping suppressed function: %s (%s)\n", fn.Name, file.Path)
+				continue
+			}
+
 			changeType := "modified"
 			if file.Status == "added" {
 				changeType = "added"
@@ -111,14 +122,16 @@ func (e *Extractor) extractFromFile(ctx context.Context, file provider.ModifiedF
 			fnDiff := diff.ExtractDiffForRange(file.Patch, fn.StartLine, fn.EndLine)
 
 			result = append(result, ExtractedFunction{
-				Name:       fn.Name,
-				StartLine:  fn.StartLine,
-				EndLine:    fn.EndLine,
-				Content:    fn.Content,
-				Diff:       fnDiff,
-				FilePath:   file.Path,
-				Language:   language,
-				ChangeType: changeType,
+				Name:         fn.Name,
+				StartLine:    fn.StartLine,
+				EndLine:      fn.EndLine,
+				Content:      fn.Content,
+				Diff:         fnDiff,
+				FilePath:     file.Path,
+				Language:     parsed.Language,
+				ChangeType:   changeType,
+				TokenHash:    fn.TokenHash,
+				Suppressions: suppressionsFor(markers, fn.StartLine, fn.EndLine),
 			})
 		}
 	}
@@ -45,6 +45,14 @@ func (m *mockProvider) GetReviewComments(ctx context.Context, number int) ([]pro
 	return nil, nil
 }
 
+func (m *mockProvider) ResolveComment(ctx context.Context, number int, comment provider.ExistingComment) error {
+	return nil
+}
+
+func (m *mockProvider) ReplyToComment(ctx context.Context, number int, comment provider.ExistingComment, body string) error {
+	return nil
+}
+
 func TestExtractModifiedFunctions_SkipsDeletedFiles(t *testing.T) {
 	mock := &mockProvider{files: map[string]string{}}
 	e := New(mock, nil)
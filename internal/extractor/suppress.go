@@ -0,0 +1,153 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/parser"
+)
+
+// Suppression markers recognized in source comments
+const (
+	// MarkerIgnoreNextLine drops findings on the line following the comment
+	MarkerIgnoreNextLine = "ignore-next-line"
+	// MarkerIgnoreLine drops findings on the line of the comment
+	MarkerIgnoreLine = "ignore-line"
+	// MarkerDisableFunction skips the review of the function containing the
+	// comment, or of the function right below it
+	MarkerDisableFunction = "disable-function"
+)
+
+// markerRegex matches a suppression marker, optionally followed by a list of
+// rule IDs, e.g. "ainspector:ignore-next-line[sql-prepared, no-console]"
+var markerRegex = regexp.MustCompile(`ainspector:(ignore-next-line|ignore-line|disable-function)\b([^\n]*)`)
+
+// ruleIDRegex matches a rule ID in the arguments of a marker
+var ruleIDRegex = regexp.MustCompile(`[A-Za-z0-9_.-]+`)
+
+// Suppression silences the findings of a line, optionally only for some rules
+type Suppression struct {
+	Line  int      `json:"line"`
+	Rules []string `json:"rules,omitempty"` // Empty means all findings
+}
+
+// Matches reports whether the suppression silences a finding of rule (which
+// may be empty) on line
+func (s Suppression) Matches(line int, rule string) bool {
+	if s.Line != line {
+		return false
+	}
+	if len(s.Rules) == 0 {
+		return true
+	}
+	for _, id := range s.Rules {
+		if id == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// marker is a suppression marker found in a comment
+type marker struct {
+	kind      string
+	rules     []string
+	startLine int
+	endLine   int
+}
+
+// parseMarkers returns the suppression markers found in comments
+func parseMarkers(comments []parser.Comment) []marker {
+	var markers []marker
+	for _, comment := range comments {
+		for _, match := range markerRegex.FindAllStringSubmatch(comment.Text, -1) {
+			markers = append(markers, marker{
+				kind:      match[1],
+				rules:     parseRuleIDs(match[2]),
+				startLine: comment.StartLine,
+				endLine:   comment.EndLine,
+			})
+		}
+	}
+	return markers
+}
+
+// parseRuleIDs extracts the rule IDs following a marker, written as a
+// bracketed list: [id1, id2]. Any other text is a free-form justification.
+func parseRuleIDs(args string) []string {
+	args = strings.TrimSpace(args)
+	if !strings.HasPrefix(args, "[") {
+		return nil
+	}
+
+	end := strings.Index(args, "]")
+	if end < 0 {
+		return nil
+	}
+
+	return ruleIDRegex.FindAllString(args[1:end], -1)
+}
+
+// isDisabled reports whether fn is disabled by a marker in the comment right
+// above it, or inside it and not inside one of its nested functions
+func isDisabled(markers []marker, fn parser.Function, functions []parser.Function) bool {
+	for _, m := range markers {
+		if m.kind != MarkerDisableFunction {
+			continue
+		}
+		if m.endLine == fn.StartLine-1 {
+			return true
+		}
+		if inner := innermost(functions, m.startLine); inner != nil && inner.StartLine == fn.StartLine && inner.EndLine == fn.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// innermost returns the smallest function containing line, or nil
+func innermost(functions []parser.Function, line int) *parser.Function {
+	var result *parser.Function
+	for i := range functions {
+		fn := &functions[i]
+		if line < fn.StartLine || line > fn.EndLine {
+			continue
+		}
+		if result == nil || fn.EndLine-fn.StartLine < result.EndLine-result.StartLine {
+			result = fn
+		}
+	}
+	return result
+}
+
+// suppressionsFor returns the line suppressions applying to a function
+// spanning startLine to endLine
+func suppressionsFor(markers []marker, startLine, endLine int) []Suppression {
+	var result []Suppression
+	for _, m := range markers {
+		var line int
+		switch m.kind {
+		case MarkerIgnoreNextLine:
+			line = m.endLine + 1
+		case MarkerIgnoreLine:
+			line = m.startLine
+		default:
+			continue
+		}
+		if line >= startLine && line <= endLine {
+			result = append(result, Suppression{Line: line, Rules: m.rules})
+		}
+	}
+	return result
+}
+
+// IsSuppressed reports whether a finding of rule (which may be empty) on line
+// is silenced by an inline suppression comment
+func (f ExtractedFunction) IsSuppressed(line int, rule string) bool {
+	for _, s := range f.Suppressions {
+		if s.Matches(line, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/provider"
+)
+
+// addedPatch returns the patch of a newly added file with the given content
+func addedPatch(content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("@@ -0,0 +1,%d @@\n", len(lines)))
+	for _, line := range lines {
+		sb.WriteString("+" + line + "\n")
+	}
+	return sb.String()
+}
+
+// extractAdded extracts the functions of a newly added file
+func extractAdded(t *testing.T, path, content string) []ExtractedFunction {
+	t.Helper()
+	e := New(&mockProvider{files: map[string]string{path: content}}, nil)
+	defer e.Close()
+
+	result, err := e.ExtractModifiedFunctions(context.Background(), []provider.ModifiedFile{
+		{Path: path, Status: "added", Patch: addedPatch(content)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func functionNames(functions []ExtractedFunction) []string {
+	var names []string
+	for _, fn := range functions {
+		names = append(names, fn.Name)
+	}
+	return names
+}
+
+func TestParseRuleIDs(t *testing.T) {
+	tests := []struct {
+		args string
+		want []string
+	}{
+		{"", nil},
+		{"[sql-prepared]", []string{"sql-prepared"}},
+		{" [sql-prepared]", []string{"sql-prepared"}},
+		{"[sql-prepared,no_console]", []string{"sql-prepared", "no_console"}},
+		{" [sql-prepared, no-console]", []string{"sql-prepared", "no-console"}},
+		{" legacy workaround", nil},
+		{" sql-prepared no_console", nil},
+		{" see [sql-prepared]", nil},
+		{" [sql] */", []string{"sql"}},
+		{" [sql] -- legacy query, see #12", []string{"sql"}},
+		{" -- false positive", nil},
+		{" -->", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			if got := parseRuleIDs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRuleIDs(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract_DisableFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name: "go comment above function",
+			path: "main.go",
+			content: `package main
+
+// ainspector:disable-function
+func legacy() {
+	println("legacy")
+}
+
+func reviewed() {
+	println("reviewed")
+}
+`,
+			want: []string{"reviewed"},
+		},
+		{
+			name: "python comment inside function",
+			path: "app.py",
+			content: `def legacy():
+    # ainspector:disable-function
+    return 1
+
+def reviewed():
+    return 2
+`,
+			want: []string{"reviewed"},
+		},
+		{
+			name: "javascript block comment",
+			path: "app.js",
+			content: `/* ainspector:disable-function */
+function legacy() {
+  return 1;
+}
+
+function reviewed() {
+  return 2;
+}
+`,
+			want: []string{"reviewed"},
+		},
+		{
+			name: "rust line comment",
+			path: "lib.rs",
+			content: `// ainspector:disable-function
+fn legacy() -> i32 {
+    1
+}
+
+fn reviewed() -> i32 {
+    2
+}
+`,
+			want: []string{"reviewed"},
+		},
+		{
+			name: "marker in nested function only disables it",
+			path: "app.js",
+			content: `function outer() {
+  const inner = () => {
+    // ainspector:disable-function
+    return 1;
+  };
+  return inner();
+}
+`,
+			want: []string{"outer"},
+		},
+		{
+			name: "marker separated by a blank line is ignored",
+			path: "main.go",
+			content: `package main
+
+// ainspector:disable-function
+
+func reviewed() {
+	println("reviewed")
+}
+`,
+			want: []string{"reviewed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := functionNames(extractAdded(t, tt.path, tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extracted functions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract_LineSuppressions(t *testing.T) {
+	content := `package main
+
+func query(db *DB) {
+	// ainspector:ignore-next-line[sql-prepared]
+	db.Exec("SELECT * FROM users WHERE id = " + id)
+	db.Exec("DELETE FROM users") // ainspector:ignore-line legacy workaround
+	println("done")
+}
+`
+	functions := extractAdded(t, "main.go", content)
+	if len(functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(functions))
+	}
+	fn := functions[0]
+
+	want := []Suppression{
+		{Line: 5, Rules: []string{"sql-prepared"}},
+		{Line: 6},
+	}
+	if !reflect.DeepEqual(fn.Suppressions, want) {
+		t.Fatalf("Suppressions = %+v, want %+v", fn.Suppressions, want)
+	}
+
+	tests := []struct {
+		line int
+		rule string
+		want bool
+	}{
+		{5, "sql-prepared", true},
+		{5, "other-rule", false},
+		{5, "", false},
+		{6, "", true},
+		{6, "any-rule", true},
+		{7, "", false},
+	}
+	for _, tt := range tests {
+		if got := fn.IsSuppressed(tt.line, tt.rule); got != tt.want {
+			t.Errorf("IsSuppressed(%d, %q) = %v, want %v", tt.line, tt.rule, got, tt.want)
+		}
+	}
+}
@@ -176,6 +176,15 @@ Keep it brief and focused on what's most relevant for code review.
 Do NOT include version numbers, build configurations, or minor details.
 Respond with ONLY the context description, no preamble or explanation.`
 
+// instructionFiles lists the AI instruction files among contextFiles, in order of priority
+var instructionFiles = []string{"CLAUDE.md", "AGENTS.md", ".cursorrules", ".github/copilot-instructions.md"}
+
+// InstructionFiles returns the AI instruction files looked for when generating
+// the project context (CLAUDE.md, AGENTS.md...), in order of priority
+func InstructionFiles() []string {
+	return append([]string(nil), instructionFiles...)
+}
+
 // findContextFiles searches for relevant context files in the project
 func findContextFiles(projectRoot string) (map[string]string, error) {
 	found := make(map[string]string)
@@ -222,7 +231,7 @@ func buildContextPrompt(files map[string]string, languages []string) string {
 	sb.WriteString("Project files:\n\n")
 
 	// Order: AI instructions first, then README, then config files
-	aiFiles := []string{"CLAUDE.md", "AGENTS.md", ".cursorrules", ".github/copilot-instructions.md"}
+	aiFiles := instructionFiles
 	readme := "README.md"
 
 	// Process AI instruction files first
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
 )
 
 func TestGenerateProjectContext_WithFiles(t *testing.T) {
@@ -256,7 +257,10 @@ func TestBuildSystemPrompt_WithProjectContext(t *testing.T) {
 		Languages:   []string{"javascript", "typescript"},
 	}
 
-	prompt := buildSystemPrompt("javascript", projectContext, nil)
+	prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "javascript"}, projectContext, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should contain base prompt
 	if !strings.Contains(prompt, "You are an expert code reviewer") {
@@ -279,7 +283,10 @@ func TestBuildSystemPrompt_WithProjectContext(t *testing.T) {
 }
 
 func TestBuildSystemPrompt_WithoutProjectContext(t *testing.T) {
-	prompt := buildSystemPrompt("go", nil, nil)
+	prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should contain base prompt
 	if !strings.Contains(prompt, "You are an expert code reviewer") {
@@ -303,7 +310,10 @@ func TestBuildSystemPrompt_WithEmptyProjectContext(t *testing.T) {
 		Languages:   []string{"go"},
 	}
 
-	prompt := buildSystemPrompt("go", projectContext, nil)
+	prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, projectContext, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should NOT contain project context section when description is empty
 	if strings.Contains(prompt, "PROJECT CONTEXT") {
@@ -472,7 +482,10 @@ func TestBuildSystemPrompt_RawVsLegacy(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			prompt := buildSystemPrompt("go", tt.projectContext, nil)
+			prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, tt.projectContext, nil))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
 			if !strings.Contains(prompt, tt.expectHeader) {
 				t.Errorf("expected prompt to contain %q", tt.expectHeader)
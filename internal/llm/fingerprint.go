@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+)
+
+// FingerprintLength is the length of the short fingerprints
+const FingerprintLength = 8
+
+// Fingerprint identifies the review settings a function was reviewed with.
+// Comparing it with the current settings tells whether a previous review is
+// still up to date.
+type Fingerprint struct {
+	// Rules covers the project rules and the language checklist applying to the function.
+	Rules string
+	// Prompt covers the whole review setup: rules, checklist, project context
+	// sources, prompt templates and model.
+	Prompt string
+}
+
+// IsZero reports whether the fingerprint is unknown, e.g. for reviews posted
+// by versions of ainspector without fingerprints
+func (f Fingerprint) IsZero() bool {
+	return f == Fingerprint{}
+}
+
+// Fingerprinter computes the fingerprints of functions for a review setup.
+type Fingerprinter struct {
+	// Config provides the rules, language checklists and context settings.
+	Config *config.Config
+	// Prompts are the prompt templates. Defaults to the built-in templates.
+	Prompts *Prompts
+	// Model is the LLM model name.
+	Model string
+	// ProjectRoot is the directory context files are read from. Defaults to
+	// the working directory.
+	ProjectRoot string
+
+	// contexts caches the fingerprint of the context sources, keyed by
+	// include/exclude patterns
+	contexts map[string]string
+}
+
+// Fingerprint returns the fingerprint of the current review settings of fn.
+func (f *Fingerprinter) Fingerprint(fn *extractor.ExtractedFunction) Fingerprint {
+	cfg := &config.Config{}
+	if f.Config != nil {
+		cfg = f.Config.ForPath(fn.FilePath)
+	}
+
+	var rules []string
+	for _, rule := range cfg.RulesFor(fn.FilePath, fn.Language) {
+		rules = append(rules, rule.ID+"\x00"+rule.Text+"\x00"+rule.Severity)
+	}
+	rules = append(rules, Checklist(fn.Language, cfg.Language(fn.Language)))
+	rulesHash := shortHash(rules...)
+
+	prompts := f.Prompts
+	if prompts == nil {
+		prompts = DefaultPrompts()
+	}
+
+	return Fingerprint{
+		Rules:  rulesHash,
+		Prompt: shortHash(rulesHash, f.Model, prompts.Version(), f.contextFingerprint(&cfg.Context)),
+	}
+}
+
+// contextFingerprint hashes the files the project context is built from:
+// the configured context files, or the files found by default
+func (f *Fingerprinter) contextFingerprint(c *config.ContextConfig) string {
+	key := contextKey(c)
+	if hash, ok := f.contexts[key]; ok {
+		return hash
+	}
+
+	projectRoot := f.ProjectRoot
+	if projectRoot == "" {
+		projectRoot = "."
+	}
+
+	var files map[string]string
+	if len(c.Include) > 0 {
+		files, _, _ = c.CollectContextFiles(projectRoot)
+	} else {
+		files, _ = findContextFiles(projectRoot)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	parts := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		parts = append(parts, path, files[path])
+	}
+	hash := shortHash(parts...)
+
+	if f.contexts == nil {
+		f.contexts = make(map[string]string)
+	}
+	f.contexts[key] = hash
+	return hash
+}
+
+// shortHash returns a short hash of parts
+func shortHash(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:])[:FingerprintLength]
+}
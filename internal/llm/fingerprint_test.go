@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+)
+
+func TestFingerprinter_Fingerprint(t *testing.T) {
+	projectRoot := t.TempDir()
+	_ = os.WriteFile(filepath.Join(projectRoot, "README.md"), []byte("# Project"), 0644)
+
+	goFn := &extractor.ExtractedFunction{Name: "run", FilePath: "main.go", Language: "go"}
+	baseConfig := func() *config.Config {
+		return &config.Config{Rules: []config.Rule{
+			{Text: "No panics", Severity: config.SeverityWarning},
+			{ID: "ts", Text: "No any", Languages: []string{"typescript"}, Severity: config.SeverityWarning},
+		}}
+	}
+	fingerprint := func(cfg *config.Config, model string) Fingerprint {
+		f := &Fingerprinter{Config: cfg, Model: model, ProjectRoot: projectRoot}
+		return f.Fingerprint(goFn)
+	}
+
+	base := fingerprint(baseConfig(), "gpt-4o")
+	if len(base.Rules) != FingerprintLength || len(base.Prompt) != FingerprintLength {
+		t.Fatalf("unexpected fingerprint lengths: %+v", base)
+	}
+	if base != fingerprint(baseConfig(), "gpt-4o") {
+		t.Error("expected fingerprint to be deterministic")
+	}
+
+	t.Run("model change only changes prompt fingerprint", func(t *testing.T) {
+		got := fingerprint(baseConfig(), "gpt-4o-mini")
+		if got.Rules != base.Rules || got.Prompt == base.Prompt {
+			t.Errorf("got %+v, base %+v", got, base)
+		}
+	})
+
+	t.Run("rule change changes both fingerprints", func(t *testing.T) {
+		cfg := baseConfig()
+		cfg.Rules[0].Text = "No panics outside main"
+		got := fingerprint(cfg, "gpt-4o")
+		if got.Rules == base.Rules || got.Prompt == base.Prompt {
+			t.Errorf("got %+v, base %+v", got, base)
+		}
+	})
+
+	t.Run("rules of other languages are ignored", func(t *testing.T) {
+		cfg := baseConfig()
+		cfg.Rules[1].Text = "Prefer unknown over any"
+		if got := fingerprint(cfg, "gpt-4o"); got != base {
+			t.Errorf("got %+v, base %+v", got, base)
+		}
+	})
+
+	t.Run("checklist change changes rules fingerprint", func(t *testing.T) {
+		cfg := baseConfig()
+		cfg.Languages = map[string]config.LanguageConfig{"go": {ExtraRules: []string{"Wrap errors"}}}
+		if got := fingerprint(cfg, "gpt-4o"); got.Rules == base.Rules {
+			t.Errorf("got %+v, base %+v", got, base)
+		}
+	})
+
+	t.Run("context change only changes prompt fingerprint", func(t *testing.T) {
+		_ = os.WriteFile(filepath.Join(projectRoot, "README.md"), []byte("# Renamed project"), 0644)
+		got := fingerprint(baseConfig(), "gpt-4o")
+		if got.Rules != base.Rules || got.Prompt == base.Prompt {
+			t.Errorf("got %+v, base %+v", got, base)
+		}
+	})
+}
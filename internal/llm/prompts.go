@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+)
+
+//go:embed prompts/*.tmpl prompts/checklists/*.txt
+var promptFS embed.FS
+
+// languageSpecificRules maps language names to their built-in review checklist,
+// loaded from the embedded prompts/checklists directory
+var languageSpecificRules = loadChecklists()
+
+// PromptData is the data model exposed to the system and user prompt templates.
+//
+// Templates can reference the following fields:
+//
+//	{{.Language}}                  language of the function under review (e.g. "go")
+//	{{.Function.Name}}             function name
+//	{{.Function.FilePath}}         path of the file containing the function
+//	{{.Function.StartLine}}        first line of the function in the file
+//	{{.Function.EndLine}}          last line of the function in the file
+//	{{.Function.ChangeType}}       "added" or "modified"
+//	{{.Function.Content}}          full source of the function
+//	{{.Diff}}                      diff restricted to the function (may be empty)
+//	{{.ProjectContext.Description}} project context (ProjectContext may be nil)
+//	{{.ProjectContext.IsRaw}}      true when the context is raw file contents
+//	{{.Rules}}                     project rules applying to the function, each
+//	                               with .ID, .Text, .Severity, .Paths and .Languages
+//	{{.LanguageRules}}             checklist for the language (may be empty)
+//
+// The "add" function is available for arithmetic, e.g. {{add $i 1}}.
+type PromptData struct {
+	Language       string
+	Function       *extractor.ExtractedFunction
+	Diff           string
+	ProjectContext *ProjectContext
+	Rules          []config.Rule
+	LanguageRules  string
+}
+
+// Prompts holds the parsed system and user prompt templates
+type Prompts struct {
+	system *template.Template
+	user   *template.Template
+
+	// systemSource and userSource hold the template texts, used for Version
+	systemSource string
+	userSource   string
+}
+
+var templateFuncs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+}
+
+// defaultPrompts holds the built-in templates embedded in the binary
+var defaultPrompts = newDefaultPrompts()
+
+func newDefaultPrompts() *Prompts {
+	system := mustReadEmbedded("prompts/system.tmpl")
+	user := mustReadEmbedded("prompts/user.tmpl")
+	return &Prompts{
+		system:       template.Must(parseTemplate("system", system)),
+		user:         template.Must(parseTemplate("user", user)),
+		systemSource: system,
+		userSource:   user,
+	}
+}
+
+// DefaultPrompts returns the built-in prompt templates
+func DefaultPrompts() *Prompts {
+	return defaultPrompts
+}
+
+// LoadPrompts returns the built-in prompt templates, replacing each one whose
+// path is set in the configuration with the template read from that file
+func LoadPrompts(cfg *config.PromptsConfig) (*Prompts, error) {
+	prompts := *defaultPrompts
+	if cfg == nil {
+		return &prompts, nil
+	}
+
+	if cfg.System != "" {
+		tmpl, source, err := parseTemplateFile(cfg, "system", cfg.System)
+		if err != nil {
+			return nil, err
+		}
+		prompts.system, prompts.systemSource = tmpl, source
+	}
+
+	if cfg.User != "" {
+		tmpl, source, err := parseTemplateFile(cfg, "user", cfg.User)
+		if err != nil {
+			return nil, err
+		}
+		prompts.user, prompts.userSource = tmpl, source
+	}
+
+	return &prompts, nil
+}
+
+// Version identifies the prompt templates: it changes whenever the text of
+// the system or user template changes
+func (p *Prompts) Version() string {
+	hash := sha256.Sum256([]byte(p.systemSource + "\x00" + p.userSource))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// NewPromptData builds the template data for reviewing a function
+func NewPromptData(fn *extractor.ExtractedFunction, projectContext *ProjectContext, rules []config.Rule) *PromptData {
+	return &PromptData{
+		Language:       fn.Language,
+		Function:       fn,
+		Diff:           fn.Diff,
+		ProjectContext: projectContext,
+		Rules:          rules,
+		LanguageRules:  languageSpecificRules[fn.Language],
+	}
+}
+
+// Checklist returns the review checklist for a language: the built-in checklist
+// (unless disabled in the configuration) followed by the configured extra rules
+func Checklist(language string, cfg config.LanguageConfig) string {
+	var checklist string
+	if cfg.BuiltinRulesEnabled() {
+		checklist = languageSpecificRules[language]
+	}
+
+	if len(cfg.ExtraRules) == 0 {
+		return checklist
+	}
+
+	var sb strings.Builder
+	if checklist == "" {
+		sb.WriteString(fmt.Sprintf("LANGUAGE-SPECIFIC CHECKS FOR %s:", strings.ToUpper(language)))
+	} else {
+		sb.WriteString(checklist)
+	}
+	for _, rule := range cfg.ExtraRules {
+		sb.WriteString("\n- " + rule)
+	}
+
+	return sb.String()
+}
+
+// System renders the system prompt
+func (p *Prompts) System(data *PromptData) (string, error) {
+	return render(p.system, data)
+}
+
+// User renders the user prompt
+func (p *Prompts) User(data *PromptData) (string, error) {
+	return render(p.user, data)
+}
+
+// render executes a template and trims surrounding whitespace from the result
+func render(tmpl *template.Template, data *PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+func parseTemplateFile(cfg *config.PromptsConfig, name, filePath string) (*template.Template, string, error) {
+	data, err := cfg.ReadTemplate(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s prompt template: %w", name, err)
+	}
+
+	tmpl, err := parseTemplate(name, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s prompt template %s: %w", name, filePath, err)
+	}
+
+	return tmpl, data, nil
+}
+
+func mustReadEmbedded(name string) string {
+	data, err := promptFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// loadChecklists reads the built-in language checklists, keyed by language name
+func loadChecklists() map[string]string {
+	entries, err := promptFS.ReadDir("prompts/checklists")
+	if err != nil {
+		panic(err)
+	}
+
+	checklists := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		checklists[name] = strings.TrimSpace(mustReadEmbedded("prompts/checklists/" + entry.Name()))
+	}
+
+	return checklists
+}
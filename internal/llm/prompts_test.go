@@ -0,0 +1,253 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+	"github.com/iq2i/ainspector/internal/extractor"
+)
+
+func TestLanguageChecklists_Embedded(t *testing.T) {
+	languages := []string{"go", "javascript", "typescript", "python", "rust", "java", "c", "cpp", "csharp", "php", "ruby", "bash"}
+
+	for _, lang := range languages {
+		checklist, ok := languageSpecificRules[lang]
+		if !ok {
+			t.Errorf("missing built-in checklist for %q", lang)
+			continue
+		}
+		if !strings.HasPrefix(checklist, "LANGUAGE-SPECIFIC CHECKS FOR ") {
+			t.Errorf("checklist for %q has unexpected header: %q", lang, checklist)
+		}
+	}
+}
+
+func TestLoadPrompts_Defaults(t *testing.T) {
+	prompts, err := LoadPrompts(&config.PromptsConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fn := &extractor.ExtractedFunction{Name: "hello", Language: "go", FilePath: "main.go"}
+	data := NewPromptData(fn, nil, nil)
+
+	system, err := prompts.System(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defaultSystem, err := DefaultPrompts().System(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if system != defaultSystem {
+		t.Error("expected default system prompt when no override is configured")
+	}
+
+	user, err := prompts.User(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defaultUser, err := DefaultPrompts().User(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user != defaultUser {
+		t.Error("expected default user prompt when no override is configured")
+	}
+}
+
+func TestPrompts_Version(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemPath := filepath.Join(tmpDir, "system.tmpl")
+	_ = os.WriteFile(systemPath, []byte("Custom system prompt"), 0644)
+
+	defaults, _ := LoadPrompts(nil)
+	if defaults.Version() != DefaultPrompts().Version() {
+		t.Error("expected same version for the default prompts")
+	}
+
+	custom, err := LoadPrompts(&config.PromptsConfig{System: systemPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if custom.Version() == defaults.Version() {
+		t.Error("expected custom system prompt to change the version")
+	}
+}
+
+func TestLoadPrompts_Overrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemPath := filepath.Join(tmpDir, "system.tmpl")
+	userPath := filepath.Join(tmpDir, "user.tmpl")
+
+	systemTmpl := `Reviewer for {{.Language}}.
+{{range $i, $rule := .Rules}}{{add $i 1}}) {{$rule.Text}}
+{{end}}`
+	userTmpl := `{{.Function.FilePath}}:{{.Function.StartLine}} {{.Function.Name}}
+{{.Diff}}
+`
+	_ = os.WriteFile(systemPath, []byte(systemTmpl), 0644)
+	_ = os.WriteFile(userPath, []byte(userTmpl), 0644)
+
+	prompts, err := LoadPrompts(&config.PromptsConfig{System: systemPath, User: userPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fn := &extractor.ExtractedFunction{Name: "run", Language: "go", FilePath: "cmd/run.go", StartLine: 12, Diff: "+x := 1"}
+	data := NewPromptData(fn, nil, config.NewRules([]string{"first", "second"}))
+
+	system, err := prompts.System(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if system != "Reviewer for go.\n1) first\n2) second" {
+		t.Errorf("unexpected system prompt: %q", system)
+	}
+
+	user, err := prompts.User(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user != "cmd/run.go:12 run\n+x := 1" {
+		t.Errorf("unexpected user prompt: %q", user)
+	}
+
+	// Overriding must not affect the built-in templates
+	defaultSystem, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(defaultSystem, "You are an expert code reviewer") {
+		t.Error("default prompts should not be modified by overrides")
+	}
+}
+
+func TestLoadPrompts_PartialOverride(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "user.tmpl")
+	_ = os.WriteFile(userPath, []byte("Function {{.Function.Name}}"), 0644)
+
+	prompts, err := LoadPrompts(&config.PromptsConfig{User: userPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := NewPromptData(&extractor.ExtractedFunction{Name: "f", Language: "python"}, nil, nil)
+
+	system, _ := prompts.System(data)
+	if !strings.Contains(system, "LANGUAGE-SPECIFIC CHECKS FOR PYTHON") {
+		t.Error("system prompt should fall back to the built-in template")
+	}
+
+	user, _ := prompts.User(data)
+	if user != "Function f" {
+		t.Errorf("unexpected user prompt: %q", user)
+	}
+}
+
+func TestLoadPrompts_MissingFile(t *testing.T) {
+	_, err := LoadPrompts(&config.PromptsConfig{System: "/nonexistent/system.tmpl"})
+	if err == nil {
+		t.Fatal("expected error for missing template file")
+	}
+}
+
+func TestLoadPrompts_InvalidTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	_ = os.WriteFile(path, []byte("{{if .Rules}}unterminated"), 0644)
+
+	_, err := LoadPrompts(&config.PromptsConfig{User: path})
+	if err == nil {
+		t.Fatal("expected error for invalid template")
+	}
+	if !strings.Contains(err.Error(), "bad.tmpl") {
+		t.Errorf("error should mention the template path, got: %v", err)
+	}
+}
+
+func TestPrompts_RenderError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.tmpl")
+	_ = os.WriteFile(path, []byte("{{.Unknown}}"), 0644)
+
+	prompts, err := LoadPrompts(&config.PromptsConfig{User: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = prompts.User(NewPromptData(&extractor.ExtractedFunction{}, nil, nil))
+	if err == nil {
+		t.Fatal("expected error when the template references an unknown field")
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name        string
+		language    string
+		cfg         config.LanguageConfig
+		contains    []string
+		notContains []string
+		empty       bool
+	}{
+		{
+			name:     "built-in checklist by default",
+			language: "go",
+			contains: []string{"LANGUAGE-SPECIFIC CHECKS FOR GO", "goroutines"},
+		},
+		{
+			name:     "extra rules appended to built-in checklist",
+			language: "javascript",
+			cfg:      config.LanguageConfig{ExtraRules: []string{"Use the shared logger"}},
+			contains: []string{"LANGUAGE-SPECIFIC CHECKS FOR JAVASCRIPT", "promise rejections", "\n- Use the shared logger"},
+		},
+		{
+			name:        "built-in checklist disabled",
+			language:    "javascript",
+			cfg:         config.LanguageConfig{BuiltinRules: &disabled, ExtraRules: []string{"Prefer named exports"}},
+			contains:    []string{"LANGUAGE-SPECIFIC CHECKS FOR JAVASCRIPT:\n- Prefer named exports"},
+			notContains: []string{"avoid var"},
+		},
+		{
+			name:     "built-in checklist disabled without extra rules",
+			language: "go",
+			cfg:      config.LanguageConfig{BuiltinRules: &disabled},
+			empty:    true,
+		},
+		{
+			name:     "checklist for a language without built-in rules",
+			language: "css",
+			cfg:      config.LanguageConfig{ExtraRules: []string{"Avoid !important", "Use design tokens for colors"}},
+			contains: []string{"LANGUAGE-SPECIFIC CHECKS FOR CSS:\n- Avoid !important\n- Use design tokens for colors"},
+		},
+		{
+			name:     "unknown language without configuration",
+			language: "html",
+			empty:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checklist := Checklist(tt.language, tt.cfg)
+
+			if tt.empty && checklist != "" {
+				t.Errorf("expected empty checklist, got %q", checklist)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(checklist, want) {
+					t.Errorf("checklist should contain %q, got %q", want, checklist)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(checklist, unwanted) {
+					t.Errorf("checklist should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
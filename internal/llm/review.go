@@ -6,206 +6,20 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
 )
 
 // LGTMMarker is returned by the LLM when there are no issues to report.
 const LGTMMarker = "LGTM"
 
-const baseSystemPrompt = `You are an expert code reviewer. You will receive a function along with the diff showing only the modified lines.
-
-IMPORTANT: Focus your review ONLY on the changes shown in the diff. The full function is provided for context only - do not review unchanged code.
-
-For the modified lines, identify ONLY actual issues:
-- Bugs or logic errors
-- Security vulnerabilities
-- Serious performance problems
-- Violations of language best practices
-- Code that could cause runtime errors
-
-RESPONSE FORMAT:
-If there are NO issues, respond with exactly: LGTM
-
-If there ARE issues, respond with a JSON object in this exact format:
-{
-  "issues": [
-    {
-      "line": <line number in the file where the issue is>,
-      "description": "<brief description of the issue>",
-      "suggestion": "<corrected code to replace the problematic line(s), or empty string if no fix suggested>"
-    }
-  ]
-}
-
-IMPORTANT RULES:
-- The "line" field must be an actual line number from the file (between the function's start and end lines)
-- The "suggestion" field should contain the corrected code that can replace the problematic code
-- Do NOT comment on code style, formatting, or minor improvements
-- Do NOT give positive feedback or praise
-- Only report problems that should be fixed
-- Respond in the same language as the code comments, or in English if there are no comments`
-
-// languageSpecificRules maps language names to their specific review rules
-var languageSpecificRules = map[string]string{
-	"go": `
-LANGUAGE-SPECIFIC CHECKS FOR GO:
-- Verify all errors are properly handled (no ignored errors)
-- Check for potential nil pointer dereferences
-- Ensure goroutines won't leak (proper cleanup/cancellation)
-- Verify context is properly propagated in function signatures
-- Check for race conditions in concurrent code
-- Ensure defer statements are used correctly (not in loops unless intended)
-- Verify proper use of channels (close on sender side, check for closed channels)`,
-
-	"javascript": `
-LANGUAGE-SPECIFIC CHECKS FOR JAVASCRIPT:
-- Verify async functions properly await promises
-- Check for unhandled promise rejections
-- Ensure variables are properly scoped (avoid var, prefer const/let)
-- Check for potential null/undefined access
-- Verify proper error handling in async/await blocks
-- Check for memory leaks (event listeners, timers not cleaned up)
-- Ensure proper use of === instead of ==`,
-
-	"typescript": `
-LANGUAGE-SPECIFIC CHECKS FOR TYPESCRIPT:
-- Verify async functions properly await promises
-- Check for unhandled promise rejections
-- Ensure proper TypeScript types (avoid 'any' type unless necessary)
-- Check for potential null/undefined access (use optional chaining)
-- Verify proper error handling in async/await blocks
-- Check for memory leaks (event listeners, timers not cleaned up)
-- Ensure type assertions are safe and necessary`,
-
-	"python": `
-LANGUAGE-SPECIFIC CHECKS FOR PYTHON:
-- Verify proper exception handling (catch specific exceptions, not bare except)
-- Check for resource leaks (use context managers/with statements)
-- Ensure mutable default arguments are not used
-- Check for proper iterator usage (avoid modifying during iteration)
-- Verify None checks before attribute access
-- Check for SQL injection vulnerabilities (use parameterized queries)
-- Ensure proper use of async/await in async functions`,
-
-	"rust": `
-LANGUAGE-SPECIFIC CHECKS FOR RUST:
-- Verify proper error handling with Result type
-- Check for potential panics (unwrap, expect usage)
-- Ensure proper lifetime annotations where needed
-- Verify ownership and borrowing rules are followed
-- Check for potential race conditions even with Rust's safety
-- Ensure proper use of Option type (avoid unwrap on None)
-- Verify unsafe blocks are necessary and sound`,
-
-	"java": `
-LANGUAGE-SPECIFIC CHECKS FOR JAVA:
-- Verify proper exception handling (don't catch and ignore)
-- Check for resource leaks (use try-with-resources)
-- Ensure proper null checks before dereferencing
-- Verify thread safety in concurrent code
-- Check for SQL injection vulnerabilities (use PreparedStatement)
-- Ensure proper equals() and hashCode() implementation in collections
-- Verify proper use of Optional instead of null returns`,
-
-	"c": `
-LANGUAGE-SPECIFIC CHECKS FOR C:
-- Verify all malloc/calloc have corresponding free
-- Check for buffer overflows (array bounds, strcpy vs strncpy)
-- Ensure proper null pointer checks before dereferencing
-- Verify no use-after-free bugs
-- Check for integer overflows in arithmetic
-- Ensure proper initialization of variables
-- Verify format string vulnerabilities (printf, scanf)`,
-
-	"cpp": `
-LANGUAGE-SPECIFIC CHECKS FOR C++:
-- Verify RAII principles (use smart pointers, no raw new/delete)
-- Check for proper exception safety
-- Ensure proper const correctness
-- Verify no dangling references or iterators
-- Check for proper move semantics usage
-- Ensure virtual destructors in base classes
-- Verify no memory leaks (use unique_ptr, shared_ptr)`,
-
-	"csharp": `
-LANGUAGE-SPECIFIC CHECKS FOR C#:
-- Verify proper disposal of IDisposable (use using statements)
-- Check for null reference exceptions (use null-conditional operators)
-- Ensure async methods properly await tasks
-- Verify proper exception handling (specific catch blocks)
-- Check for SQL injection vulnerabilities (use parameterized queries)
-- Ensure proper thread safety with async code
-- Verify LINQ queries are efficient (avoid multiple enumeration)`,
-
-	"php": `
-LANGUAGE-SPECIFIC CHECKS FOR PHP:
-- Verify SQL injection prevention (use prepared statements)
-- Check for XSS vulnerabilities (proper output escaping)
-- Ensure proper error handling (try-catch blocks)
-- Verify null coalescing and null-safe operators usage
-- Check for CSRF protection in forms
-- Ensure proper password hashing (password_hash, not md5/sha1)
-- Verify file upload security (type, size validation)`,
-
-	"ruby": `
-LANGUAGE-SPECIFIC CHECKS FOR RUBY:
-- Verify SQL injection prevention (use ActiveRecord parameters)
-- Check for XSS vulnerabilities (proper output escaping)
-- Ensure proper exception handling (rescue specific errors)
-- Verify nil checks before method calls (use safe navigation &.)
-- Check for mass assignment vulnerabilities (strong parameters)
-- Ensure proper symbol/string usage for memory efficiency
-- Verify thread safety in multi-threaded code`,
-
-	"bash": `
-LANGUAGE-SPECIFIC CHECKS FOR BASH:
-- Verify proper quoting of variables to prevent word splitting
-- Check for command injection vulnerabilities
-- Ensure proper error handling (set -e, set -u, set -o pipefail)
-- Verify proper use of [[ ]] instead of [ ] for tests
-- Check for race conditions in file operations
-- Ensure proper cleanup in trap handlers
-- Verify safe handling of user input`,
-}
-
-// buildSystemPrompt creates a language-specific system prompt with optional project context and rules
-func buildSystemPrompt(language string, projectContext *ProjectContext, rules []string) string {
-	prompt := baseSystemPrompt
-
-	// Add project context if available
-	if projectContext != nil && projectContext.Description != "" {
-		if projectContext.IsRaw {
-			// Raw file contents from config
-			prompt += "\n\n=== PROJECT CONTEXT ===\n" + projectContext.Description
-		} else {
-			// LLM-generated summary (legacy)
-			prompt += "\n\nPROJECT CONTEXT:\n" + projectContext.Description
-		}
-	}
-
-	// Add project-specific rules
-	if len(rules) > 0 {
-		prompt += "\n\n=== PROJECT RULES (MUST BE ENFORCED) ===\n"
-		prompt += "The following rules are specific to this project and MUST be enforced.\n"
-		prompt += "Report any violations of these rules explicitly in your review:\n\n"
-		for i, rule := range rules {
-			prompt += fmt.Sprintf("%d. %s\n", i+1, rule)
-		}
-	}
-
-	// Add language-specific rules
-	if rules, ok := languageSpecificRules[language]; ok {
-		prompt += "\n" + rules
-	}
-
-	return prompt
-}
-
 // Suggestion represents a code suggestion for a specific issue.
 type Suggestion struct {
 	Line        int    `json:"line"`
 	Description string `json:"description"`
 	Code        string `json:"suggestion"`
+	Severity    string `json:"severity,omitempty"`
+	Rule        string `json:"rule,omitempty"` // ID of the violated project rule, if any
 }
 
 // ReviewResponse is the structured response from the LLM.
@@ -230,23 +44,62 @@ func (r *ReviewResult) HasIssues() bool {
 	return len(r.Suggestions) > 0
 }
 
+// Reviewer reviews functions using an LLM client and prompt templates.
+type Reviewer struct {
+	client  *Client
+	prompts *Prompts
+
+	// ProjectContext is included in the system prompt when set.
+	ProjectContext *ProjectContext
+	// Config provides the project rules and language checklists. Scoped rules
+	// are only sent for functions matching their paths and languages, and
+	// nested configurations are resolved for each function's file.
+	Config *config.Config
+	// ProjectRoot is the directory context files of nested configurations are
+	// read from. Defaults to the working directory.
+	ProjectRoot string
+
+	// contexts caches the project context of nested configurations adding
+	// their own context files, keyed by include/exclude patterns
+	contexts map[string]*ProjectContext
+}
+
+// NewReviewer creates a Reviewer. If prompts is nil, the built-in templates are used.
+func NewReviewer(client *Client, prompts *Prompts) *Reviewer {
+	if prompts == nil {
+		prompts = DefaultPrompts()
+	}
+	return &Reviewer{
+		client:  client,
+		prompts: prompts,
+	}
+}
+
 // ReviewFunctions reviews each function using the LLM and returns the results.
 // If projectContext is provided, it will be included in the system prompt for better context.
 // If rules are provided, they will be enforced as mandatory project-specific rules.
 func ReviewFunctions(ctx context.Context, client *Client, functions []extractor.ExtractedFunction, projectContext *ProjectContext, rules []string) []ReviewResult {
+	reviewer := NewReviewer(client, nil)
+	reviewer.ProjectContext = projectContext
+	reviewer.Config = &config.Config{Rules: config.NewRules(rules)}
+	return reviewer.Review(ctx, functions)
+}
+
+// Review reviews each function using the LLM and returns the results.
+func (r *Reviewer) Review(ctx context.Context, functions []extractor.ExtractedFunction) []ReviewResult {
 	results := make([]ReviewResult, 0, len(functions))
 
 	for _, fn := range functions {
 		result := ReviewResult{Function: fn}
 
-		systemPrompt := buildSystemPrompt(fn.Language, projectContext, rules)
-		userPrompt := buildUserPrompt(&fn)
-		messages := []ChatMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
+		messages, err := r.buildMessages(&fn)
+		if err != nil {
+			result.Error = err
+			results = append(results, result)
+			continue
 		}
 
-		review, err := client.Complete(ctx, messages)
+		review, err := r.client.Complete(ctx, messages)
 		if err != nil {
 			result.Error = err
 			results = append(results, result)
@@ -254,13 +107,102 @@ func ReviewFunctions(ctx context.Context, client *Client, functions []extractor.
 		}
 
 		result.RawReview = review
-		result.Suggestions = parseReviewResponse(review)
+		result.Suggestions = applyRuleSeverity(parseReviewResponse(review), r.config().ForPath(fn.FilePath).Rules)
 		results = append(results, result)
 	}
 
 	return results
 }
 
+// config returns the review configuration, or an empty one if none is set
+func (r *Reviewer) config() *config.Config {
+	if r.Config == nil {
+		return &config.Config{}
+	}
+	return r.Config
+}
+
+// projectContextFor returns the project context for an effective configuration.
+// Nested configurations adding context files get their own context built from
+// the configured files; others share the project context of the reviewer.
+func (r *Reviewer) projectContextFor(cfg *config.Config) *ProjectContext {
+	key := contextKey(&cfg.Context)
+	if key == contextKey(&r.config().Context) {
+		return r.ProjectContext
+	}
+
+	if pc, ok := r.contexts[key]; ok {
+		return pc
+	}
+
+	var languages []string
+	if r.ProjectContext != nil {
+		languages = r.ProjectContext.Languages
+	}
+
+	projectRoot := r.ProjectRoot
+	if projectRoot == "" {
+		projectRoot = "."
+	}
+
+	pc, err := generateConfigBasedContext(projectRoot, languages, &cfg.Context)
+	if err != nil {
+		fmt.Printf("Warning: failed to generate project context: %v\n", err)
+		pc = r.ProjectContext
+	}
+
+	if r.contexts == nil {
+		r.contexts = make(map[string]*ProjectContext)
+	}
+	r.contexts[key] = pc
+	return pc
+}
+
+// contextKey identifies a context configuration by its patterns
+func contextKey(c *config.ContextConfig) string {
+	return strings.Join(c.Include, "\n") + "\x00" + strings.Join(c.Exclude, "\n")
+}
+
+// buildMessages renders the system and user prompts for a function
+func (r *Reviewer) buildMessages(fn *extractor.ExtractedFunction) ([]ChatMessage, error) {
+	cfg := r.config().ForPath(fn.FilePath)
+	data := NewPromptData(fn, r.projectContextFor(cfg), cfg.RulesFor(fn.FilePath, fn.Language))
+	data.LanguageRules = Checklist(fn.Language, cfg.Language(fn.Language))
+
+	systemPrompt, err := r.prompts.System(data)
+	if err != nil {
+		return nil, err
+	}
+
+	userPrompt, err := r.prompts.User(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, nil
+}
+
+// applyRuleSeverity normalises the severity of suggestions, falling back to the
+// default severity of the violated rule when the LLM didn't provide a valid one
+func applyRuleSeverity(suggestions []Suggestion, rules []config.Rule) []Suggestion {
+	for i := range suggestions {
+		s := &suggestions[i]
+		s.Severity = strings.ToLower(strings.TrimSpace(s.Severity))
+		if config.IsValidSeverity(s.Severity) {
+			continue
+		}
+
+		s.Severity = config.DefaultSeverity
+		if rule := config.FindRule(rules, s.Rule); rule != nil {
+			s.Severity = rule.Severity
+		}
+	}
+	return suggestions
+}
+
 // parseReviewResponse parses the LLM response into structured suggestions.
 func parseReviewResponse(response string) []Suggestion {
 	trimmed := strings.TrimSpace(response)
@@ -290,22 +232,3 @@ func parseReviewResponse(response string) []Suggestion {
 
 	return reviewResp.Issues
 }
-
-func buildUserPrompt(fn *extractor.ExtractedFunction) string {
-	diffSection := ""
-	if fn.Diff != "" {
-		diffSection = fmt.Sprintf("\n\n## Changes (REVIEW THESE):\n```diff\n%s\n```", fn.Diff)
-	}
-
-	return fmt.Sprintf("Review the changes in this %s function:\n\nFile: %s\nFunction: %s (lines %d-%d)\nChange type: %s%s\n\n## Full function (for context only, DO NOT review unchanged code):\n```%s\n%s\n```",
-		fn.Language,
-		fn.FilePath,
-		fn.Name,
-		fn.StartLine,
-		fn.EndLine,
-		fn.ChangeType,
-		diffSection,
-		fn.Language,
-		fn.Content,
-	)
-}
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/iq2i/ainspector/internal/config"
 	"github.com/iq2i/ainspector/internal/extractor"
 )
 
@@ -287,7 +290,10 @@ func TestBuildUserPrompt_WithDiff(t *testing.T) {
 		ChangeType: "modified",
 	}
 
-	prompt := buildUserPrompt(fn)
+	prompt, err := DefaultPrompts().User(NewPromptData(fn, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Check that all required information is included
 	if !strings.Contains(prompt, "go") {
@@ -331,7 +337,10 @@ func TestBuildUserPrompt_NoDiff(t *testing.T) {
 		ChangeType: "added",
 	}
 
-	prompt := buildUserPrompt(fn)
+	prompt, err := DefaultPrompts().User(NewPromptData(fn, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should not have diff section when diff is empty
 	if strings.Contains(prompt, "```diff") {
@@ -355,7 +364,10 @@ func TestBuildUserPrompt_AddedFunction(t *testing.T) {
 		FilePath:   "app.py",
 	}
 
-	prompt := buildUserPrompt(fn)
+	prompt, err := DefaultPrompts().User(NewPromptData(fn, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if !strings.Contains(prompt, "added") {
 		t.Error("prompt should contain change type 'added'")
@@ -374,7 +386,10 @@ func TestBuildUserPrompt_SpecialCharacters(t *testing.T) {
 		Language: "go",
 	}
 
-	prompt := buildUserPrompt(fn)
+	prompt, err := DefaultPrompts().User(NewPromptData(fn, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should contain the special characters (not escape them)
 	if !strings.Contains(prompt, "<script>") {
@@ -636,7 +651,10 @@ func TestBuildSystemPrompt_LanguageSpecific(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			prompt := buildSystemPrompt(tt.language, nil, nil)
+			prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: tt.language}, nil, nil))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
 			// Should always contain base prompt
 			if !strings.Contains(prompt, "You are an expert code reviewer") {
@@ -657,7 +675,10 @@ func TestBuildSystemPrompt_BasePromptAlwaysPresent(t *testing.T) {
 	languages := []string{"go", "javascript", "python", "rust", "unknown"}
 
 	for _, lang := range languages {
-		prompt := buildSystemPrompt(lang, nil, nil)
+		prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: lang}, nil, nil))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
 
 		// All prompts should contain essential base instructions
 		essentials := []string{
@@ -682,7 +703,10 @@ func TestBuildSystemPrompt_WithProjectRules(t *testing.T) {
 		"console.log must not be used in production code",
 	}
 
-	prompt := buildSystemPrompt("go", nil, rules)
+	prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, config.NewRules(rules)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Should contain the rules section header
 	if !strings.Contains(prompt, "=== PROJECT RULES (MUST BE ENFORCED) ===") {
@@ -715,13 +739,19 @@ func TestBuildSystemPrompt_WithProjectRules(t *testing.T) {
 
 func TestBuildSystemPrompt_WithoutProjectRules(t *testing.T) {
 	// Test with nil rules
-	promptNil := buildSystemPrompt("go", nil, nil)
+	promptNil, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if strings.Contains(promptNil, "PROJECT RULES") {
 		t.Error("prompt should not contain project rules section when rules are nil")
 	}
 
 	// Test with empty rules
-	promptEmpty := buildSystemPrompt("go", nil, []string{})
+	promptEmpty, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, config.NewRules([]string{})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if strings.Contains(promptEmpty, "PROJECT RULES") {
 		t.Error("prompt should not contain project rules section when rules are empty")
 	}
@@ -737,7 +767,10 @@ func TestBuildSystemPrompt_WithoutProjectRules(t *testing.T) {
 
 func TestBuildSystemPrompt_RulesPlacement(t *testing.T) {
 	rules := []string{"Test rule"}
-	prompt := buildSystemPrompt("go", nil, rules)
+	prompt, err := DefaultPrompts().System(NewPromptData(&extractor.ExtractedFunction{Language: "go"}, nil, config.NewRules(rules)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	// Rules should come after base prompt but before language-specific rules
 	baseIdx := strings.Index(prompt, "You are an expert code reviewer")
@@ -810,3 +843,196 @@ func TestReviewFunctions_WithProjectRules(t *testing.T) {
 		t.Error("system prompt should contain second rule")
 	}
 }
+
+func TestReviewer_ScopedRules(t *testing.T) {
+	var systemPrompts []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		systemPrompts = append(systemPrompts, req.Messages[0].Content)
+
+		resp := ChatResponse{
+			Choices: []struct {
+				Message ChatMessage `json:"message"`
+			}{
+				{Message: ChatMessage{Role: "assistant", Content: "LGTM"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reviewer := NewReviewer(NewClient(server.URL, "key", "gpt-4"), nil)
+	reviewer.Config = &config.Config{Rules: []config.Rule{
+		{Text: "Global rule", Severity: config.SeverityWarning},
+		{ID: "sql-prepared", Text: "SQL must use prepared statements", Paths: []string{"internal/db/**"}, Severity: config.SeverityCritical},
+		{ID: "no-console", Text: "No console.log", Languages: []string{"javascript"}, Severity: config.SeverityWarning},
+	}}
+
+	functions := []extractor.ExtractedFunction{
+		{Name: "query", Language: "go", FilePath: "internal/db/user.go"},
+		{Name: "render", Language: "javascript", FilePath: "web/app.js"},
+	}
+	reviewer.Review(context.Background(), functions)
+
+	if len(systemPrompts) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(systemPrompts))
+	}
+
+	dbPrompt := systemPrompts[0]
+	if !strings.Contains(dbPrompt, "1. Global rule") {
+		t.Error("db prompt should contain the global rule")
+	}
+	if !strings.Contains(dbPrompt, "2. [sql-prepared] SQL must use prepared statements") {
+		t.Error("db prompt should contain the scoped rule with its id")
+	}
+	if strings.Contains(dbPrompt, "No console.log") {
+		t.Error("db prompt should not contain javascript rules")
+	}
+
+	jsPrompt := systemPrompts[1]
+	if strings.Contains(jsPrompt, "sql-prepared") {
+		t.Error("javascript prompt should not contain db rules")
+	}
+	if !strings.Contains(jsPrompt, "[no-console] No console.log") {
+		t.Error("javascript prompt should contain the javascript rule")
+	}
+}
+
+func TestReviewer_LanguageChecklists(t *testing.T) {
+	var systemPrompt string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		systemPrompt = req.Messages[0].Content
+
+		resp := ChatResponse{
+			Choices: []struct {
+				Message ChatMessage `json:"message"`
+			}{
+				{Message: ChatMessage{Role: "assistant", Content: "LGTM"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	disabled := false
+	reviewer := NewReviewer(NewClient(server.URL, "key", "gpt-4"), nil)
+	reviewer.Config = &config.Config{Languages: map[string]config.LanguageConfig{
+		"javascript": {BuiltinRules: &disabled, ExtraRules: []string{"var is allowed in legacy modules"}},
+	}}
+
+	reviewer.Review(context.Background(), []extractor.ExtractedFunction{
+		{Name: "legacy", Language: "javascript", FilePath: "legacy.js"},
+	})
+
+	if strings.Contains(systemPrompt, "avoid var") {
+		t.Error("built-in javascript checklist should be disabled")
+	}
+	if !strings.Contains(systemPrompt, "- var is allowed in legacy modules") {
+		t.Error("system prompt should contain the extra javascript rule")
+	}
+}
+
+func TestReviewer_NestedConfig(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tmpDir := t.TempDir()
+	_ = os.Chdir(tmpDir)
+
+	_ = os.WriteFile("ainspector.yaml", []byte("rules:\n  - Root rule\n"), 0644)
+	_ = os.MkdirAll(filepath.Join("services", "billing", "docs"), 0755)
+	_ = os.WriteFile(filepath.Join("services", "billing", "ainspector.yaml"), []byte("rules:\n  - Amounts must use integer cents\ncontext:\n  include:\n    - docs/money.md\n"), 0644)
+	_ = os.WriteFile(filepath.Join("services", "billing", "docs", "money.md"), []byte("Billing conventions"), 0644)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var systemPrompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		systemPrompts = append(systemPrompts, req.Messages[0].Content)
+
+		resp := ChatResponse{
+			Choices: []struct {
+				Message ChatMessage `json:"message"`
+			}{
+				{Message: ChatMessage{Role: "assistant", Content: "LGTM"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reviewer := NewReviewer(NewClient(server.URL, "key", "gpt-4"), nil)
+	reviewer.Config = cfg
+	reviewer.ProjectContext = &ProjectContext{Description: "Root context", IsRaw: false}
+	reviewer.Review(context.Background(), []extractor.ExtractedFunction{
+		{Name: "charge", Language: "go", FilePath: "services/billing/charge.go"},
+		{Name: "main", Language: "go", FilePath: "main.go"},
+	})
+
+	if len(systemPrompts) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(systemPrompts))
+	}
+
+	billing := systemPrompts[0]
+	if !strings.Contains(billing, "1. Root rule") || !strings.Contains(billing, "2. Amounts must use integer cents") {
+		t.Error("billing prompt should contain root and nested rules")
+	}
+	if !strings.Contains(billing, "=== services/billing/docs/money.md ===") || !strings.Contains(billing, "Billing conventions") {
+		t.Error("billing prompt should contain the nested context file")
+	}
+
+	root := systemPrompts[1]
+	if strings.Contains(root, "integer cents") || strings.Contains(root, "Billing conventions") {
+		t.Error("root prompt should not contain nested rules or context")
+	}
+	if !strings.Contains(root, "Root context") {
+		t.Error("root prompt should contain the reviewer project context")
+	}
+}
+
+func TestApplyRuleSeverity(t *testing.T) {
+	rules := []config.Rule{
+		{ID: "sql-prepared", Text: "SQL", Severity: config.SeverityCritical},
+	}
+
+	suggestions := []Suggestion{
+		{Line: 1, Description: "rule violation", Rule: "sql-prepared"},
+		{Line: 2, Description: "explicit severity", Rule: "sql-prepared", Severity: "Info"},
+		{Line: 3, Description: "no rule"},
+		{Line: 4, Description: "unknown severity", Severity: "blocker"},
+	}
+
+	result := applyRuleSeverity(suggestions, rules)
+
+	expected := []string{config.SeverityCritical, config.SeverityInfo, config.DefaultSeverity, config.DefaultSeverity}
+	for i, want := range expected {
+		if result[i].Severity != want {
+			t.Errorf("suggestion %d: expected severity %q, got %q", i, want, result[i].Severity)
+		}
+	}
+}
+
+func TestParseReviewResponse_RuleAndSeverity(t *testing.T) {
+	response := `{"issues":[{"line":3,"description":"Raw SQL","suggestion":"","severity":"critical","rule":"sql-prepared"}]}`
+
+	suggestions := parseReviewResponse(response)
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(suggestions))
+	}
+	if suggestions[0].Rule != "sql-prepared" {
+		t.Errorf("expected rule 'sql-prepared', got %q", suggestions[0].Rule)
+	}
+	if suggestions[0].Severity != "critical" {
+		t.Errorf("expected severity 'critical', got %q", suggestions[0].Severity)
+	}
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"unsafe"
@@ -30,6 +33,21 @@ type Function struct {
 	StartLine int
 	EndLine   int
 	Content   string
+	TokenHash string // Hash of the syntax tree, ignoring whitespace and comments
+}
+
+// Comment represents a comment found in source code
+type Comment struct {
+	StartLine int
+	EndLine   int
+	Text      string
+}
+
+// File holds the result of parsing a source file
+type File struct {
+	Language  string
+	Functions []Function
+	Comments  []Comment
 }
 
 // LanguageConfig holds configuration for a specific language
@@ -270,19 +288,28 @@ func SupportedExtensions() []string {
 
 // Parse parses source code and returns all functions
 func (p *Parser) Parse(path string, content []byte) ([]Function, string, error) {
+	file, err := p.ParseFile(path, content)
+	if err != nil {
+		return nil, "", err
+	}
+	return file.Functions, file.Language, nil
+}
+
+// ParseFile parses source code and returns all functions and comments
+func (p *Parser) ParseFile(path string, content []byte) (*File, error) {
 	config := GetConfig(path)
 	if config == nil {
-		return nil, "", fmt.Errorf("unsupported file type: %s", path)
+		return nil, fmt.Errorf("unsupported file type: %s", path)
 	}
 
 	if config.FunctionQuery == "" {
-		return []Function{}, config.Name, nil
+		return &File{Language: config.Name, Functions: []Function{}}, nil
 	}
 
 	// Set language
 	lang := tree_sitter.NewLanguage(config.Language)
 	if err := p.parser.SetLanguage(lang); err != nil {
-		return nil, "", fmt.Errorf("failed to set language: %w", err)
+		return nil, fmt.Errorf("failed to set language: %w", err)
 	}
 
 	// Parse content
@@ -292,7 +319,7 @@ func (p *Parser) Parse(path string, content []byte) ([]Function, string, error)
 	// Create query
 	query, err := tree_sitter.NewQuery(lang, config.FunctionQuery)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create query: %w", err)
+		return nil, fmt.Errorf("failed to create query: %w", err)
 	}
 	defer query.Close()
 
@@ -337,10 +364,89 @@ func (p *Parser) Parse(path string, content []byte) ([]Function, string, error)
 					StartLine: startLine,
 					EndLine:   endLine,
 					Content:   strings.TrimSpace(fnNode.Utf8Text(content)),
+					TokenHash: tokenHash(fnNode, content),
 				})
 			}
 		}
 	}
 
-	return functions, config.Name, nil
+	return &File{
+		Language:  config.Name,
+		Functions: functions,
+		Comments:  collectComments(tree.RootNode(), content, nil),
+	}, nil
+}
+
+// tokenHash hashes the syntax tree of node: the kinds of named nodes and the
+// text of leaf tokens, skipping comments. Formatting changes (whitespace,
+// line breaks, comments) and line shifts don't change the hash, while
+// structural changes like Python indentation do.
+func tokenHash(node *tree_sitter.Node, content []byte) string {
+	hash := sha256.New()
+	writeTokens(hash, node, content)
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// writeTokens writes the token stream of node to w
+func writeTokens(w io.Writer, node *tree_sitter.Node, content []byte) {
+	if strings.Contains(node.Kind(), "comment") {
+		return
+	}
+
+	if node.ChildCount() == 0 {
+		_, _ = io.WriteString(w, node.Utf8Text(content))
+		_, _ = w.Write([]byte{0})
+		return
+	}
+
+	if node.IsNamed() {
+		_, _ = io.WriteString(w, node.Kind()+"(")
+	}
+
+	// Text between children isn't always whitespace: some grammars don't
+	// have nodes for the content of string literals
+	offset := node.StartByte()
+	for i := uint(0); i < node.ChildCount(); i++ {
+		child := node.Child(i)
+		writeGap(w, content[offset:child.StartByte()])
+		writeTokens(w, child, content)
+		offset = child.EndByte()
+	}
+	writeGap(w, content[offset:node.EndByte()])
+
+	if node.IsNamed() {
+		_, _ = io.WriteString(w, ")")
+	}
+}
+
+// writeGap writes the text between two tokens to w, with whitespace collapsed
+func writeGap(w io.Writer, gap []byte) {
+	if fields := strings.Fields(string(gap)); len(fields) > 0 {
+		_, _ = io.WriteString(w, strings.Join(fields, " "))
+		_, _ = w.Write([]byte{0})
+	}
+}
+
+// collectComments appends the comment nodes found under node, in source order.
+// Comment node kinds differ between grammars (comment, line_comment,
+// block_comment...) but all contain "comment".
+func collectComments(node *tree_sitter.Node, content []byte, comments []Comment) []Comment {
+	if strings.Contains(node.Kind(), "comment") {
+		start, end := node.StartPosition(), node.EndPosition()
+		endLine := int(end.Row) + 1
+		// Some grammars include the trailing newline in line comments
+		if end.Column == 0 && end.Row > start.Row {
+			endLine--
+		}
+		return append(comments, Comment{
+			StartLine: int(start.Row) + 1,
+			EndLine:   endLine,
+			Text:      node.Utf8Text(content),
+		})
+	}
+
+	for i := uint(0); i < node.ChildCount(); i++ {
+		comments = collectComments(node.Child(i), content, comments)
+	}
+	return comments
 }
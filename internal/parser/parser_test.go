@@ -2,6 +2,7 @@ package parser
 
 import (
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -700,3 +701,122 @@ const arrow = () => {
 		t.Log("Note: Anonymous functions may or may not have <anonymous> name depending on tree-sitter query")
 	}
 }
+
+func TestParser_ParseFileComments(t *testing.T) {
+	p := NewParser()
+	defer p.Close()
+
+	tests := []struct {
+		path    string
+		content string
+		want    []Comment
+	}{
+		{
+			path:    "main.go",
+			content: "package main\n\n// hello\nfunc hello() {\n\t/* inline */\n}\n",
+			want: []Comment{
+				{StartLine: 3, EndLine: 3, Text: "// hello"},
+				{StartLine: 5, EndLine: 5, Text: "/* inline */"},
+			},
+		},
+		{
+			path:    "app.py",
+			content: "# top\ndef f():\n    return 1  # trailing\n",
+			want: []Comment{
+				{StartLine: 1, EndLine: 1, Text: "# top"},
+				{StartLine: 3, EndLine: 3, Text: "# trailing"},
+			},
+		},
+		{
+			path:    "lib.rs",
+			content: "// doc\nfn f() {}\n",
+			want: []Comment{
+				{StartLine: 1, EndLine: 1, Text: "// doc\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file, err := p.ParseFile(tt.path, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(file.Comments) != len(tt.want) {
+				t.Fatalf("expected %d comments, got %+v", len(tt.want), file.Comments)
+			}
+			for i, want := range tt.want {
+				got := file.Comments[i]
+				if got.StartLine != want.StartLine || got.EndLine != want.EndLine || strings.TrimSpace(got.Text) != strings.TrimSpace(want.Text) {
+					t.Errorf("comment %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_TokenHash(t *testing.T) {
+	p := NewParser()
+	defer p.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		base     string
+		variant  string
+		wantSame bool
+	}{
+		{
+			name:     "reformatted",
+			path:     "main.go",
+			base:     "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+			variant:  "package main\n\nfunc add(a, b int) int { return a+b }\n",
+			wantSame: true,
+		},
+		{
+			name:     "line shift and comments",
+			path:     "main.go",
+			base:     "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+			variant:  "package main\n\nimport \"fmt\"\n\n// add sums\nfunc add(a, b int) int {\n\t// sum\n\treturn a + b /* done */\n}\n",
+			wantSame: true,
+		},
+		{
+			name:    "changed operator",
+			path:    "main.go",
+			base:    "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+			variant: "package main\n\nfunc add(a, b int) int {\n\treturn a - b\n}\n",
+		},
+		{
+			name:    "changed string literal",
+			path:    "main.go",
+			base:    "package main\n\nfunc greet() string {\n\treturn \"hello\"\n}\n",
+			variant: "package main\n\nfunc greet() string {\n\treturn \"hello world\"\n}\n",
+		},
+		{
+			name:    "python indentation",
+			path:    "app.py",
+			base:    "def f(x):\n    if x:\n        x += 1\n    return x\n",
+			variant: "def f(x):\n    if x:\n        x += 1\n        return x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _, err := p.Parse(tt.path, []byte(tt.base))
+			if err != nil || len(base) != 1 {
+				t.Fatalf("failed to parse base: %v %+v", err, base)
+			}
+			variant, _, err := p.Parse(tt.path, []byte(tt.variant))
+			if err != nil || len(variant) != 1 {
+				t.Fatalf("failed to parse variant: %v %+v", err, variant)
+			}
+
+			if base[0].TokenHash == "" {
+				t.Fatal("expected token hash to be set")
+			}
+			if same := base[0].TokenHash == variant[0].TokenHash; same != tt.wantSame {
+				t.Errorf("same hash = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/diff"
+)
+
+// azureAPIVersion is the version of the Azure DevOps REST API used
+const azureAPIVersion = "7.1"
+
+// azureCommentShift is the number of bits of the thread ID shifted in the
+// comment IDs: Azure DevOps numbers comments within their thread, so
+// ExistingComment IDs combine both
+const azureCommentShift = 20
+
+// commitSHARegex matches full commit SHAs, to tell them from branch names
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// AzureDevOpsProvider implements Provider for Azure DevOps Repos
+type AzureDevOpsProvider struct {
+	api      *restClient
+	project  string
+	repo     string
+	headSHA  string
+	reviewed func(path string) bool
+}
+
+// NewAzureDevOpsProvider creates a new Azure DevOps provider for the
+// collection at collectionURL (e.g. https://dev.azure.com/org), authenticating
+// with a personal access token or the System.AccessToken of the pipeline
+func NewAzureDevOpsProvider(collectionURL, project, repo, token string) *AzureDevOpsProvider {
+	header := http.Header{}
+	if token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(":" + token))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+
+	return &AzureDevOpsProvider{
+		api:     newRESTClient(collectionURL, header),
+		project: project,
+		repo:    repo,
+	}
+}
+
+// azureThread is a pull request comment thread of the Azure DevOps API
+type azureThread struct {
+	ID            int64  `json:"id"`
+	Status        string `json:"status"`
+	IsDeleted     bool   `json:"isDeleted"`
+	ThreadContext *struct {
+		FilePath       string `json:"filePath"`
+		RightFileStart *struct {
+			Line int `json:"line"`
+		} `json:"rightFileStart"`
+	} `json:"threadContext"`
+	Comments []struct {
+		ID          int64  `json:"id"`
+		Content     string `json:"content"`
+		CommentType string `json:"commentType"`
+		IsDeleted   bool   `json:"isDeleted"`
+	} `json:"comments"`
+}
+
+// azureChange is a file change of a pull request iteration
+type azureChange struct {
+	ChangeType   string `json:"changeType"`
+	OriginalPath string `json:"originalPath"`
+	Item         struct {
+		Path     string `json:"path"`
+		IsFolder bool   `json:"isFolder"`
+	} `json:"item"`
+}
+
+// azureContinuationHeader holds the token of the next page of a paginated
+// Azure DevOps response
+const azureContinuationHeader = "X-Ms-Continuationtoken"
+
+// SetFileFilter sets the function reporting whether a file is reviewed: the
+// contents of the other files aren't downloaded
+func (p *AzureDevOpsProvider) SetFileFilter(reviewed func(path string) bool) {
+	p.reviewed = reviewed
+}
+
+// GetModifiedFiles returns all files modified in a pull request. Azure DevOps
+// returns no patches: they are computed from the file contents at the merge
+// base and at the head. Files that aren't reviewed (see SetFileFilter) and
+// binary files have no patch.
+func (p *AzureDevOpsProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	// The last iteration holds the head commit and the merge base
+	var iterations struct {
+		Value []struct {
+			ID              int `json:"id"`
+			SourceRefCommit struct {
+				CommitID string `json:"commitId"`
+			} `json:"sourceRefCommit"`
+			CommonRefCommit struct {
+				CommitID string `json:"commitId"`
+			} `json:"commonRefCommit"`
+		} `json:"value"`
+	}
+	if err := p.api.do(ctx, http.MethodGet, p.url(p.pullRequestPath(number)+"/iterations", nil), nil, &iterations); err != nil {
+		return nil, fmt.Errorf("failed to get PR iterations: %w", err)
+	}
+	if len(iterations.Value) == 0 {
+		return nil, fmt.Errorf("failed to get PR iterations: pull request %d has no iterations", number)
+	}
+	last := iterations.Value[len(iterations.Value)-1]
+	p.headSHA = last.SourceRefCommit.CommitID
+	baseSHA := last.CommonRefCommit.CommitID
+
+	// Compare the last iteration to the target branch (iteration 0)
+	var changes []azureChange
+	for skip := 0; ; {
+		var page struct {
+			ChangeEntries []azureChange `json:"changeEntries"`
+			NextSkip      int           `json:"nextSkip"`
+		}
+		query := url.Values{"$compareTo": {"0"}, "$top": {"2000"}, "$skip": {strconv.Itoa(skip)}}
+		path := fmt.Sprintf("%s/iterations/%d/changes", p.pullRequestPath(number), last.ID)
+		if err := p.api.do(ctx, http.MethodGet, p.url(path, query), nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list PR files: %w", err)
+		}
+		changes = append(changes, page.ChangeEntries...)
+
+		if page.NextSkip == 0 {
+			break
+		}
+		skip = page.NextSkip
+	}
+
+	// Convert to ModifiedFile
+	result := make([]ModifiedFile, 0, len(changes))
+	for _, c := range changes {
+		// Only files (not folders) have a patch
+		if c.Item.IsFolder {
+			continue
+		}
+
+		mf := ModifiedFile{
+			Path:    strings.TrimPrefix(c.Item.Path, "/"),
+			OldPath: strings.TrimPrefix(c.OriginalPath, "/"),
+			Status:  "modified",
+		}
+		switch {
+		case strings.Contains(c.ChangeType, "add"):
+			mf.Status = "added"
+		case strings.Contains(c.ChangeType, "delete"):
+			mf.Status = "deleted"
+		case strings.Contains(c.ChangeType, "rename"):
+			mf.Status = "renamed"
+		}
+		if mf.OldPath == "" {
+			mf.OldPath = mf.Path
+		}
+
+		if p.reviewed != nil && !p.reviewed(mf.Path) {
+			result = append(result, mf)
+			continue
+		}
+
+		var oldContent, newContent string
+		var err error
+		if mf.Status != "deleted" {
+			if newContent, err = p.getItem(ctx, p.repositoryPath(p.project, p.repo), mf.Path, p.headSHA); err != nil {
+				return nil, fmt.Errorf("failed to get content of %s: %w", mf.Path, err)
+			}
+		}
+		if mf.Status != "added" && !isBinary(newContent) {
+			if oldContent, err = p.getItem(ctx, p.repositoryPath(p.project, p.repo), mf.OldPath, baseSHA); err != nil {
+				return nil, fmt.Errorf("failed to get content of %s: %w", mf.OldPath, err)
+			}
+		}
+		if !isBinary(oldContent) && !isBinary(newContent) {
+			mf.Patch = diff.Unified(oldContent, newContent)
+		}
+
+		result = append(result, mf)
+	}
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file at the PR head
+func (p *AzureDevOpsProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	content, err := p.getItem(ctx, p.repositoryPath(p.project, p.repo), path, p.headSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return content, nil
+}
+
+// GetRepositoryFile returns the content of a file in another repository
+// (project/repo) at the given ref, or at the default branch if ref is empty
+func (p *AzureDevOpsProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid repository %q: expected project/repo", repository)
+	}
+
+	content, err := p.getItem(ctx, p.repositoryPath(parts[0], parts[1]), path, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return content, nil
+}
+
+// PostComment posts a comment on the pull request
+func (p *AzureDevOpsProvider) PostComment(ctx context.Context, number int, body string) error {
+	if err := p.createThread(ctx, number, body, nil); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReview creates comment threads on specific lines of the pull request
+func (p *AzureDevOpsProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	for _, c := range comments {
+		body := c.Body
+		// Add suggestion block if there's a suggested code change
+		if c.Suggestion != "" {
+			body = fmt.Sprintf("%s\n\n```suggestion\n%s\n```", c.Body, c.Suggestion)
+		}
+
+		threadContext := map[string]any{
+			"filePath":       "/" + c.Path,
+			"rightFileStart": map[string]int{"line": c.Line, "offset": 1},
+			"rightFileEnd":   map[string]int{"line": c.Line, "offset": 1},
+		}
+
+		if err := p.createThread(ctx, number, body, threadContext); err != nil {
+			// Log error but continue with other comments
+			fmt.Printf("Warning: failed to create comment for %s:%d: %v\n", c.Path, c.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// GetReviewComments returns all comments of the threads on pull request files
+func (p *AzureDevOpsProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	threads, err := p.listThreads(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR comments: %w", err)
+	}
+
+	var result []ExistingComment
+	for _, t := range threads {
+		// Only include threads on file lines
+		if t.IsDeleted || t.ThreadContext == nil || t.ThreadContext.RightFileStart == nil {
+			continue
+		}
+
+		var first int64
+		for _, c := range t.Comments {
+			if c.IsDeleted || c.CommentType == "system" {
+				continue
+			}
+
+			comment := ExistingComment{
+				ID:       azureCommentID(t.ID, c.ID),
+				ThreadID: strconv.FormatInt(t.ID, 10),
+				Resolved: t.Status != "active" && t.Status != "pending",
+				Path:     strings.TrimPrefix(t.ThreadContext.FilePath, "/"),
+				Line:     t.ThreadContext.RightFileStart.Line,
+				Body:     c.Content,
+			}
+			if first == 0 {
+				first = comment.ID
+			} else {
+				comment.InReplyTo = first
+			}
+			result = append(result, comment)
+		}
+	}
+
+	return result, nil
+}
+
+// ResolveComment resolves the thread of a pull request comment
+func (p *AzureDevOpsProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	status := map[string]string{"status": "fixed"}
+
+	path := fmt.Sprintf("%s/threads/%s", p.pullRequestPath(number), comment.ThreadID)
+	if err := p.api.do(ctx, http.MethodPatch, p.url(path, nil), status, nil); err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies to the first comment of the thread of a pull
+// request comment
+func (p *AzureDevOpsProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	root := comment.ID
+	if comment.InReplyTo != 0 {
+		root = comment.InReplyTo
+	}
+	_, parentID := splitAzureCommentID(root)
+
+	reply := map[string]any{
+		"content":         body,
+		"parentCommentId": parentID,
+		"commentType":     "text",
+	}
+
+	path := fmt.Sprintf("%s/threads/%s/comments", p.pullRequestPath(number), comment.ThreadID)
+	if err := p.api.do(ctx, http.MethodPost, p.url(path, nil), reply, nil); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateReviewComment replaces the content of a pull request comment
+func (p *AzureDevOpsProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	comment := map[string]string{"content": body}
+
+	threadID, commentID := splitAzureCommentID(id)
+	path := fmt.Sprintf("%s/threads/%d/comments/%d", p.pullRequestPath(number), threadID, commentID)
+	if err := p.api.do(ctx, http.MethodPatch, p.url(path, nil), comment, nil); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// listThreads returns every comment thread of a pull request, following the
+// continuation tokens of the paginated responses
+func (p *AzureDevOpsProvider) listThreads(ctx context.Context, number int) ([]azureThread, error) {
+	var threads []azureThread
+	query := url.Values{}
+	for {
+		resp, err := p.api.send(ctx, http.MethodGet, p.url(p.pullRequestPath(number)+"/threads", query), nil, http.Header{"Accept": {"application/json"}})
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Value []azureThread `json:"value"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		threads = append(threads, page.Value...)
+
+		token := resp.Header.Get(azureContinuationHeader)
+		if token == "" {
+			return threads, nil
+		}
+		query = url.Values{"continuationToken": {token}}
+	}
+}
+
+// createThread creates a comment thread, on the lines of threadContext if set
+func (p *AzureDevOpsProvider) createThread(ctx context.Context, number int, body string, threadContext map[string]any) error {
+	thread := map[string]any{
+		"comments": []map[string]any{
+			{"parentCommentId": 0, "content": body, "commentType": "text"},
+		},
+		"status": "active",
+	}
+	if threadContext != nil {
+		thread["threadContext"] = threadContext
+	}
+
+	return p.api.do(ctx, http.MethodPost, p.url(p.pullRequestPath(number)+"/threads", nil), thread, nil)
+}
+
+// getItem returns the content of a file of a repository at the given commit
+// or branch, or at the default branch if version is empty
+func (p *AzureDevOpsProvider) getItem(ctx context.Context, repositoryPath, path, version string) (string, error) {
+	query := url.Values{"path": {"/" + path}, "$format": {"octetStream"}}
+	if version != "" {
+		versionType := "branch"
+		if commitSHARegex.MatchString(version) {
+			versionType = "commit"
+		}
+		query.Set("versionDescriptor.version", version)
+		query.Set("versionDescriptor.versionType", versionType)
+	}
+
+	content, err := p.api.getRaw(ctx, p.url(repositoryPath+"/items", query))
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// url returns the API path with the query and the API version
+func (p *AzureDevOpsProvider) url(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", azureAPIVersion)
+	return path + "?" + query.Encode()
+}
+
+// repositoryPath returns the API path of a repository
+func (p *AzureDevOpsProvider) repositoryPath(project, repo string) string {
+	return fmt.Sprintf("/%s/_apis/git/repositories/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+// pullRequestPath returns the API path of a pull request
+func (p *AzureDevOpsProvider) pullRequestPath(number int) string {
+	return fmt.Sprintf("%s/pullRequests/%d", p.repositoryPath(p.project, p.repo), number)
+}
+
+// azureCommentID combines the ID of a thread and of one of its comments into
+// a unique comment ID
+func azureCommentID(threadID, commentID int64) int64 {
+	return threadID<<azureCommentShift | commentID
+}
+
+// splitAzureCommentID returns the thread and comment IDs combined by
+// azureCommentID
+func splitAzureCommentID(id int64) (threadID, commentID int64) {
+	return id >> azureCommentShift, id & (1<<azureCommentShift - 1)
+}
+
+// isBinary reports whether content looks binary, containing a NUL byte in its
+// first 8000 bytes as git checks
+func isBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
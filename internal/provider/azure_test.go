@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+// newAzureDevOpsTestServer starts an Azure DevOps stand-in serving pull
+// request 1 of Project/repo, recording the threads and comments posted
+func newAzureDevOpsTestServer(t *testing.T, posted *[]map[string]any) *AzureDevOpsProvider {
+	t.Helper()
+
+	const (
+		baseSHA = "1111111111111111111111111111111111111111"
+		headSHA = "2222222222222222222222222222222222222222"
+	)
+	contents := map[string]string{
+		baseSHA + "/main.go":  "package main\n\nfunc main() {}\n",
+		headSHA + "/main.go":  "package main\n\nfunc main() {\n\trun()\n}\n",
+		baseSHA + "/old.go":   "package main\n",
+		headSHA + "/new.go":   "package main\n",
+		headSHA + "/logo.png": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+	}
+
+	mux := http.NewServeMux()
+	repo := "/org/Project/_apis/git/repositories/repo"
+	base := repo + "/pullRequests/1"
+
+	record := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body["method"] = r.Method
+		body["path"] = r.URL.Path
+		*posted = append(*posted, body)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}
+
+	mux.HandleFunc(base+"/iterations", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != azureAPIVersion {
+			http.Error(w, "missing api-version", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":1,"sourceRefCommit":{"commitId":"0000000000000000000000000000000000000000"}},
+			{"id":2,"sourceRefCommit":{"commitId":"` + headSHA + `"},"commonRefCommit":{"commitId":"` + baseSHA + `"}}
+		]}`))
+	})
+	mux.HandleFunc(base+"/iterations/2/changes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skip") == "0" {
+			_, _ = w.Write([]byte(`{"changeEntries":[
+				{"changeType":"edit","item":{"path":"/main.go"}},
+				{"changeType":"add","item":{"path":"/pkg","isFolder":true}}
+			],"nextSkip":2}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"changeEntries":[
+			{"changeType":"delete","item":{"path":"/old.go"}},
+			{"changeType":"rename","originalPath":"/old.go","item":{"path":"/new.go"}},
+			{"changeType":"add","item":{"path":"/logo.png"}}
+		]}`))
+	})
+	mux.HandleFunc(repo+"/items", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("versionDescriptor.versionType") != "commit" {
+			http.Error(w, "unexpected version type", http.StatusBadRequest)
+			return
+		}
+		content, ok := contents[query.Get("versionDescriptor.version")+query.Get("path")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc(base+"/threads", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			record(w, r)
+			return
+		}
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Header().Set(azureContinuationHeader, "page2")
+			_, _ = w.Write([]byte(`{"value":[
+				{"id":8,"status":"active","comments":[{"id":1,"content":"General comment","commentType":"text"}]}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":7,"status":"fixed","threadContext":{"filePath":"/main.go","rightFileStart":{"line":4,"offset":1}},"comments":[
+				{"id":2,"content":"Earlier comment","commentType":"text","isDeleted":true},
+				{"id":3,"content":"Issue <!-- ainspector:fn:aaaaaaaaaaaa -->","commentType":"text"},
+				{"id":4,"content":"Status changed","commentType":"system"},
+				{"id":5,"parentCommentId":3,"content":"Reply","commentType":"text"}
+			]}
+		]}`))
+	})
+	mux.HandleFunc(base+"/threads/7", record)
+	mux.HandleFunc(base+"/threads/7/comments", record)
+	mux.HandleFunc(base+"/threads/7/comments/5", record)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewAzureDevOpsProvider(server.URL+"/org/", "Project", "repo", "token")
+}
+
+func TestAzureDevOpsProvider_ImplementsInterfaces(t *testing.T) {
+	var _ Provider = (*AzureDevOpsProvider)(nil)
+	var _ CommentUpdater = (*AzureDevOpsProvider)(nil)
+	var _ FileFilter = (*AzureDevOpsProvider)(nil)
+	var _ config.RemoteFetcher = (*AzureDevOpsProvider)(nil)
+}
+
+func TestAzureDevOpsProvider_GetModifiedFiles(t *testing.T) {
+	var posted []map[string]any
+	p := newAzureDevOpsTestServer(t, &posted)
+	ctx := context.Background()
+
+	if got := p.api.header.Get("Authorization"); got != "Basic OnRva2Vu" {
+		t.Errorf("Authorization = %q, want %q", got, "Basic OnRva2Vu")
+	}
+
+	files, err := p.GetModifiedFiles(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 4 {
+		t.Fatalf("expected 4 files across pages, got %+v", files)
+	}
+	wantPatch := "@@ -1,3 +1,5 @@\n package main\n \n-func main() {}\n+func main() {\n+\trun()\n+}\n"
+	if f := files[0]; f.Path != "main.go" || f.Status != "modified" || f.Patch != wantPatch {
+		t.Errorf("unexpected modified file: %+v", f)
+	}
+	if f := files[1]; f.Path != "old.go" || f.Status != "deleted" || f.Patch != "@@ -1,1 +0,0 @@\n-package main\n" {
+		t.Errorf("unexpected deleted file: %+v", f)
+	}
+	if f := files[2]; f.Path != "new.go" || f.OldPath != "old.go" || f.Status != "renamed" || f.Patch != "" {
+		t.Errorf("unexpected renamed file: %+v", f)
+	}
+	if f := files[3]; f.Path != "logo.png" || f.Status != "added" || f.Patch != "" {
+		t.Errorf("expected no patch for a binary file, got %+v", f)
+	}
+
+	content, err := p.GetFileContent(ctx, "main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n\nfunc main() {\n\trun()\n}\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestAzureDevOpsProvider_GetModifiedFiles_Filter(t *testing.T) {
+	var posted []map[string]any
+	p := newAzureDevOpsTestServer(t, &posted)
+	p.SetFileFilter(func(path string) bool { return path != "main.go" })
+
+	files, err := p.GetModifiedFiles(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 4 {
+		t.Fatalf("expected the files that aren't reviewed to be listed, got %+v", files)
+	}
+	if f := files[0]; f.Path != "main.go" || f.Patch != "" {
+		t.Errorf("expected no patch for a file that isn't reviewed, got %+v", f)
+	}
+	if f := files[1]; f.Path != "old.go" || f.Patch == "" {
+		t.Errorf("expected a patch for a reviewed file, got %+v", f)
+	}
+}
+
+func TestAzureDevOpsProvider_CreateReview(t *testing.T) {
+	var posted []map[string]any
+	p := newAzureDevOpsTestServer(t, &posted)
+
+	err := p.CreateReview(context.Background(), 1, []ReviewComment{
+		{Path: "main.go", Line: 4, Body: "Issue", Suggestion: "\tfixed()"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 thread, got %d", len(posted))
+	}
+	threadContext := posted[0]["threadContext"].(map[string]any)
+	start := threadContext["rightFileStart"].(map[string]any)
+	if threadContext["filePath"] != "/main.go" || start["line"] != float64(4) {
+		t.Errorf("unexpected thread context: %+v", threadContext)
+	}
+	comment := posted[0]["comments"].([]any)[0].(map[string]any)
+	if comment["content"] != "Issue\n\n```suggestion\n\tfixed()\n```" {
+		t.Errorf("unexpected content: %q", comment["content"])
+	}
+}
+
+func TestAzureDevOpsProvider_GetReviewComments(t *testing.T) {
+	var posted []map[string]any
+	p := newAzureDevOpsTestServer(t, &posted)
+	ctx := context.Background()
+
+	comments, err := p.GetReviewComments(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments on file lines across pages, got %+v", comments)
+	}
+	first, reply := comments[0], comments[1]
+	if first.ThreadID != "7" || first.Path != "main.go" || first.Line != 4 || first.InReplyTo != 0 || !first.Resolved {
+		t.Errorf("unexpected comment: %+v", first)
+	}
+	if reply.InReplyTo != first.ID || reply.ID == first.ID {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+
+	if err := p.ReplyToComment(ctx, 1, reply, "Fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.ResolveComment(ctx, 1, reply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.UpdateReviewComment(ctx, 1, reply.ID, "Updated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		method, path string
+	}{
+		{http.MethodPost, "/org/Project/_apis/git/repositories/repo/pullRequests/1/threads/7/comments"},
+		{http.MethodPatch, "/org/Project/_apis/git/repositories/repo/pullRequests/1/threads/7"},
+		{http.MethodPatch, "/org/Project/_apis/git/repositories/repo/pullRequests/1/threads/7/comments/5"},
+	}
+	if len(posted) != len(want) {
+		t.Fatalf("expected %d requests, got %+v", len(want), posted)
+	}
+	for i, w := range want {
+		if posted[i]["method"] != w.method || posted[i]["path"] != w.path {
+			t.Errorf("request %d = %s %s, want %s %s", i, posted[i]["method"], posted[i]["path"], w.method, w.path)
+		}
+	}
+	if posted[0]["parentCommentId"] != float64(3) {
+		t.Errorf("expected a reply to the first comment of the thread, got %+v", posted[0])
+	}
+	if posted[1]["status"] != "fixed" || posted[2]["content"] != "Updated" {
+		t.Errorf("unexpected requests: %+v", posted)
+	}
+}
@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/diff"
+)
+
+// BitbucketAPIURL is the base URL of the Bitbucket Cloud REST API 2.0
+const BitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// BitbucketProvider implements Provider for Bitbucket Cloud
+type BitbucketProvider struct {
+	api       *restClient
+	workspace string
+	repo      string
+	headSHA   string
+}
+
+// NewBitbucketProvider creates a new Bitbucket Cloud provider. With a
+// username, token is an app password; otherwise it is an access token.
+func NewBitbucketProvider(workspace, repo, username, token string) *BitbucketProvider {
+	header := http.Header{}
+	if username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+		header.Set("Authorization", "Basic "+credentials)
+	} else if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	return &BitbucketProvider{
+		api:       newRESTClient(BitbucketAPIURL, header),
+		workspace: workspace,
+		repo:      repo,
+	}
+}
+
+// bitbucketMarkerRegex matches the ainspector markers of a comment body. They
+// are HTML comments, which Bitbucket Cloud displays.
+var bitbucketMarkerRegex = regexp.MustCompile(`<!-- (ainspector:[^>]*?) -->`)
+
+// bitbucketLinkMarkerRegex matches the markers as posted on Bitbucket Cloud:
+// reference-style link definitions, which markdown hides
+var bitbucketLinkMarkerRegex = regexp.MustCompile(`\[//\]: # \((ainspector:[^)]*)\)`)
+
+// hideMarkers rewrites the markers of a comment body as link definitions
+func hideMarkers(body string) string {
+	return bitbucketMarkerRegex.ReplaceAllString(body, "[//]: # ($1)")
+}
+
+// restoreMarkers rewrites the link definitions of a comment body back into markers
+func restoreMarkers(body string) string {
+	return bitbucketLinkMarkerRegex.ReplaceAllString(body, "<!-- $1 -->")
+}
+
+// bitbucketPage is a page of a paginated Bitbucket Cloud response
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// bitbucketComment is a pull request comment of the Bitbucket Cloud API
+type bitbucketComment struct {
+	ID      int64 `json:"id"`
+	Deleted bool  `json:"deleted"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline *struct {
+		Path string `json:"path"`
+		To   *int   `json:"to"`
+	} `json:"inline"`
+	Parent *struct {
+		ID int64 `json:"id"`
+	} `json:"parent"`
+	Resolution *struct {
+		Type string `json:"type"`
+	} `json:"resolution"`
+}
+
+// listAll fetches every page of a paginated endpoint
+func listAll[T any](ctx context.Context, api *restClient, path string) ([]T, error) {
+	var all []T
+	for path != "" {
+		var page bitbucketPage[T]
+		if err := api.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Values...)
+		path = page.Next
+	}
+	return all, nil
+}
+
+// GetModifiedFiles returns all files modified in a pull request
+func (p *BitbucketProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	// Get PR details to get the head commit
+	var pr struct {
+		Source struct {
+			Commit struct {
+				Hash string `json:"hash"`
+			} `json:"commit"`
+		} `json:"source"`
+	}
+	if err := p.api.do(ctx, http.MethodGet, p.pullRequestPath(number), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	p.headSHA = pr.Source.Commit.Hash
+
+	type diffstatPath struct {
+		Path string `json:"path"`
+	}
+	stats, err := listAll[struct {
+		Status string        `json:"status"`
+		Old    *diffstatPath `json:"old"`
+		New    *diffstatPath `json:"new"`
+	}](ctx, p.api, p.pullRequestPath(number)+"/diffstat?pagelen=100")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR files: %w", err)
+	}
+
+	// The diffstat has no patches: take them from the diff of the PR
+	raw, err := p.api.getRaw(ctx, p.pullRequestPath(number)+"/diff")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	patches, err := diff.SplitFiles(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PR diff: %w", err)
+	}
+	patchByPath := make(map[string]string, len(patches))
+	for _, fp := range patches {
+		patchByPath[fp.Path] = fp.Patch
+	}
+
+	// Convert to ModifiedFile
+	result := make([]ModifiedFile, 0, len(stats))
+	for _, s := range stats {
+		mf := ModifiedFile{Status: s.Status}
+		if s.New != nil {
+			mf.Path = s.New.Path
+		}
+		if s.Old != nil {
+			mf.OldPath = s.Old.Path
+		}
+		if s.Status == "removed" {
+			mf.Status = "deleted"
+			mf.Path = mf.OldPath
+		}
+		mf.Patch = patchByPath[mf.Path]
+		result = append(result, mf)
+	}
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file at the PR head
+func (p *BitbucketProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	content, err := p.api.getRaw(ctx, fmt.Sprintf("/repositories/%s/%s/src/%s/%s", p.workspace, p.repo, p.headSHA, escapePath(path)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// GetRepositoryFile returns the content of a file in another repository
+// (workspace/repo) at the given ref, or at the main branch if ref is empty
+func (p *BitbucketProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	if len(strings.SplitN(repository, "/", 2)) != 2 {
+		return "", fmt.Errorf("invalid repository %q: expected workspace/repo", repository)
+	}
+
+	if ref == "" {
+		var repo struct {
+			MainBranch struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		}
+		if err := p.api.do(ctx, http.MethodGet, "/repositories/"+repository, nil, &repo); err != nil {
+			return "", fmt.Errorf("failed to get repository: %w", err)
+		}
+		ref = repo.MainBranch.Name
+	}
+
+	content, err := p.api.getRaw(ctx, fmt.Sprintf("/repositories/%s/src/%s/%s", repository, url.PathEscape(ref), escapePath(path)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// PostComment posts a comment on the pull request
+func (p *BitbucketProvider) PostComment(ctx context.Context, number int, body string) error {
+	comment := map[string]any{
+		"content": map[string]string{"raw": hideMarkers(body)},
+	}
+
+	if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", comment, nil); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReview creates inline comments on specific lines of the pull request.
+// Bitbucket Cloud has no suggestion blocks: suggested code is shown as a code
+// block. Markers are posted as link definitions (see hideMarkers).
+func (p *BitbucketProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	for _, c := range comments {
+		body := c.Body
+		if c.Suggestion != "" {
+			body = fmt.Sprintf("%s\n\nSuggested change:\n```\n%s\n```", c.Body, c.Suggestion)
+		}
+
+		comment := map[string]any{
+			"content": map[string]string{"raw": hideMarkers(body)},
+			"inline":  map[string]any{"path": c.Path, "to": c.Line},
+		}
+
+		if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", comment, nil); err != nil {
+			// Log error but continue with other comments
+			fmt.Printf("Warning: failed to create comment for %s:%d: %v\n", c.Path, c.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// GetReviewComments returns all inline comments on the pull request, with
+// their markers restored
+func (p *BitbucketProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	comments, err := listAll[bitbucketComment](ctx, p.api, p.pullRequestPath(number)+"/comments?pagelen=100")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR comments: %w", err)
+	}
+
+	var result []ExistingComment
+	for _, c := range comments {
+		// Only include inline comments
+		if c.Deleted || c.Inline == nil {
+			continue
+		}
+
+		comment := ExistingComment{
+			ID:       c.ID,
+			Resolved: c.Resolution != nil,
+			Path:     c.Inline.Path,
+			Body:     restoreMarkers(c.Content.Raw),
+		}
+		if c.Inline.To != nil {
+			comment.Line = *c.Inline.To
+		}
+		if c.Parent != nil {
+			comment.InReplyTo = c.Parent.ID
+		}
+		result = append(result, comment)
+	}
+
+	return result, nil
+}
+
+// ResolveComment resolves the thread of a pull request comment
+func (p *BitbucketProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	id := comment.ID
+	if comment.InReplyTo != 0 {
+		id = comment.InReplyTo
+	}
+
+	if err := p.api.do(ctx, http.MethodPost, fmt.Sprintf("%s/comments/%d/resolve", p.pullRequestPath(number), id), nil, nil); err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies in the thread of a pull request comment
+func (p *BitbucketProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	id := comment.ID
+	if comment.InReplyTo != 0 {
+		id = comment.InReplyTo
+	}
+
+	reply := map[string]any{
+		"content": map[string]string{"raw": hideMarkers(body)},
+		"parent":  map[string]int64{"id": id},
+	}
+
+	if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", reply, nil); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateReviewComment replaces the body of a pull request comment
+func (p *BitbucketProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	comment := map[string]any{
+		"content": map[string]string{"raw": hideMarkers(body)},
+	}
+
+	if err := p.api.do(ctx, http.MethodPut, fmt.Sprintf("%s/comments/%d", p.pullRequestPath(number), id), comment, nil); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// pullRequestPath returns the API path of a pull request
+func (p *BitbucketProvider) pullRequestPath(number int) string {
+	return fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", p.workspace, p.repo, number)
+}
+
+// escapePath escapes each segment of a file path for use in a URL path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/diff"
+)
+
+// BitbucketServerProvider implements Provider for Bitbucket Server and
+// Bitbucket Data Center
+type BitbucketServerProvider struct {
+	api     *restClient
+	project string
+	repo    string
+	headSHA string
+
+	// added holds the added lines of each file, as comments are anchored on
+	// added or context lines
+	added map[string]map[int]bool
+}
+
+// NewBitbucketServerProvider creates a new Bitbucket Server provider for the
+// instance at serverURL (e.g. https://bitbucket.example.com), authenticating
+// with a personal access token
+func NewBitbucketServerProvider(serverURL, project, repo, token string) *BitbucketServerProvider {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	return &BitbucketServerProvider{
+		api:     newRESTClient(strings.TrimSuffix(serverURL, "/")+"/rest/api/1.0", header),
+		project: project,
+		repo:    repo,
+	}
+}
+
+// bitbucketServerPage is a page of a paginated Bitbucket Server response
+type bitbucketServerPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// bitbucketServerComment is a pull request comment of the Bitbucket Server
+// API, with its replies
+type bitbucketServerComment struct {
+	ID             int64                    `json:"id"`
+	Version        int                      `json:"version"`
+	Text           string                   `json:"text"`
+	ThreadResolved bool                     `json:"threadResolved"`
+	Comments       []bitbucketServerComment `json:"comments"`
+}
+
+// listPages fetches every page of a paginated endpoint
+func listPages[T any](ctx context.Context, api *restClient, path string) ([]T, error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	var all []T
+	start := 0
+	for {
+		var page bitbucketServerPage[T]
+		if err := api.do(ctx, http.MethodGet, fmt.Sprintf("%s%slimit=100&start=%d", path, separator, start), nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Values...)
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			return all, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// GetModifiedFiles returns all files modified in a pull request
+func (p *BitbucketServerProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	// Get PR details to get the head commit
+	var pr struct {
+		FromRef struct {
+			LatestCommit string `json:"latestCommit"`
+		} `json:"fromRef"`
+	}
+	if err := p.api.do(ctx, http.MethodGet, p.pullRequestPath(number), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	p.headSHA = pr.FromRef.LatestCommit
+
+	type changePath struct {
+		ToString string `json:"toString"`
+	}
+	changes, err := listPages[struct {
+		Type    string      `json:"type"`
+		Path    changePath  `json:"path"`
+		SrcPath *changePath `json:"srcPath"`
+	}](ctx, p.api, p.pullRequestPath(number)+"/changes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR files: %w", err)
+	}
+
+	// The changes have no patches: take them from the diff of the PR
+	raw, err := p.api.getRaw(ctx, p.pullRequestPath(number)+".diff")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	patches, err := diff.SplitFiles(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PR diff: %w", err)
+	}
+	patchByPath := make(map[string]string, len(patches))
+	for _, fp := range patches {
+		patchByPath[fp.Path] = fp.Patch
+	}
+
+	// Convert to ModifiedFile
+	p.added = make(map[string]map[int]bool)
+	result := make([]ModifiedFile, 0, len(changes))
+	for _, c := range changes {
+		status := "modified"
+		switch c.Type {
+		case "ADD", "COPY":
+			status = "added"
+		case "DELETE":
+			status = "deleted"
+		case "MOVE":
+			status = "renamed"
+		}
+
+		mf := ModifiedFile{
+			Path:    c.Path.ToString,
+			OldPath: c.Path.ToString,
+			Status:  status,
+			Patch:   patchByPath[c.Path.ToString],
+		}
+		if c.SrcPath != nil {
+			mf.OldPath = c.SrcPath.ToString
+		}
+		result = append(result, mf)
+
+		if lines, err := diff.ParsePatch(mf.Patch); err == nil {
+			p.added[mf.Path] = make(map[int]bool, len(lines.Added))
+			for _, line := range lines.Added {
+				p.added[mf.Path][line] = true
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file at the PR head
+func (p *BitbucketServerProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	content, err := p.api.getRaw(ctx, fmt.Sprintf("%s/raw/%s?at=%s", p.repositoryPath(p.project, p.repo), escapePath(path), url.QueryEscape(p.headSHA)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// GetRepositoryFile returns the content of a file in another repository
+// (PROJECT/repo) at the given ref, or at the default branch if ref is empty
+func (p *BitbucketServerProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid repository %q: expected PROJECT/repo", repository)
+	}
+
+	endpoint := fmt.Sprintf("%s/raw/%s", p.repositoryPath(parts[0], parts[1]), escapePath(path))
+	if ref != "" {
+		endpoint += "?at=" + url.QueryEscape(ref)
+	}
+
+	content, err := p.api.getRaw(ctx, endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// PostComment posts a comment on the pull request
+func (p *BitbucketServerProvider) PostComment(ctx context.Context, number int, body string) error {
+	comment := map[string]any{"text": body}
+
+	if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", comment, nil); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReview creates comments anchored on specific lines of the pull request
+func (p *BitbucketServerProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	for _, c := range comments {
+		body := c.Body
+		// Add suggestion block if there's a suggested code change
+		if c.Suggestion != "" {
+			body = fmt.Sprintf("%s\n\n```suggestion\n%s\n```", c.Body, c.Suggestion)
+		}
+
+		lineType := "CONTEXT"
+		if p.added[c.Path][c.Line] {
+			lineType = "ADDED"
+		}
+
+		comment := map[string]any{
+			"text": body,
+			"anchor": map[string]any{
+				"path":     c.Path,
+				"line":     c.Line,
+				"lineType": lineType,
+				"fileType": "TO",
+				"diffType": "EFFECTIVE",
+			},
+		}
+
+		if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", comment, nil); err != nil {
+			// Log error but continue with other comments
+			fmt.Printf("Warning: failed to create comment for %s:%d: %v\n", c.Path, c.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// GetReviewComments returns all anchored comments on the pull request, with
+// their replies
+func (p *BitbucketServerProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	activities, err := listPages[struct {
+		Action        string                  `json:"action"`
+		CommentAction string                  `json:"commentAction"`
+		Comment       *bitbucketServerComment `json:"comment"`
+		CommentAnchor *struct {
+			Path string `json:"path"`
+			Line int    `json:"line"`
+		} `json:"commentAnchor"`
+	}](ctx, p.api, p.pullRequestPath(number)+"/activities")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR comments: %w", err)
+	}
+
+	var result []ExistingComment
+	for _, a := range activities {
+		// Only include the threads of anchored comments; replies are nested
+		if a.Action != "COMMENTED" || a.CommentAction != "ADDED" || a.Comment == nil || a.CommentAnchor == nil {
+			continue
+		}
+
+		root := a.Comment
+		var walk func(c *bitbucketServerComment)
+		walk = func(c *bitbucketServerComment) {
+			comment := ExistingComment{
+				ID:       c.ID,
+				Resolved: root.ThreadResolved,
+				Path:     a.CommentAnchor.Path,
+				Line:     a.CommentAnchor.Line,
+				Body:     c.Text,
+			}
+			if c != root {
+				comment.InReplyTo = root.ID
+			}
+			result = append(result, comment)
+
+			for i := range c.Comments {
+				walk(&c.Comments[i])
+			}
+		}
+		walk(root)
+	}
+
+	return result, nil
+}
+
+// ResolveComment resolves the thread of a pull request comment
+func (p *BitbucketServerProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	id := comment.ID
+	if comment.InReplyTo != 0 {
+		id = comment.InReplyTo
+	}
+
+	if err := p.editComment(ctx, number, id, map[string]any{"threadResolved": true}); err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies in the thread of a pull request comment
+func (p *BitbucketServerProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	id := comment.ID
+	if comment.InReplyTo != 0 {
+		id = comment.InReplyTo
+	}
+
+	reply := map[string]any{
+		"text":   body,
+		"parent": map[string]int64{"id": id},
+	}
+
+	if err := p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/comments", reply, nil); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateReviewComment replaces the text of a pull request comment
+func (p *BitbucketServerProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	if err := p.editComment(ctx, number, id, map[string]any{"text": body}); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// editComment updates the fields of a comment. Updates must carry the current
+// version of the comment, which is fetched first.
+func (p *BitbucketServerProvider) editComment(ctx context.Context, number int, id int64, fields map[string]any) error {
+	path := fmt.Sprintf("%s/comments/%d", p.pullRequestPath(number), id)
+
+	var current bitbucketServerComment
+	if err := p.api.do(ctx, http.MethodGet, path, nil, &current); err != nil {
+		return err
+	}
+
+	fields["version"] = current.Version
+	return p.api.do(ctx, http.MethodPut, path, fields, nil)
+}
+
+// repositoryPath returns the API path of a repository
+func (p *BitbucketServerProvider) repositoryPath(project, repo string) string {
+	return fmt.Sprintf("/projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+// pullRequestPath returns the API path of a pull request
+func (p *BitbucketServerProvider) pullRequestPath(number int) string {
+	return fmt.Sprintf("%s/pull-requests/%d", p.repositoryPath(p.project, p.repo), number)
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+// newBitbucketServerTestServer starts a Bitbucket Server stand-in serving pull
+// request 1 of PROJ/repo, recording the comments posted and updated
+func newBitbucketServerTestServer(t *testing.T, posted *[]map[string]any) *BitbucketServerProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	base := "/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/1"
+
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"fromRef":{"latestCommit":"abc123"}}`))
+	})
+	mux.HandleFunc(base+"/changes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start") == "0" {
+			_, _ = w.Write([]byte(`{"values":[{"type":"MODIFY","path":{"toString":"main.go"}}],"isLastPage":false,"nextPageStart":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"values":[{"type":"DELETE","path":{"toString":"old.go"}}],"isLastPage":true}`))
+	})
+	mux.HandleFunc(base+".diff", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bitbucketDiff))
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/repo/raw/pkg/main.go", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("at") != "abc123" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("package main\n"))
+	})
+	mux.HandleFunc(base+"/comments", func(w http.ResponseWriter, r *http.Request) {
+		var comment map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		*posted = append(*posted, comment)
+		_, _ = w.Write([]byte(`{"id":100,"version":0}`))
+	})
+	mux.HandleFunc(base+"/comments/10", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var comment map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&comment)
+			*posted = append(*posted, comment)
+		}
+		_, _ = w.Write([]byte(`{"id":10,"version":3}`))
+	})
+	mux.HandleFunc(base+"/activities", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"values":[
+			{"action":"COMMENTED","commentAction":"ADDED","comment":{"id":10,"text":"Issue <!-- ainspector:fn:aaaaaaaaaaaa -->","threadResolved":true,"comments":[{"id":11,"text":"Reply"}]},"commentAnchor":{"path":"main.go","line":3}},
+			{"action":"COMMENTED","commentAction":"ADDED","comment":{"id":12,"text":"General comment"}},
+			{"action":"APPROVED"}
+		],"isLastPage":true}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewBitbucketServerProvider(server.URL+"/", "PROJ", "repo", "token")
+}
+
+func TestBitbucketServerProvider_ImplementsInterfaces(t *testing.T) {
+	var _ Provider = (*BitbucketServerProvider)(nil)
+	var _ CommentUpdater = (*BitbucketServerProvider)(nil)
+	var _ config.RemoteFetcher = (*BitbucketServerProvider)(nil)
+}
+
+func TestBitbucketServerProvider_GetModifiedFiles(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketServerTestServer(t, &posted)
+	ctx := context.Background()
+
+	if got := p.api.header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer token")
+	}
+
+	files, err := p.GetModifiedFiles(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files across pages, got %+v", files)
+	}
+	if files[0].Path != "main.go" || files[0].Status != "modified" || files[0].Patch == "" {
+		t.Errorf("unexpected modified file: %+v", files[0])
+	}
+	if files[1].Path != "old.go" || files[1].Status != "deleted" {
+		t.Errorf("unexpected deleted file: %+v", files[1])
+	}
+
+	content, err := p.GetFileContent(ctx, "pkg/main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestBitbucketServerProvider_CreateReview(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketServerTestServer(t, &posted)
+	ctx := context.Background()
+
+	if _, err := p.GetModifiedFiles(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := p.CreateReview(ctx, 1, []ReviewComment{
+		{Path: "main.go", Line: 2, Body: "Issue", Suggestion: "fixed()"},
+		{Path: "main.go", Line: 3, Body: "Context"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(posted))
+	}
+	tests := []struct {
+		line     float64
+		lineType string
+	}{
+		{line: 2, lineType: "ADDED"},
+		{line: 3, lineType: "CONTEXT"},
+	}
+	for i, tt := range tests {
+		anchor := posted[i]["anchor"].(map[string]any)
+		if anchor["path"] != "main.go" || anchor["line"] != tt.line || anchor["lineType"] != tt.lineType || anchor["fileType"] != "TO" {
+			t.Errorf("unexpected anchor: %+v", anchor)
+		}
+	}
+	if text := posted[0]["text"]; text != "Issue\n\n```suggestion\nfixed()\n```" {
+		t.Errorf("unexpected text: %q", text)
+	}
+}
+
+func TestBitbucketServerProvider_GetReviewComments(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketServerTestServer(t, &posted)
+	ctx := context.Background()
+
+	comments, err := p.GetReviewComments(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 anchored comments, got %+v", comments)
+	}
+	if c := comments[0]; c.ID != 10 || c.Path != "main.go" || c.Line != 3 || c.InReplyTo != 0 || !c.Resolved {
+		t.Errorf("unexpected comment: %+v", c)
+	}
+	if c := comments[1]; c.ID != 11 || c.InReplyTo != 10 || c.Path != "main.go" || !c.Resolved {
+		t.Errorf("unexpected reply: %+v", c)
+	}
+
+	if err := p.ReplyToComment(ctx, 1, comments[1], "Fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parent := posted[0]["parent"].(map[string]any); parent["id"] != float64(10) {
+		t.Errorf("expected reply to the first comment of the thread, got %+v", parent)
+	}
+
+	if err := p.ResolveComment(ctx, 1, comments[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update := posted[1]; update["threadResolved"] != true || update["version"] != float64(3) {
+		t.Errorf("expected the thread resolved at the current version, got %+v", update)
+	}
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+const bitbucketDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,3 @@
+ package main
++
+ func main() {}
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+`
+
+// newBitbucketTestServer starts a Bitbucket Cloud stand-in serving pull request 1
+// of ws/repo, recording the comments posted
+func newBitbucketTestServer(t *testing.T, posted *[]map[string]any) *BitbucketProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	base := "/repositories/ws/repo/pullrequests/1"
+
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"source":{"commit":{"hash":"abc123"}}}`))
+	})
+	mux.HandleFunc(base+"/diffstat", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			_, _ = w.Write([]byte(`{"values":[{"status":"modified","old":{"path":"main.go"},"new":{"path":"main.go"}}],"next":"http://` + r.Host + r.URL.Path + `?page=2"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"values":[{"status":"removed","old":{"path":"old.go"},"new":null}]}`))
+	})
+	mux.HandleFunc(base+"/diff", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bitbucketDiff))
+	})
+	mux.HandleFunc("/repositories/ws/repo/src/abc123/pkg/main.go", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("package main\n"))
+	})
+	mux.HandleFunc(base+"/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var comment map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&comment)
+			*posted = append(*posted, comment)
+			_, _ = w.Write([]byte(`{"id":100}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"values":[
+			{"id":10,"content":{"raw":"Issue\n\n[//]: # (ainspector:fn:aaaaaaaaaaaa)"},"inline":{"path":"main.go","to":3}},
+			{"id":11,"content":{"raw":"Reply"},"inline":{"path":"main.go","to":3},"parent":{"id":10},"resolution":{"type":"resolved"}},
+			{"id":12,"content":{"raw":"General comment"}},
+			{"id":13,"deleted":true,"content":{"raw":""},"inline":{"path":"main.go","to":1}}
+		]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	p := NewBitbucketProvider("ws", "repo", "", "token")
+	p.api.baseURL = server.URL
+	return p
+}
+
+func TestBitbucketProvider_ImplementsInterfaces(t *testing.T) {
+	var _ Provider = (*BitbucketProvider)(nil)
+	var _ CommentUpdater = (*BitbucketProvider)(nil)
+	var _ config.RemoteFetcher = (*BitbucketProvider)(nil)
+}
+
+func TestNewBitbucketProvider_Auth(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+	}{
+		{name: "access token", want: "Bearer secret"},
+		{name: "app password", username: "user", want: "Basic dXNlcjpzZWNyZXQ="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewBitbucketProvider("ws", "repo", tt.username, "secret")
+			if got := p.api.header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitbucketProvider_GetModifiedFiles(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketTestServer(t, &posted)
+	ctx := context.Background()
+
+	files, err := p.GetModifiedFiles(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files across pages, got %+v", files)
+	}
+	if files[0].Path != "main.go" || files[0].Status != "modified" || files[0].Patch == "" {
+		t.Errorf("unexpected modified file: %+v", files[0])
+	}
+	if files[1].Path != "old.go" || files[1].Status != "deleted" {
+		t.Errorf("unexpected deleted file: %+v", files[1])
+	}
+
+	content, err := p.GetFileContent(ctx, "pkg/main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestBitbucketProvider_CreateReview(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketTestServer(t, &posted)
+
+	err := p.CreateReview(context.Background(), 1, []ReviewComment{
+		{Path: "main.go", Line: 3, Body: "Issue\n\n<!-- ainspector:fn:aaaaaaaaaaaa -->", Suggestion: "fixed()"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(posted))
+	}
+	inline := posted[0]["inline"].(map[string]any)
+	if inline["path"] != "main.go" || inline["to"] != float64(3) {
+		t.Errorf("unexpected inline position: %+v", inline)
+	}
+	raw := posted[0]["content"].(map[string]any)["raw"].(string)
+	if raw != "Issue\n\n[//]: # (ainspector:fn:aaaaaaaaaaaa)\n\nSuggested change:\n```\nfixed()\n```" {
+		t.Errorf("unexpected body: %q", raw)
+	}
+}
+
+func TestBitbucketProvider_GetReviewComments(t *testing.T) {
+	var posted []map[string]any
+	p := newBitbucketTestServer(t, &posted)
+
+	comments, err := p.GetReviewComments(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 inline comments, got %+v", comments)
+	}
+	if c := comments[0]; c.ID != 10 || c.Path != "main.go" || c.Line != 3 || c.InReplyTo != 0 {
+		t.Errorf("unexpected comment: %+v", c)
+	}
+	if body := comments[0].Body; body != "Issue\n\n<!-- ainspector:fn:aaaaaaaaaaaa -->" {
+		t.Errorf("expected the marker to be restored, got %q", body)
+	}
+	if c := comments[1]; c.InReplyTo != 10 || !c.Resolved {
+		t.Errorf("unexpected reply: %+v", c)
+	}
+
+	if err := p.ReplyToComment(context.Background(), 1, comments[1], "Fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parent := posted[0]["parent"].(map[string]any); parent["id"] != float64(10) {
+		t.Errorf("expected reply to the first comment of the thread, got %+v", parent)
+	}
+}
+
+func TestBitbucketProvider_PaginationHost(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"values":[],"next":"https://attacker.example.com/page2"}`))
+	}))
+	defer server.Close()
+
+	p := NewBitbucketProvider("ws", "repo", "", "token")
+	p.api.baseURL = server.URL
+
+	if _, err := p.GetReviewComments(context.Background(), 1); err == nil {
+		t.Error("expected an error for a next page on another host")
+	}
+	if requests != 1 {
+		t.Errorf("expected only the first page to be requested, got %d requests", requests)
+	}
+}
@@ -0,0 +1,413 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/diff"
+)
+
+// gerritRobotID identifies the robot comments of ainspector
+const gerritRobotID = "ainspector"
+
+// gerritTag tags the reviews of ainspector as automated, so that Gerrit can
+// hide them from the change log
+const gerritTag = "autogenerated:ainspector"
+
+// gerritMarkerProperty is the robot comment property holding the markers of
+// the comment, kept out of the message displayed
+const gerritMarkerProperty = "ainspector-markers"
+
+// gerritXSSIPrefix prefixes the JSON responses of Gerrit
+const gerritXSSIPrefix = ")]}'"
+
+// gerritMarkerRegex matches the ainspector markers of a comment body
+var gerritMarkerRegex = regexp.MustCompile(`\s*<!-- ainspector:[^>]*-->`)
+
+// GerritProvider implements Provider for Gerrit. The PR number is the change
+// number, reviewed at the revision of its patch set.
+type GerritProvider struct {
+	api      *restClient
+	project  string
+	revision string
+	change   int
+}
+
+// NewGerritProvider creates a new Gerrit provider for the instance at
+// serverURL (e.g. https://gerrit.example.com), reviewing the given revision of
+// changes of project. With a username, requests are authenticated with its
+// HTTP password.
+func NewGerritProvider(serverURL, project, revision, username, password string) *GerritProvider {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+
+	header := http.Header{}
+	if username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		header.Set("Authorization", "Basic "+credentials)
+		// Authenticated endpoints are prefixed with /a
+		serverURL += "/a"
+	}
+
+	if revision == "" {
+		revision = "current"
+	}
+
+	return &GerritProvider{
+		api:      newRESTClient(serverURL, header),
+		project:  project,
+		revision: revision,
+	}
+}
+
+// gerritComment is a published comment or robot comment of the Gerrit API
+type gerritComment struct {
+	ID         string            `json:"id"`
+	InReplyTo  string            `json:"in_reply_to"`
+	Line       int               `json:"line"`
+	Message    string            `json:"message"`
+	Unresolved *bool             `json:"unresolved"`
+	Updated    string            `json:"updated"`
+	Properties map[string]string `json:"properties"`
+}
+
+// gerritReview is the ReviewInput of the Gerrit API
+type gerritReview struct {
+	Message       string                      `json:"message,omitempty"`
+	Tag           string                      `json:"tag"`
+	Comments      map[string][]map[string]any `json:"comments,omitempty"`
+	RobotComments map[string][]map[string]any `json:"robot_comments,omitempty"`
+}
+
+// GetModifiedFiles returns all files modified in the revision of a change
+func (p *GerritProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	p.change = number
+
+	var files map[string]struct {
+		Status  string `json:"status"`
+		OldPath string `json:"old_path"`
+	}
+	if err := p.get(ctx, p.revisionPath(number)+"/files", &files); err != nil {
+		return nil, fmt.Errorf("failed to list change files: %w", err)
+	}
+
+	// The files have no patches: take them from the patch of the revision,
+	// which is base64 encoded
+	encoded, err := p.api.getRaw(ctx, p.revisionPath(number)+"/patch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change patch: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode change patch: %w", err)
+	}
+	// Skip the commit message of the formatted patch
+	if i := bytes.Index(raw, []byte("diff --git ")); i >= 0 {
+		raw = raw[i:]
+	}
+	patches, err := diff.SplitFiles(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse change patch: %w", err)
+	}
+	patchByPath := make(map[string]string, len(patches))
+	for _, fp := range patches {
+		patchByPath[fp.Path] = fp.Patch
+	}
+
+	// Convert to ModifiedFile
+	result := make([]ModifiedFile, 0, len(files))
+	for path, f := range files {
+		// Skip the magic files (/COMMIT_MSG, /MERGE_LIST)
+		if strings.HasPrefix(path, "/") {
+			continue
+		}
+
+		mf := ModifiedFile{
+			Path:    path,
+			OldPath: path,
+			Status:  "modified",
+			Patch:   patchByPath[path],
+		}
+		switch f.Status {
+		case "A", "C":
+			mf.Status = "added"
+		case "D":
+			mf.Status = "deleted"
+		case "R":
+			mf.Status = "renamed"
+		}
+		if f.OldPath != "" {
+			mf.OldPath = f.OldPath
+		}
+		result = append(result, mf)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file at the reviewed revision
+func (p *GerritProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	return p.getContent(ctx, fmt.Sprintf("%s/files/%s/content", p.revisionPath(p.change), url.PathEscape(path)))
+}
+
+// GetRepositoryFile returns the content of a file in another project at the
+// given commit or branch, or at the default branch if ref is empty
+func (p *GerritProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	project := url.PathEscape(repository)
+
+	if ref == "" {
+		var head string
+		if err := p.get(ctx, fmt.Sprintf("/projects/%s/HEAD", project), &head); err != nil {
+			return "", fmt.Errorf("failed to get project: %w", err)
+		}
+		ref = head
+	}
+
+	kind := "branches"
+	if commitSHARegex.MatchString(ref) {
+		kind = "commits"
+	}
+
+	return p.getContent(ctx, fmt.Sprintf("/projects/%s/%s/%s/files/%s/content", project, kind, url.PathEscape(ref), url.PathEscape(path)))
+}
+
+// PostComment posts a message on the change
+func (p *GerritProvider) PostComment(ctx context.Context, number int, body string) error {
+	if err := p.review(ctx, number, gerritReview{Message: body}); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReview creates a review with comments on specific lines. Comments
+// are robot comments, whose markers are kept in a property rather than in the
+// message displayed. Suggested code changes are fix suggestions.
+func (p *GerritProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	review := gerritReview{
+		RobotComments: make(map[string][]map[string]any),
+	}
+	for _, c := range comments {
+		comment := p.robotComment(c.Line, c.Body)
+		if c.Suggestion != "" {
+			comment["fix_suggestions"] = []map[string]any{{
+				"description": "Suggested change",
+				"replacements": []map[string]any{{
+					"path": c.Path,
+					// Replace the whole commented line
+					"range": map[string]int{
+						"start_line":      c.Line,
+						"start_character": 0,
+						"end_line":        c.Line + 1,
+						"end_character":   0,
+					},
+					"replacement": c.Suggestion + "\n",
+				}},
+			}}
+		}
+		review.RobotComments[c.Path] = append(review.RobotComments[c.Path], comment)
+	}
+
+	if err := p.review(ctx, number, review); err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return nil
+}
+
+// GetReviewComments returns all published comments and robot comments of the
+// change. Gerrit comment IDs are strings: they are hashed into the comment
+// IDs, and kept as the thread IDs.
+func (p *GerritProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	var comments, robotComments map[string][]gerritComment
+	if err := p.get(ctx, p.changePath(number)+"/comments", &comments); err != nil {
+		return nil, fmt.Errorf("failed to list change comments: %w", err)
+	}
+	if err := p.get(ctx, p.changePath(number)+"/robotcomments", &robotComments); err != nil {
+		return nil, fmt.Errorf("failed to list change robot comments: %w", err)
+	}
+
+	type located struct {
+		gerritComment
+		path string
+	}
+	byID := make(map[string]located)
+	var all []located
+	for _, set := range []map[string][]gerritComment{comments, robotComments} {
+		for path, pathComments := range set {
+			// Skip the comments on the magic files (/COMMIT_MSG, /PATCHSET_LEVEL)
+			if strings.HasPrefix(path, "/") {
+				continue
+			}
+			for _, c := range pathComments {
+				if markers := c.Properties[gerritMarkerProperty]; markers != "" {
+					c.Message += markers
+				}
+				byID[c.ID] = located{c, path}
+				all = append(all, located{c, path})
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Updated < all[j].Updated })
+
+	// Find the root of each thread, and whether its last comment resolved it
+	root := func(c gerritComment) string {
+		for c.InReplyTo != "" {
+			parent, ok := byID[c.InReplyTo]
+			if !ok {
+				break
+			}
+			c = parent.gerritComment
+		}
+		return c.ID
+	}
+	resolved := make(map[string]bool)
+	for _, c := range all {
+		// Robot comments have no resolved state: they are unresolved
+		resolved[root(c.gerritComment)] = c.Unresolved != nil && !*c.Unresolved
+	}
+
+	result := make([]ExistingComment, 0, len(all))
+	for _, c := range all {
+		rootID := root(c.gerritComment)
+		comment := ExistingComment{
+			ID:       gerritCommentID(c.ID),
+			ThreadID: rootID,
+			Resolved: resolved[rootID],
+			Path:     c.path,
+			Line:     byID[rootID].Line,
+			Body:     c.Message,
+		}
+		if rootID != c.ID {
+			comment.InReplyTo = gerritCommentID(rootID)
+		}
+		result = append(result, comment)
+	}
+
+	return result, nil
+}
+
+// ResolveComment resolves the thread of a comment, replying "Done" as the
+// Gerrit UI does
+func (p *GerritProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	if err := p.reply(ctx, number, comment, "Done", false); err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies in the thread of a comment, keeping it unresolved
+func (p *GerritProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	if err := p.reply(ctx, number, comment, body, true); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// reply posts a reply to the first comment of the thread of comment. Replies
+// holding markers are robot comments, keeping the markers out of the message.
+func (p *GerritProvider) reply(ctx context.Context, number int, comment ExistingComment, body string, unresolved bool) error {
+	if gerritMarkerRegex.MatchString(body) {
+		reply := p.robotComment(comment.Line, body)
+		reply["in_reply_to"] = comment.ThreadID
+		return p.review(ctx, number, gerritReview{
+			RobotComments: map[string][]map[string]any{comment.Path: {reply}},
+		})
+	}
+
+	review := gerritReview{
+		Comments: map[string][]map[string]any{
+			comment.Path: {{
+				"in_reply_to": comment.ThreadID,
+				"line":        comment.Line,
+				"message":     body,
+				"unresolved":  unresolved,
+			}},
+		},
+	}
+
+	return p.review(ctx, number, review)
+}
+
+// robotComment returns a robot comment on line, with the markers of body moved
+// from the message to a property
+func (p *GerritProvider) robotComment(line int, body string) map[string]any {
+	return map[string]any{
+		"line":         line,
+		"message":      gerritMarkerRegex.ReplaceAllString(body, ""),
+		"robot_id":     gerritRobotID,
+		"robot_run_id": p.revision,
+		"properties": map[string]string{
+			gerritMarkerProperty: strings.Join(gerritMarkerRegex.FindAllString(body, -1), ""),
+		},
+	}
+}
+
+// review posts a review on the revision of a change
+func (p *GerritProvider) review(ctx context.Context, number int, review gerritReview) error {
+	review.Tag = gerritTag
+	return p.api.do(ctx, http.MethodPost, p.revisionPath(number)+"/review", review, nil)
+}
+
+// get sends a GET request and decodes the JSON response into result, without
+// the prefix Gerrit adds against XSSI
+func (p *GerritProvider) get(ctx context.Context, path string, result any) error {
+	data, err := p.api.getRaw(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	data = bytes.TrimPrefix(data, []byte(gerritXSSIPrefix))
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// getContent returns the content of a file, which Gerrit returns base64
+// encoded
+func (p *GerritProvider) getContent(ctx context.Context, path string) (string, error) {
+	encoded, err := p.api.getRaw(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// changePath returns the API path of a change
+func (p *GerritProvider) changePath(number int) string {
+	return fmt.Sprintf("/changes/%s~%d", url.PathEscape(p.project), number)
+}
+
+// revisionPath returns the API path of the reviewed revision of a change
+func (p *GerritProvider) revisionPath(number int) string {
+	return fmt.Sprintf("%s/revisions/%s", p.changePath(number), p.revision)
+}
+
+// gerritCommentID hashes a Gerrit comment ID into a comment ID
+func gerritCommentID(id string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return int64(h.Sum64() &^ (1 << 63))
+}
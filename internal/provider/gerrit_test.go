@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+const gerritPatch = `From abc123 Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH] Change main
+
+---
+ main.go | 1 +
+ 1 file changed, 1 insertion(+)
+
+` + bitbucketDiff
+
+// newGerritTestServer starts a Gerrit stand-in serving change 42 of
+// platform/tools, recording the reviews posted
+func newGerritTestServer(t *testing.T, posted *[]map[string]any) *GerritProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	change := "/a/changes/platform%2Ftools~42"
+	revision := change + "/revisions/abc123"
+
+	mux.HandleFunc(revision+"/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(gerritXSSIPrefix + `
+{"/COMMIT_MSG":{"status":"A"},"main.go":{},"old.go":{"status":"D"}}`))
+	})
+	mux.HandleFunc(revision+"/patch", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(gerritPatch))))
+	})
+	mux.HandleFunc(revision+"/files/pkg%2Fmain.go/content", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("package main\n"))))
+	})
+	mux.HandleFunc(revision+"/review", func(w http.ResponseWriter, r *http.Request) {
+		var review map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		*posted = append(*posted, review)
+		_, _ = w.Write([]byte(gerritXSSIPrefix + `{}`))
+	})
+	mux.HandleFunc(change+"/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(gerritXSSIPrefix + `
+{
+	"main.go": [
+		{"id":"a1","line":3,"message":"Issue <!-- ainspector:fn:aaaaaaaaaaaa -->","unresolved":true,"updated":"2024-01-01 10:00:00.000000000"},
+		{"id":"a2","in_reply_to":"a1","line":3,"message":"Done","unresolved":false,"updated":"2024-01-02 10:00:00.000000000"},
+		{"id":"b2","in_reply_to":"b1","line":5,"message":"Will do","unresolved":true,"updated":"2024-01-02 11:00:00.000000000"}
+	],
+	"/PATCHSET_LEVEL": [
+		{"id":"c1","message":"General comment","updated":"2024-01-01 09:00:00.000000000"}
+	]
+}`))
+	})
+	mux.HandleFunc(change+"/robotcomments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(gerritXSSIPrefix + `
+{"main.go":[{"id":"b1","line":5,"message":"Robot issue","robot_id":"ainspector","properties":{"ainspector-markers":"\n\n<!-- ainspector:fn:bbbbbbbbbbbb -->"},"updated":"2024-01-01 11:00:00.000000000"}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewGerritProvider(server.URL+"/", "platform/tools", "abc123", "bot", "secret")
+}
+
+func TestGerritProvider_ImplementsInterfaces(t *testing.T) {
+	var _ Provider = (*GerritProvider)(nil)
+	var _ config.RemoteFetcher = (*GerritProvider)(nil)
+}
+
+func TestNewGerritProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		username     string
+		revision     string
+		wantBaseURL  string
+		wantRevision string
+	}{
+		{name: "authenticated", username: "bot", revision: "abc123", wantBaseURL: "https://gerrit.example.com/a", wantRevision: "abc123"},
+		{name: "anonymous", wantBaseURL: "https://gerrit.example.com", wantRevision: "current"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewGerritProvider("https://gerrit.example.com/", "project", tt.revision, tt.username, "secret")
+			if p.api.baseURL != tt.wantBaseURL || p.revision != tt.wantRevision {
+				t.Errorf("got %q at %q, want %q at %q", p.api.baseURL, p.revision, tt.wantBaseURL, tt.wantRevision)
+			}
+		})
+	}
+}
+
+func TestGerritProvider_GetModifiedFiles(t *testing.T) {
+	var posted []map[string]any
+	p := newGerritTestServer(t, &posted)
+	ctx := context.Background()
+
+	files, err := p.GetModifiedFiles(ctx, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files without the commit message, got %+v", files)
+	}
+	if f := files[0]; f.Path != "main.go" || f.Status != "modified" || !strings.HasPrefix(f.Patch, "@@ -1,2 +1,3 @@") {
+		t.Errorf("unexpected modified file: %+v", f)
+	}
+	if f := files[1]; f.Path != "old.go" || f.Status != "deleted" || f.Patch == "" {
+		t.Errorf("unexpected deleted file: %+v", f)
+	}
+
+	content, err := p.GetFileContent(ctx, "pkg/main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestGerritProvider_CreateReview(t *testing.T) {
+	var posted []map[string]any
+	p := newGerritTestServer(t, &posted)
+
+	err := p.CreateReview(context.Background(), 42, []ReviewComment{
+		{Path: "main.go", Line: 2, Body: "Issue\n\n<!-- ainspector:fn:aaaaaaaaaaaa -->"},
+		{Path: "main.go", Line: 3, Body: "Fixable\n\n<!-- ainspector:fn:bbbbbbbbbbbb -->", Suggestion: "fixed()"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 review, got %d", len(posted))
+	}
+	review := posted[0]
+	if review["tag"] != gerritTag {
+		t.Errorf("tag = %v, want %q", review["tag"], gerritTag)
+	}
+
+	if _, ok := review["comments"]; ok {
+		t.Errorf("expected findings to be robot comments only, got %+v", review["comments"])
+	}
+
+	robots := review["robot_comments"].(map[string]any)["main.go"].([]any)
+	if len(robots) != 2 {
+		t.Fatalf("expected 2 robot comments, got %+v", robots)
+	}
+
+	plain := robots[0].(map[string]any)
+	if plain["line"] != float64(2) || plain["message"] != "Issue" || plain["robot_id"] != gerritRobotID || plain["fix_suggestions"] != nil {
+		t.Errorf("unexpected robot comment: %+v", plain)
+	}
+	if markers := plain["properties"].(map[string]any)[gerritMarkerProperty]; markers != "\n\n<!-- ainspector:fn:aaaaaaaaaaaa -->" {
+		t.Errorf("unexpected markers: %q", markers)
+	}
+
+	robot := robots[1].(map[string]any)
+	if robot["message"] != "Fixable" || robot["robot_id"] != gerritRobotID {
+		t.Errorf("unexpected robot comment: %+v", robot)
+	}
+	if markers := robot["properties"].(map[string]any)[gerritMarkerProperty]; markers != "\n\n<!-- ainspector:fn:bbbbbbbbbbbb -->" {
+		t.Errorf("unexpected markers: %q", markers)
+	}
+	replacement := robot["fix_suggestions"].([]any)[0].(map[string]any)["replacements"].([]any)[0].(map[string]any)
+	rng := replacement["range"].(map[string]any)
+	if replacement["replacement"] != "fixed()\n" || rng["start_line"] != float64(3) || rng["end_line"] != float64(4) {
+		t.Errorf("unexpected replacement: %+v", replacement)
+	}
+}
+
+func TestGerritProvider_GetReviewComments(t *testing.T) {
+	var posted []map[string]any
+	p := newGerritTestServer(t, &posted)
+	ctx := context.Background()
+
+	comments, err := p.GetReviewComments(ctx, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments on files, got %+v", comments)
+	}
+	byThread := make(map[string][]ExistingComment)
+	for _, c := range comments {
+		byThread[c.ThreadID] = append(byThread[c.ThreadID], c)
+	}
+
+	resolved := byThread["a1"]
+	if len(resolved) != 2 || !resolved[0].Resolved || resolved[1].InReplyTo != resolved[0].ID || resolved[0].InReplyTo != 0 {
+		t.Errorf("unexpected resolved thread: %+v", resolved)
+	}
+
+	robot := byThread["b1"]
+	if len(robot) != 2 || robot[0].Resolved || robot[0].Line != 5 || robot[1].InReplyTo != robot[0].ID {
+		t.Errorf("unexpected robot thread: %+v", robot)
+	}
+	if robot[0].Body != "Robot issue\n\n<!-- ainspector:fn:bbbbbbbbbbbb -->" {
+		t.Errorf("expected the markers restored from the properties, got %q", robot[0].Body)
+	}
+
+	if err := p.ResolveComment(ctx, 42, robot[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reply := posted[0]["comments"].(map[string]any)["main.go"].([]any)[0].(map[string]any)
+	if reply["in_reply_to"] != "b1" || reply["message"] != "Done" || reply["unresolved"] != false {
+		t.Errorf("unexpected resolving reply: %+v", reply)
+	}
+
+	if err := p.ReplyToComment(ctx, 42, robot[1], "Fixed\n\n<!-- ainspector:resolved -->"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	marked := posted[1]["robot_comments"].(map[string]any)["main.go"].([]any)[0].(map[string]any)
+	if marked["in_reply_to"] != "b1" || marked["message"] != "Fixed" {
+		t.Errorf("unexpected reply: %+v", marked)
+	}
+	if markers := marked["properties"].(map[string]any)[gerritMarkerProperty]; markers != "\n\n<!-- ainspector:resolved -->" {
+		t.Errorf("expected the markers of the reply in its properties, got %q", markers)
+	}
+}
@@ -0,0 +1,344 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/diff"
+)
+
+// giteaPageSize is the page size of paginated Gitea requests, the default
+// maximum of Gitea instances
+const giteaPageSize = 50
+
+// GiteaProvider implements Provider for Gitea and Forgejo
+type GiteaProvider struct {
+	api     *restClient
+	owner   string
+	repo    string
+	headSHA string
+}
+
+// NewGiteaProvider creates a new Gitea provider for the instance at serverURL
+// (e.g. https://gitea.example.com)
+func NewGiteaProvider(serverURL, owner, repo, token string) *GiteaProvider {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "token "+token)
+	}
+
+	return &GiteaProvider{
+		api:   newRESTClient(strings.TrimSuffix(serverURL, "/")+"/api/v1", header),
+		owner: owner,
+		repo:  repo,
+	}
+}
+
+// giteaReplyPrefix starts the marker linking a reply, posted as a pull
+// request comment, to the review comment it replies to
+const giteaReplyPrefix = "<!-- ainspector:reply:"
+
+// giteaReplyRegex matches the marker of a reply, capturing the ID of the
+// review comment
+var giteaReplyRegex = regexp.MustCompile(`<!-- ainspector:reply:(\d+) -->`)
+
+// giteaMarkerRegex matches the ainspector markers of a comment body
+var giteaMarkerRegex = regexp.MustCompile(`<!-- ainspector:[^>]*-->`)
+
+// giteaReviewComment is a pull request review comment of the Gitea API
+type giteaReviewComment struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	Path     string `json:"path"`
+	Position int    `json:"position"`
+	Resolver *struct {
+		ID int64 `json:"id"`
+	} `json:"resolver"`
+}
+
+// listGiteaPages fetches every page of a paginated endpoint
+func listGiteaPages[T any](ctx context.Context, api *restClient, path string) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		var values []T
+		if err := api.do(ctx, http.MethodGet, fmt.Sprintf("%s?limit=%d&page=%d", path, giteaPageSize, page), nil, &values); err != nil {
+			return nil, err
+		}
+		all = append(all, values...)
+
+		if len(values) < giteaPageSize {
+			return all, nil
+		}
+	}
+}
+
+// GetModifiedFiles returns all files modified in a pull request
+func (p *GiteaProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	// Get PR details to get the head commit
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := p.api.do(ctx, http.MethodGet, p.pullRequestPath(number), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	p.headSHA = pr.Head.SHA
+
+	files, err := listGiteaPages[struct {
+		Filename         string `json:"filename"`
+		PreviousFilename string `json:"previous_filename"`
+		Status           string `json:"status"`
+	}](ctx, p.api, p.pullRequestPath(number)+"/files")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR files: %w", err)
+	}
+
+	// The files have no patches: take them from the diff of the PR
+	raw, err := p.api.getRaw(ctx, p.pullRequestPath(number)+".diff")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	patches, err := diff.SplitFiles(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PR diff: %w", err)
+	}
+	patchByPath := make(map[string]string, len(patches))
+	for _, fp := range patches {
+		patchByPath[fp.Path] = fp.Patch
+	}
+
+	// Convert to ModifiedFile
+	result := make([]ModifiedFile, 0, len(files))
+	for _, f := range files {
+		mf := ModifiedFile{
+			Path:    f.Filename,
+			OldPath: f.PreviousFilename,
+			Status:  f.Status,
+			Patch:   patchByPath[f.Filename],
+		}
+		if mf.Status == "changed" {
+			mf.Status = "modified"
+		}
+		if mf.OldPath == "" {
+			mf.OldPath = mf.Path
+		}
+		result = append(result, mf)
+	}
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file at the PR head
+func (p *GiteaProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	return p.GetRepositoryFile(ctx, p.owner+"/"+p.repo, path, p.headSHA)
+}
+
+// GetRepositoryFile returns the content of a file in another repository
+// (owner/repo) at the given ref, or at the default branch if ref is empty
+func (p *GiteaProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid repository %q: expected owner/repo", repository)
+	}
+
+	endpoint := fmt.Sprintf("%s/raw/%s", p.repositoryPath(parts[0], parts[1]), escapePath(path))
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	content, err := p.api.getRaw(ctx, endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// PostComment posts a comment on the pull request
+func (p *GiteaProvider) PostComment(ctx context.Context, number int, body string) error {
+	comment := map[string]string{"body": body}
+
+	path := fmt.Sprintf("%s/issues/%d/comments", p.repositoryPath(p.owner, p.repo), number)
+	if err := p.api.do(ctx, http.MethodPost, path, comment, nil); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReview creates a review with inline comments on specific lines.
+// Gitea has no suggestion blocks: suggested code is shown as a code block.
+func (p *GiteaProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	giteaComments := make([]map[string]any, 0, len(comments))
+	for _, c := range comments {
+		body := c.Body
+		if c.Suggestion != "" {
+			body = fmt.Sprintf("%s\n\nSuggested change:\n```\n%s\n```", c.Body, c.Suggestion)
+		}
+
+		giteaComments = append(giteaComments, map[string]any{
+			"path":         c.Path,
+			"new_position": c.Line,
+			"body":         body,
+		})
+	}
+
+	if err := p.createReview(ctx, number, giteaComments); err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return nil
+}
+
+// GetReviewComments returns all review comments on the pull request. Gitea
+// groups the comments on the same line into a conversation, so the first of
+// them is the one the others reply to. The pull request comments posted by
+// ReplyToComment are returned as replies to the comment they quote.
+func (p *GiteaProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	reviews, err := listGiteaPages[struct {
+		ID int64 `json:"id"`
+	}](ctx, p.api, p.pullRequestPath(number)+"/reviews")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR reviews: %w", err)
+	}
+
+	var comments []giteaReviewComment
+	for _, r := range reviews {
+		var reviewComments []giteaReviewComment
+		if err := p.api.do(ctx, http.MethodGet, fmt.Sprintf("%s/reviews/%d/comments", p.pullRequestPath(number), r.ID), nil, &reviewComments); err != nil {
+			return nil, fmt.Errorf("failed to list PR comments: %w", err)
+		}
+		comments = append(comments, reviewComments...)
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+
+	type position struct {
+		path string
+		line int
+	}
+	firsts := make(map[position]int64)
+
+	result := make([]ExistingComment, 0, len(comments))
+	for _, c := range comments {
+		comment := ExistingComment{
+			ID:       c.ID,
+			Resolved: c.Resolver != nil,
+			Path:     c.Path,
+			Line:     c.Position,
+			Body:     c.Body,
+		}
+		pos := position{c.Path, c.Position}
+		if first, ok := firsts[pos]; ok {
+			comment.InReplyTo = first
+		} else {
+			firsts[pos] = c.ID
+		}
+		result = append(result, comment)
+	}
+
+	issueComments, err := listGiteaPages[struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}](ctx, p.api, fmt.Sprintf("%s/issues/%d/comments", p.repositoryPath(p.owner, p.repo), number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR comments: %w", err)
+	}
+
+	roots := make(map[int64]ExistingComment, len(result))
+	for _, c := range result {
+		roots[c.ID] = c
+	}
+	for _, c := range issueComments {
+		match := giteaReplyRegex.FindStringSubmatch(c.Body)
+		if match == nil {
+			continue
+		}
+		id, _ := strconv.ParseInt(match[1], 10, 64)
+		root, ok := roots[id]
+		if !ok {
+			continue
+		}
+		result = append(result, ExistingComment{
+			ID:        c.ID,
+			InReplyTo: root.ID,
+			Resolved:  root.Resolved,
+			Path:      root.Path,
+			Line:      root.Line,
+			Body:      c.Body,
+		})
+	}
+
+	return result, nil
+}
+
+// ResolveComment does nothing and returns ErrResolveUnsupported: the Gitea
+// API cannot resolve conversations, so comments are only marked as fixed by
+// the reply
+func (p *GiteaProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	return ErrResolveUnsupported
+}
+
+// ReplyToComment replies to a pull request comment. The Gitea API cannot
+// reply in a conversation, and a new review comment would need the line to
+// still be in the diff: the reply is a pull request comment quoting the
+// original one, linked to it by a marker (see GetReviewComments).
+func (p *GiteaProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	root := comment.ID
+	if comment.InReplyTo != 0 {
+		root = comment.InReplyTo
+	}
+
+	quote := strings.TrimSpace(giteaMarkerRegex.ReplaceAllString(comment.Body, ""))
+	reply := fmt.Sprintf("> **%s:%d**\n>\n> %s\n\n%s\n\n%s%d -->",
+		comment.Path, comment.Line, strings.ReplaceAll(quote, "\n", "\n> "), body, giteaReplyPrefix, root)
+
+	if err := p.PostComment(ctx, number, reply); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateReviewComment replaces the body of a pull request review comment
+func (p *GiteaProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	comment := map[string]string{"body": body}
+
+	path := fmt.Sprintf("%s/issues/comments/%d", p.repositoryPath(p.owner, p.repo), id)
+	if err := p.api.do(ctx, http.MethodPatch, path, comment, nil); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// createReview submits a review made of the given comments
+func (p *GiteaProvider) createReview(ctx context.Context, number int, comments []map[string]any) error {
+	review := map[string]any{
+		"commit_id": p.headSHA,
+		"event":     "COMMENT",
+		"comments":  comments,
+	}
+
+	return p.api.do(ctx, http.MethodPost, p.pullRequestPath(number)+"/reviews", review, nil)
+}
+
+// repositoryPath returns the API path of a repository
+func (p *GiteaProvider) repositoryPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+// pullRequestPath returns the API path of a pull request
+func (p *GiteaProvider) pullRequestPath(number int) string {
+	return fmt.Sprintf("%s/pulls/%d", p.repositoryPath(p.owner, p.repo), number)
+}
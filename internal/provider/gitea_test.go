@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+// newGiteaTestServer starts a Gitea stand-in serving pull request 1 of
+// owner/repo, recording the reviews posted
+func newGiteaTestServer(t *testing.T, posted *[]map[string]any) *GiteaProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	base := "/api/v1/repos/owner/repo/pulls/1"
+
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number":1,"head":{"sha":"abc123"}}`))
+	})
+	mux.HandleFunc(base+"/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			_, _ = w.Write([]byte(`[{"filename":"old.go","status":"deleted"}]`))
+			return
+		}
+		// A full first page, to check that the next one is fetched
+		files := make([]map[string]string, giteaPageSize)
+		for i := range files {
+			files[i] = map[string]string{"filename": fmt.Sprintf("file%d.go", i), "status": "added"}
+		}
+		files[0] = map[string]string{"filename": "main.go", "status": "changed"}
+		_ = json.NewEncoder(w).Encode(files)
+	})
+	mux.HandleFunc(base+".diff", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bitbucketDiff))
+	})
+	mux.HandleFunc("/api/v1/repos/owner/repo/raw/pkg/main.go", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "abc123" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("package main\n"))
+	})
+	mux.HandleFunc(base+"/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var review map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&review)
+			*posted = append(*posted, review)
+			_, _ = w.Write([]byte(`{"id":100}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1},{"id":2}]`))
+	})
+	mux.HandleFunc(base+"/reviews/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":10,"body":"Issue <!-- ainspector:fn:aaaaaaaaaaaa -->","path":"main.go","position":3,"resolver":{"id":1}}]`))
+	})
+	mux.HandleFunc(base+"/reviews/2/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":12,"body":"Other","path":"main.go","position":1},{"id":11,"body":"Reply","path":"main.go","position":3}]`))
+	})
+
+	mux.HandleFunc("/api/v1/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var comment map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&comment)
+			*posted = append(*posted, comment)
+			_, _ = w.Write([]byte(`{"id":100}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"id":13,"body":"General comment"},
+			{"id":14,"body":"> Issue\n\nFixed in abc1234\n\n<!-- ainspector:reply:10 -->"},
+			{"id":15,"body":"Fixed\n\n<!-- ainspector:reply:99 -->"}
+		]`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewGiteaProvider(server.URL+"/", "owner", "repo", "token")
+}
+
+func TestGiteaProvider_ImplementsInterfaces(t *testing.T) {
+	var _ Provider = (*GiteaProvider)(nil)
+	var _ CommentUpdater = (*GiteaProvider)(nil)
+	var _ config.RemoteFetcher = (*GiteaProvider)(nil)
+}
+
+func TestGiteaProvider_GetModifiedFiles(t *testing.T) {
+	var posted []map[string]any
+	p := newGiteaTestServer(t, &posted)
+	ctx := context.Background()
+
+	if got := p.api.header.Get("Authorization"); got != "token token" {
+		t.Errorf("Authorization = %q, want %q", got, "token token")
+	}
+
+	files, err := p.GetModifiedFiles(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != giteaPageSize+1 {
+		t.Fatalf("expected %d files across pages, got %d", giteaPageSize+1, len(files))
+	}
+	if f := files[0]; f.Path != "main.go" || f.OldPath != "main.go" || f.Status != "modified" || f.Patch == "" {
+		t.Errorf("unexpected modified file: %+v", f)
+	}
+	if f := files[giteaPageSize]; f.Path != "old.go" || f.Status != "deleted" {
+		t.Errorf("unexpected deleted file: %+v", f)
+	}
+
+	content, err := p.GetFileContent(ctx, "pkg/main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestGiteaProvider_CreateReview(t *testing.T) {
+	var posted []map[string]any
+	p := newGiteaTestServer(t, &posted)
+	ctx := context.Background()
+
+	if _, err := p.GetModifiedFiles(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := p.CreateReview(ctx, 1, []ReviewComment{
+		{Path: "main.go", Line: 2, Body: "Issue", Suggestion: "fixed()"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 review, got %d", len(posted))
+	}
+	if posted[0]["commit_id"] != "abc123" || posted[0]["event"] != "COMMENT" {
+		t.Errorf("unexpected review: %+v", posted[0])
+	}
+	comment := posted[0]["comments"].([]any)[0].(map[string]any)
+	if comment["path"] != "main.go" || comment["new_position"] != float64(2) {
+		t.Errorf("unexpected comment position: %+v", comment)
+	}
+	if comment["body"] != "Issue\n\nSuggested change:\n```\nfixed()\n```" {
+		t.Errorf("unexpected body: %q", comment["body"])
+	}
+}
+
+func TestGiteaProvider_GetReviewComments(t *testing.T) {
+	var posted []map[string]any
+	p := newGiteaTestServer(t, &posted)
+	ctx := context.Background()
+
+	comments, err := p.GetReviewComments(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments, got %+v", comments)
+	}
+	if c := comments[0]; c.ID != 10 || c.Path != "main.go" || c.Line != 3 || c.InReplyTo != 0 || !c.Resolved {
+		t.Errorf("unexpected comment: %+v", c)
+	}
+	if c := comments[1]; c.ID != 11 || c.InReplyTo != 10 || c.Resolved {
+		t.Errorf("expected a reply in the conversation of the same line, got %+v", c)
+	}
+	if c := comments[2]; c.ID != 12 || c.InReplyTo != 0 {
+		t.Errorf("expected a separate conversation, got %+v", c)
+	}
+
+	if c := comments[3]; c.ID != 14 || c.InReplyTo != 10 || c.Path != "main.go" || c.Line != 3 || !c.Resolved {
+		t.Errorf("expected a pull request comment replying to comment 10, got %+v", c)
+	}
+
+	if err := p.ReplyToComment(ctx, 1, comments[0], "Fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "> **main.go:3**\n>\n> Issue\n\nFixed\n\n<!-- ainspector:reply:10 -->"
+	if len(posted) != 1 || posted[0]["body"] != want {
+		t.Errorf("expected a pull request comment quoting the comment, got %+v", posted)
+	}
+
+	if err := p.ResolveComment(ctx, 1, comments[0]); !errors.Is(err, ErrResolveUnsupported) {
+		t.Errorf("expected ErrResolveUnsupported, got %v", err)
+	}
+}
@@ -3,39 +3,114 @@ package provider
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
 )
 
+// GitHubAPIURL is the base URL of the github.com REST API
+const GitHubAPIURL = "https://api.github.com"
+
+// checkRunName is the name of the check runs reporting the findings
+const checkRunName = "ainspector"
+
+// checkRunAnnotationsLimit is the maximum number of annotations of a check run
+// creation or update request
+const checkRunAnnotationsLimit = 50
+
 // GitHubProvider implements Provider for GitHub
 type GitHubProvider struct {
 	client  *github.Client
 	owner   string
 	repo    string
 	headSHA string
+
+	// graphQLURL is the GraphQL endpoint, relative to the REST API on
+	// github.com
+	graphQLURL string
 }
 
 // NewGitHubProvider creates a new GitHub provider
 func NewGitHubProvider(owner, repo, token string) *GitHubProvider {
-	var client *github.Client
+	return &GitHubProvider{
+		client:     newGitHubClient(staticTokenSource(token), nil),
+		owner:      owner,
+		repo:       repo,
+		graphQLURL: "graphql",
+	}
+}
+
+// NewGitHubEnterpriseProvider creates a new provider for the GitHub
+// Enterprise Server instance whose REST API is at apiURL (e.g.
+// https://github.example.com/api/v3), trusting the CA certificates of the
+// caBundle file, if any, in addition to the system ones
+func NewGitHubEnterpriseProvider(apiURL, owner, repo, token, caBundle string) (*GitHubProvider, error) {
+	httpClient, err := newHTTPClient(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGitHubEnterpriseProvider(apiURL, owner, repo, newGitHubClient(staticTokenSource(token), httpClient))
+}
 
-	if token != "" {
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		tc := oauth2.NewClient(context.Background(), ts)
-		client = github.NewClient(tc)
-	} else {
-		client = github.NewClient(nil)
+// newGitHubEnterpriseProvider creates a new provider sending the requests of
+// client to the REST API at apiURL
+func newGitHubEnterpriseProvider(apiURL, owner, repo string, client *github.Client) (*GitHubProvider, error) {
+	client, err := client.WithEnterpriseURLs(apiURL, githubServerURL(apiURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API URL %q: %w", apiURL, err)
+	}
+
+	graphQLURL := "graphql"
+	if strings.HasSuffix(client.BaseURL.Path, "/api/v3/") {
+		graphQLURL = strings.TrimSuffix(client.BaseURL.String(), "v3/") + "graphql"
 	}
 
 	return &GitHubProvider{
-		client: client,
-		owner:  owner,
-		repo:   repo,
+		client:     client,
+		owner:      owner,
+		repo:       repo,
+		graphQLURL: graphQLURL,
+	}, nil
+}
+
+// githubServerURL returns the server URL of a GitHub Enterprise Server REST
+// API URL, as the upload and GraphQL APIs are next to the REST API (/api/v3)
+func githubServerURL(apiURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/api/v3")
+}
+
+// staticTokenSource returns a source of the given token, or nil if it is empty
+func staticTokenSource(token string) oauth2.TokenSource {
+	if token == "" {
+		return nil
+	}
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+}
+
+// newGitHubClient creates a GitHub client sending its requests through
+// httpClient (the default client if nil), authenticated with the tokens of ts
+// if set
+func newGitHubClient(ts oauth2.TokenSource, httpClient *http.Client) *github.Client {
+	if ts == nil {
+		return github.NewClient(httpClient)
 	}
+
+	ctx := context.Background()
+	if httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	}
+	return github.NewClient(oauth2.NewClient(ctx, ts))
+}
+
+// HeadSHA returns the head commit of the pull request fetched by
+// GetModifiedFiles
+func (p *GitHubProvider) HeadSHA() string {
+	return p.headSHA
 }
 
 // GetModifiedFiles returns all files modified in a pull request
@@ -103,6 +178,32 @@ func (p *GitHubProvider) GetFileContent(ctx context.Context, path string) (strin
 	return string(decoded), nil
 }
 
+// GetRepositoryFile returns the content of a file in another repository
+// (owner/repo) at the given ref, or at the default branch if ref is empty
+func (p *GitHubProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid repository %q: expected owner/repo", repository)
+	}
+
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	content, _, _, err := p.client.Repositories.GetContents(ctx, parts[0], parts[1], path, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	if content == nil {
+		return "", fmt.Errorf("file not found: %s", path)
+	}
+
+	decoded, err := content.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode content: %w", err)
+	}
+
+	return decoded, nil
+}
+
 // PostComment posts a comment on the pull request
 func (p *GitHubProvider) PostComment(ctx context.Context, number int, body string) error {
 	comment := &github.IssueComment{
@@ -154,7 +255,116 @@ func (p *GitHubProvider) CreateReview(ctx context.Context, number int, comments
 	return nil
 }
 
-// GetReviewComments returns all review comments on the pull request
+// CreateCheckRun creates a check run on the head commit of the pull request.
+// The annotations are sent by batches, as the API limits their number per
+// request: the check run is completed with the last batch.
+func (p *GitHubProvider) CreateCheckRun(ctx context.Context, run CheckRun) error {
+	annotations := make([]*github.CheckRunAnnotation, 0, len(run.Annotations))
+	for _, a := range run.Annotations {
+		annotation := &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.Line),
+			EndLine:         github.Int(a.Line),
+			AnnotationLevel: github.String(annotationLevel(a.Severity)),
+			Title:           github.String(a.Title),
+			Message:         github.String(a.Message),
+		}
+		if a.Details != "" {
+			annotation.RawDetails = github.String(a.Details)
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	var batches [][]*github.CheckRunAnnotation
+	for len(annotations) > checkRunAnnotationsLimit {
+		batches = append(batches, annotations[:checkRunAnnotationsLimit])
+		annotations = annotations[checkRunAnnotationsLimit:]
+	}
+	batches = append(batches, annotations)
+
+	output := func(batch []*github.CheckRunAnnotation) *github.CheckRunOutput {
+		return &github.CheckRunOutput{
+			Title:       github.String(run.Title),
+			Summary:     github.String(run.Summary),
+			Annotations: batch,
+		}
+	}
+
+	opts := github.CreateCheckRunOptions{
+		Name:    checkRunName,
+		HeadSHA: p.headSHA,
+		Status:  github.String("in_progress"),
+		Output:  output(batches[0]),
+	}
+	if len(batches) == 1 {
+		opts.Status = github.String("completed")
+		opts.Conclusion = github.String(run.Conclusion)
+	}
+	checkRun, _, err := p.client.Checks.CreateCheckRun(ctx, p.owner, p.repo, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for i := 1; i < len(batches); i++ {
+		update := github.UpdateCheckRunOptions{
+			Name:   checkRunName,
+			Output: output(batches[i]),
+		}
+		if i == len(batches)-1 {
+			update.Status = github.String("completed")
+			update.Conclusion = github.String(run.Conclusion)
+		}
+		if _, _, err := p.client.Checks.UpdateCheckRun(ctx, p.owner, p.repo, checkRun.GetID(), update); err != nil {
+			return fmt.Errorf("failed to add check run annotations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetCommitStatus sets the status of the head commit of the pull request
+func (p *GitHubProvider) SetCommitStatus(ctx context.Context, status CommitStatus) error {
+	repoStatus := &github.RepoStatus{
+		State:       github.String(status.State),
+		Description: github.String(status.Description),
+		Context:     github.String(StatusContext),
+	}
+	if status.TargetURL != "" {
+		repoStatus.TargetURL = github.String(status.TargetURL)
+	}
+
+	sha := status.SHA
+	if sha == "" {
+		sha = p.headSHA
+	}
+	if sha == "" {
+		return fmt.Errorf("failed to set commit status: head commit unknown")
+	}
+
+	_, _, err := p.client.Repositories.CreateStatus(ctx, p.owner, p.repo, sha, repoStatus)
+	if err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+
+	return nil
+}
+
+// annotationLevel returns the check run annotation level of a severity
+func annotationLevel(severity string) string {
+	switch severity {
+	case "critical":
+		return "failure"
+	case "info":
+		return "notice"
+	default:
+		return "warning"
+	}
+}
+
+// GetReviewComments returns all review comments on the pull request, with the
+// review thread they belong to. Review threads are only available in the
+// GraphQL API: when it fails (e.g. token without GraphQL access), a warning
+// is printed and the comments are returned without their thread.
 func (p *GitHubProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
 	var allComments []*github.PullRequestComment
 	opts := &github.PullRequestListCommentsOptions{
@@ -174,14 +384,168 @@ func (p *GitHubProvider) GetReviewComments(ctx context.Context, number int) ([]E
 		opts.Page = resp.NextPage
 	}
 
+	threads, err := p.reviewThreads(ctx, number)
+	if err != nil {
+		fmt.Printf("Warning: failed to list PR review threads, resolved threads are considered open: %v\n", err)
+	}
+
 	result := make([]ExistingComment, 0, len(allComments))
 	for _, c := range allComments {
-		result = append(result, ExistingComment{
-			Path: c.GetPath(),
-			Line: c.GetLine(),
-			Body: c.GetBody(),
-		})
+		comment := ExistingComment{
+			ID:        c.GetID(),
+			InReplyTo: c.GetInReplyTo(),
+			Path:      c.GetPath(),
+			Line:      c.GetLine(),
+			Body:      c.GetBody(),
+		}
+
+		root := comment.ID
+		if comment.InReplyTo != 0 {
+			root = comment.InReplyTo
+		}
+		if thread, ok := threads[root]; ok {
+			comment.ThreadID = thread.ID
+			comment.Resolved = thread.IsResolved
+		}
+		result = append(result, comment)
 	}
 
 	return result, nil
 }
+
+// UpdateReviewComment replaces the body of a pull request review comment
+func (p *GitHubProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	comment := &github.PullRequestComment{
+		Body: github.String(body),
+	}
+
+	_, _, err := p.client.PullRequests.EditComment(ctx, p.owner, p.repo, id, comment)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies in the review thread of a pull request comment
+func (p *GitHubProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	id := comment.ID
+	if comment.InReplyTo != 0 {
+		id = comment.InReplyTo
+	}
+
+	_, _, err := p.client.PullRequests.CreateCommentInReplyTo(ctx, p.owner, p.repo, number, body, id)
+	if err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveComment resolves the review thread of a pull request comment, loaded
+// by GetReviewComments
+func (p *GitHubProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	if comment.Resolved {
+		return nil
+	}
+	if comment.ThreadID == "" {
+		return fmt.Errorf("failed to resolve comment: no review thread found for comment %d", comment.ID)
+	}
+
+	const mutation = `mutation($id: ID!) {
+  resolveReviewThread(input: {threadId: $id}) { thread { id } }
+}`
+	if err := p.graphQL(ctx, mutation, map[string]any{"id": comment.ThreadID}, nil); err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// reviewThread is a pull request review thread of the GraphQL API
+type reviewThread struct {
+	ID         string `json:"id"`
+	IsResolved bool   `json:"isResolved"`
+	Comments   struct {
+		Nodes []struct {
+			DatabaseID int64 `json:"databaseId"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+// reviewThreads returns the review threads of the pull request, keyed by the
+// ID of their first comment
+func (p *GitHubProvider) reviewThreads(ctx context.Context, number int) (map[int64]reviewThread, error) {
+	const query = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes { id isResolved comments(first: 1) { nodes { databaseId } } }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+	result := make(map[int64]reviewThread)
+	variables := map[string]any{"owner": p.owner, "repo": p.repo, "number": number, "cursor": nil}
+	for {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes    []reviewThread `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		if err := p.graphQL(ctx, query, variables, &data); err != nil {
+			return nil, err
+		}
+
+		threads := data.Repository.PullRequest.ReviewThreads
+		for _, thread := range threads.Nodes {
+			if len(thread.Comments.Nodes) > 0 {
+				result[thread.Comments.Nodes[0].DatabaseID] = thread
+			}
+		}
+
+		if !threads.PageInfo.HasNextPage {
+			return result, nil
+		}
+		variables["cursor"] = threads.PageInfo.EndCursor
+	}
+}
+
+// graphQL runs a GraphQL query and decodes its data into result
+func (p *GitHubProvider) graphQL(ctx context.Context, query string, variables map[string]any, result any) error {
+	req, err := p.client.NewRequest(http.MethodPost, p.graphQLURL, map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := p.client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, result)
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHubApp holds the credentials of a GitHub App, which reviews are posted as
+// instead of the user or bot owning a token
+type GitHubApp struct {
+	ID         int64
+	PrivateKey []byte // PEM encoded RSA private key
+
+	// InstallationID is the installation of the app on the repository, looked
+	// up from the repository if zero
+	InstallationID int64
+}
+
+// githubAppJWTLifetime is the lifetime of the JWTs authenticating as the app,
+// under the 10 minutes maximum accepted by GitHub
+const githubAppJWTLifetime = 9 * time.Minute
+
+// NewGitHubAppProvider creates a new provider authenticated as the installation
+// of a GitHub App on the repository, for the REST API at apiURL (GitHubAPIURL
+// or a GitHub Enterprise Server API). Installation tokens expire after an
+// hour, so a new one is requested when the current one expires.
+func NewGitHubAppProvider(apiURL, owner, repo string, app GitHubApp, caBundle string) (*GitHubProvider, error) {
+	key, err := parseGitHubAppKey(app.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	// The app itself can only look up its installation and create tokens
+	appClient, err := github.NewClient(&http.Client{
+		Transport: &githubAppTransport{appID: app.ID, key: key, base: httpClient.Transport},
+	}).WithEnterpriseURLs(apiURL, githubServerURL(apiURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API URL %q: %w", apiURL, err)
+	}
+
+	ts := oauth2.ReuseTokenSource(nil, &githubAppTokenSource{
+		client:         appClient,
+		owner:          owner,
+		repo:           repo,
+		installationID: app.InstallationID,
+	})
+	return newGitHubEnterpriseProvider(apiURL, owner, repo, newGitHubClient(ts, httpClient))
+}
+
+// parseGitHubAppKey parses the private key of a GitHub App, in the PKCS #1
+// format of the keys generated by GitHub or in the PKCS #8 format
+func parseGitHubAppKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: no PEM block")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid GitHub App private key: not an RSA key")
+	}
+
+	return key, nil
+}
+
+// githubAppTransport authenticates requests as a GitHub App, with a JWT signed
+// by its private key
+type githubAppTransport struct {
+	appID int64
+	key   *rsa.PrivateKey
+	base  http.RoundTripper // http.DefaultTransport if nil
+}
+
+// RoundTrip sends the request with a new JWT
+func (t *githubAppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	transport := t.base
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// jwt returns a JWT issued at now, backdated by a minute to allow for clock
+// drift with GitHub
+func (t *githubAppTransport) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(githubAppJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(t.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// githubAppTokenSource creates installation tokens of a GitHub App, wrapped
+// in a reuse token source that serializes the calls to Token
+type githubAppTokenSource struct {
+	client *github.Client
+	owner  string
+	repo   string
+
+	installationID int64
+}
+
+// Token creates a new installation token, looking up the installation of the
+// app on the repository the first time if it isn't known
+func (s *githubAppTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+
+	if s.installationID == 0 {
+		installation, _, err := s.client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find GitHub App installation: %w", err)
+		}
+		s.installationID = installation.GetID()
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "Bearer",
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifyGitHubAppJWT checks the signature and claims of the JWT of a request
+// authenticated as a GitHub App, from the handler of a test server
+func verifyGitHubAppJWT(t *testing.T, r *http.Request, key *rsa.PublicKey) {
+	t.Helper()
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	if !ok || len(parts) != 3 {
+		t.Errorf("expected a JWT, got %q", r.Header.Get("Authorization"))
+		return
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Errorf("invalid JWT signature encoding: %v", err)
+		return
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("invalid JWT signature: %v", err)
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Errorf("invalid JWT claims encoding: %v", err)
+		return
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Errorf("invalid JWT claims: %v", err)
+		return
+	}
+	if claims.Issuer != "42" {
+		t.Errorf("expected issuer '42', got %q", claims.Issuer)
+	}
+	now := time.Now().Unix()
+	if claims.IssuedAt > now || claims.ExpiresAt <= now || claims.ExpiresAt-claims.IssuedAt > 10*60 {
+		t.Errorf("unexpected JWT validity: iat=%d exp=%d now=%d", claims.IssuedAt, claims.ExpiresAt, now)
+	}
+}
+
+func TestGitHubAppProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var lookups, tokens int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/owner/repo/installation":
+			verifyGitHubAppJWT(t, r, &key.PublicKey)
+			lookups++
+			_ = json.NewEncoder(w).Encode(map[string]int64{"id": 7})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/app/installations/7/access_tokens":
+			verifyGitHubAppJWT(t, r, &key.PublicKey)
+			tokens++
+			// The first token is about to expire, so it is only used once
+			expiresAt := time.Now().Add(time.Hour)
+			if tokens == 1 {
+				expiresAt = time.Now().Add(time.Second)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"token":      fmt.Sprintf("installation-token-%d", tokens),
+				"expires_at": expiresAt.Format(time.RFC3339),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/org/config/contents/base.yaml":
+			if got, want := r.Header.Get("Authorization"), fmt.Sprintf("Bearer installation-token-%d", tokens); got != want {
+				t.Errorf("Authorization = %q, want %q", got, want)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"type":     "file",
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte("rules: []\n")),
+			})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewGitHubAppProvider(server.URL+"/api/v3", "owner", "repo", GitHubApp{ID: 42, PrivateKey: privateKey}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		content, err := p.GetRepositoryFile(context.Background(), "org/config", "base.yaml", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if content != "rules: []\n" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	if lookups != 1 {
+		t.Errorf("expected the installation to be looked up once, got %d", lookups)
+	}
+	if tokens != 2 {
+		t.Errorf("expected 2 installation tokens (the first one expiring), got %d", tokens)
+	}
+}
+
+func TestGitHubAppProvider_InstallationID(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/app/installations/99/access_tokens":
+			verifyGitHubAppJWT(t, r, &key.PublicKey)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"token":      "installation-token",
+				"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+			})
+		case "/api/v3/repos/owner/repo/issues/1/comments":
+			if got := r.Header.Get("Authorization"); got != "Bearer installation-token" {
+				t.Errorf("unexpected Authorization: %q", got)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	app := GitHubApp{ID: 42, PrivateKey: privateKey, InstallationID: 99}
+	p, err := NewGitHubAppProvider(server.URL+"/api/v3", "owner", "repo", app, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.PostComment(context.Background(), 1, "Hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewGitHubAppProvider_InvalidKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr string
+	}{
+		{
+			name:    "not PEM",
+			key:     []byte("not a key"),
+			wantErr: "no PEM block",
+		},
+		{
+			name:    "not a private key",
+			key:     pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}),
+			wantErr: "invalid GitHub App private key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGitHubAppProvider(GitHubAPIURL, "owner", "repo", GitHubApp{ID: 42, PrivateKey: tt.key}, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
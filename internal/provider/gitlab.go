@@ -118,6 +118,28 @@ func (p *GitLabProvider) GetFileContent(ctx context.Context, path string) (strin
 	return string(content), nil
 }
 
+// GetRepositoryFile returns the content of a file in another project
+// (group/project) at the given ref, or at the default branch if ref is empty
+func (p *GitLabProvider) GetRepositoryFile(ctx context.Context, repository, path, ref string) (string, error) {
+	if p.client == nil {
+		return "", fmt.Errorf("GitLab client not initialized")
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+	opts := &gitlab.GetRawFileOptions{
+		Ref: gitlab.Ptr(ref),
+	}
+
+	content, _, err := p.client.RepositoryFiles.GetRawFile(repository, path, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
 // PostComment posts a comment on the merge request
 func (p *GitLabProvider) PostComment(ctx context.Context, number int, body string) error {
 	if p.client == nil {
@@ -218,17 +240,115 @@ func (p *GitLabProvider) GetReviewComments(ctx context.Context, number int) ([]E
 
 	var result []ExistingComment
 	for _, d := range allDiscussions {
-		for _, note := range d.Notes {
+		for i, note := range d.Notes {
 			// Only include notes with position (inline comments)
 			if note.Position != nil {
-				result = append(result, ExistingComment{
-					Path: note.Position.NewPath,
-					Line: int(note.Position.NewLine),
-					Body: note.Body,
-				})
+				comment := ExistingComment{
+					ID:       note.ID,
+					ThreadID: d.ID,
+					Resolved: note.Resolved,
+					Path:     note.Position.NewPath,
+					Line:     int(note.Position.NewLine),
+					Body:     note.Body,
+				}
+				if i > 0 {
+					comment.InReplyTo = d.Notes[0].ID
+				}
+				result = append(result, comment)
 			}
 		}
 	}
 
 	return result, nil
 }
+
+// UpdateReviewComment replaces the body of a merge request note
+func (p *GitLabProvider) UpdateReviewComment(ctx context.Context, number int, id int64, body string) error {
+	if p.client == nil {
+		return fmt.Errorf("GitLab client not initialized")
+	}
+
+	opts := &gitlab.UpdateMergeRequestNoteOptions{
+		Body: gitlab.Ptr(body),
+	}
+
+	_, _, err := p.client.Notes.UpdateMergeRequestNote(p.projectID, int64(number), id, opts)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveComment resolves the discussion of a merge request note
+func (p *GitLabProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	if p.client == nil {
+		return fmt.Errorf("GitLab client not initialized")
+	}
+
+	opts := &gitlab.ResolveMergeRequestDiscussionOptions{
+		Resolved: gitlab.Ptr(true),
+	}
+
+	_, _, err := p.client.Discussions.ResolveMergeRequestDiscussion(p.projectID, int64(number), comment.ThreadID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+
+	return nil
+}
+
+// ReplyToComment replies in the discussion of a merge request note
+func (p *GitLabProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	if p.client == nil {
+		return fmt.Errorf("GitLab client not initialized")
+	}
+
+	opts := &gitlab.AddMergeRequestDiscussionNoteOptions{
+		Body: gitlab.Ptr(body),
+	}
+
+	_, _, err := p.client.Discussions.AddMergeRequestDiscussionNote(p.projectID, int64(number), comment.ThreadID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+
+	return nil
+}
+
+// SetCommitStatus sets the status of the head commit of the merge request
+func (p *GitLabProvider) SetCommitStatus(ctx context.Context, status CommitStatus) error {
+	if p.client == nil {
+		return fmt.Errorf("GitLab client not initialized")
+	}
+
+	// GitLab names the failure state "failed"
+	state := gitlab.BuildStateValue(status.State)
+	if status.State == "failure" {
+		state = gitlab.Failed
+	}
+
+	opts := &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        gitlab.Ptr(StatusContext),
+		Description: gitlab.Ptr(status.Description),
+	}
+	if status.TargetURL != "" {
+		opts.TargetURL = gitlab.Ptr(status.TargetURL)
+	}
+
+	sha := status.SHA
+	if sha == "" {
+		sha = p.headSHA
+	}
+	if sha == "" {
+		return fmt.Errorf("failed to set commit status: head commit unknown")
+	}
+
+	_, _, err := p.client.Commits.SetCommitStatus(p.projectID, sha, opts)
+	if err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+
+	return nil
+}
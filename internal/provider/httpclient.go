@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// newHTTPClient returns an HTTP client trusting the PEM encoded CA
+// certificates of the caBundle file in addition to the system ones, for
+// self-hosted instances using an internal certificate authority. Without a
+// bundle, it returns the default client.
+func newHTTPClient(caBundle string) (*http.Client, error) {
+	if caBundle == "" {
+		return http.DefaultClient, nil
+	}
+
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("failed to read CA bundle: no PEM certificate in %s", caBundle)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iq2i/ainspector/internal/parser"
+)
+
+// errLocalUnsupported is returned by LocalProvider for operations requiring a PR/MR
+var errLocalUnsupported = errors.New("not supported on the local working tree")
+
+// LocalProvider implements Provider for the local working tree: every
+// supported source file is reported as added, so that all of its functions are
+// extracted. It can't post comments.
+type LocalProvider struct {
+	root  string
+	paths []string
+}
+
+// NewLocalProvider creates a provider reading files under root. If paths are
+// given, only these files and directories (relative to root) are listed.
+func NewLocalProvider(root string, paths ...string) *LocalProvider {
+	return &LocalProvider{root: root, paths: paths}
+}
+
+// GetModifiedFiles returns all supported source files of the working tree,
+// skipping hidden directories, node_modules and vendor. The PR/MR number is
+// ignored.
+func (p *LocalProvider) GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error) {
+	paths := p.paths
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var result []ModifiedFile
+	for _, start := range paths {
+		err := filepath.WalkDir(filepath.Join(p.root, start), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != filepath.Join(p.root, start) && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !parser.IsSupported(path) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(p.root, path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			result = append(result, ModifiedFile{
+				Path:   filepath.ToSlash(rel),
+				Status: "added",
+				Patch:  addedPatch(string(content)),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// GetFileContent returns the content of a file of the working tree
+func (p *LocalProvider) GetFileContent(ctx context.Context, path string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(p.root, filepath.FromSlash(path)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), nil
+}
+
+// PostComment is not supported on the local working tree
+func (p *LocalProvider) PostComment(ctx context.Context, number int, body string) error {
+	return errLocalUnsupported
+}
+
+// CreateReview is not supported on the local working tree
+func (p *LocalProvider) CreateReview(ctx context.Context, number int, comments []ReviewComment) error {
+	return errLocalUnsupported
+}
+
+// GetReviewComments returns no comments: the working tree has no PR/MR
+func (p *LocalProvider) GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error) {
+	return nil, nil
+}
+
+// addedPatch returns the unified diff of a newly added file
+func addedPatch(content string) string {
+	if content == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -0,0 +1,%d @@\n", len(lines))
+	for _, line := range lines {
+		sb.WriteString("+")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ResolveComment is not supported on the local working tree
+func (p *LocalProvider) ResolveComment(ctx context.Context, number int, comment ExistingComment) error {
+	return errLocalUnsupported
+}
+
+// ReplyToComment is not supported on the local working tree
+func (p *LocalProvider) ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error {
+	return errLocalUnsupported
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestLocalProvider_ImplementsInterface(t *testing.T) {
+	var _ Provider = (*LocalProvider)(nil)
+}
+
+func TestLocalProvider_GetModifiedFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                  "package main\n\nfunc main() {}\n",
+		"internal/app/app.go":      "package app\n",
+		"web/index.ts":             "export {}\n",
+		"README.md":                "# Project\n",
+		"vendor/lib/lib.go":        "package lib\n",
+		"node_modules/pkg/a.js":    "",
+		".git/hooks/pre-commit.sh": "",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		_ = os.MkdirAll(filepath.Dir(fullPath), 0755)
+		_ = os.WriteFile(fullPath, []byte(content), 0644)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{"whole tree", nil, []string{"internal/app/app.go", "main.go", "web/index.ts"}},
+		{"restricted paths", []string{"internal", "web/index.ts"}, []string{"internal/app/app.go", "web/index.ts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewLocalProvider(root, tt.paths...)
+			result, err := p.GetModifiedFiles(context.Background(), 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, f := range result {
+				paths = append(paths, f.Path)
+				if f.Status != "added" {
+					t.Errorf("expected status added for %s, got %s", f.Path, f.Status)
+				}
+			}
+			sort.Strings(paths)
+			if len(paths) != len(tt.want) {
+				t.Fatalf("expected files %v, got %v", tt.want, paths)
+			}
+			for i := range paths {
+				if paths[i] != tt.want[i] {
+					t.Errorf("expected files %v, got %v", tt.want, paths)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalProvider_GetFileContent(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+
+	p := NewLocalProvider(root)
+	content, err := p.GetFileContent(context.Background(), "main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	if _, err := p.GetFileContent(context.Background(), "missing.go"); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if err := p.CreateReview(context.Background(), 0, nil); err == nil {
+		t.Error("expected CreateReview to be unsupported")
+	}
+}
+
+func TestAddedPatch(t *testing.T) {
+	if got := addedPatch(""); got != "" {
+		t.Errorf("expected empty patch, got %q", got)
+	}
+
+	want := "@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := addedPatch("a\nb\n"); got != want {
+		t.Errorf("addedPatch() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,13 @@
 package provider
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrResolveUnsupported is returned by ResolveComment on the git hosts whose
+// API can't resolve review threads
+var ErrResolveUnsupported = errors.New("the git host cannot resolve review threads")
 
 // ModifiedFile represents a file that was modified in a PR/MR
 type ModifiedFile struct {
@@ -21,12 +28,18 @@ type ReviewComment struct {
 
 // ExistingComment represents a review comment already posted on the PR/MR
 type ExistingComment struct {
-	Path string // File path
-	Line int    // Line number
-	Body string // Comment body
+	ID        int64  // Comment ID on the git host
+	ThreadID  string // Thread ID, for hosts identifying threads separately (GitHub review threads, GitLab discussions, Azure DevOps threads)
+	InReplyTo int64  // ID of the first comment of the thread, for replies
+	Resolved  bool   // Whether the thread is known to be resolved
+	Path      string // File path
+	Line      int    // Line number
+	Body      string // Comment body
 }
 
-// Provider is the interface for git hosting providers (GitHub, GitLab)
+// Provider is the interface for git hosting providers (GitHub, GitLab,
+// Bitbucket Cloud and Server, Gitea, Azure DevOps, Gerrit), also implemented
+// by LocalProvider for the local working tree
 type Provider interface {
 	// GetModifiedFiles returns all files modified in a PR/MR
 	GetModifiedFiles(ctx context.Context, number int) ([]ModifiedFile, error)
@@ -42,4 +55,80 @@ type Provider interface {
 
 	// GetReviewComments returns all review comments on the PR/MR
 	GetReviewComments(ctx context.Context, number int) ([]ExistingComment, error)
+
+	// ResolveComment resolves the thread of a review comment. Returns
+	// ErrResolveUnsupported if the git host can't resolve threads.
+	ResolveComment(ctx context.Context, number int, comment ExistingComment) error
+
+	// ReplyToComment replies in the thread of a review comment
+	ReplyToComment(ctx context.Context, number int, comment ExistingComment, body string) error
+}
+
+// CommentUpdater is implemented by providers able to edit review comments
+// already posted on the PR/MR
+type CommentUpdater interface {
+	// UpdateReviewComment replaces the body of a review comment
+	UpdateReviewComment(ctx context.Context, number int, id int64, body string) error
+}
+
+// HeadCommitReader is implemented by providers exposing the head commit of the
+// PR/MR, to check that a review was computed for its current state
+type HeadCommitReader interface {
+	// HeadSHA returns the head commit of the PR/MR, known once its modified
+	// files are fetched
+	HeadSHA() string
+}
+
+// CheckRun is a completed check run reporting the findings of a review on the
+// head commit of the PR/MR
+type CheckRun struct {
+	Conclusion  string // success, neutral or failure
+	Title       string
+	Summary     string // Markdown
+	Annotations []Annotation
+}
+
+// Annotation is a finding reported on a line by a check run
+type Annotation struct {
+	Path     string // File path
+	Line     int    // Line number
+	Severity string // critical, warning or info
+	Title    string
+	Message  string
+	Details  string // Optional: raw details, such as the suggested code
+}
+
+// CheckReporter is implemented by providers able to report the findings of a
+// review as a check run
+type CheckReporter interface {
+	// CreateCheckRun creates a check run on the head commit of the PR/MR,
+	// known once its modified files are fetched
+	CreateCheckRun(ctx context.Context, run CheckRun) error
+}
+
+// StatusContext is the name of the commit status set by ainspector
+const StatusContext = "ainspector/review"
+
+// CommitStatus is the state of the review of the head commit of the PR/MR
+type CommitStatus struct {
+	SHA         string // Optional: commit, defaults to the head known once the modified files are fetched
+	State       string // pending, success or failure
+	Description string // Short summary, e.g. "3 issues (1 critical)"
+	TargetURL   string // Optional: page of the review, e.g. the CI job
+}
+
+// StatusReporter is implemented by providers able to set a commit status
+type StatusReporter interface {
+	// SetCommitStatus sets the StatusContext status of the given commit, or
+	// of the head commit of the PR/MR known once its modified files are
+	// fetched
+	SetCommitStatus(ctx context.Context, status CommitStatus) error
+}
+
+// FileFilter is implemented by providers computing the patches of the
+// modified files from their contents, to skip downloading the files that
+// won't be reviewed
+type FileFilter interface {
+	// SetFileFilter sets the function reporting whether a file is reviewed
+	SetFileFilter(reviewed func(path string) bool)
 }
@@ -1,7 +1,20 @@
 package provider
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 func TestModifiedFile_Fields(t *testing.T) {
@@ -69,6 +82,108 @@ func TestNewGitHubProvider_NoToken(t *testing.T) {
 	}
 }
 
+func TestNewGitHubEnterpriseProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiURL      string
+		wantBaseURL string
+		wantUpload  string
+		wantGraphQL string
+	}{
+		{
+			name:        "enterprise server",
+			apiURL:      "https://github.example.com/api/v3",
+			wantBaseURL: "https://github.example.com/api/v3/",
+			wantUpload:  "https://github.example.com/api/uploads/",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+		{
+			name:        "enterprise server without API path",
+			apiURL:      "https://github.example.com/",
+			wantBaseURL: "https://github.example.com/api/v3/",
+			wantUpload:  "https://github.example.com/api/uploads/",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+		{
+			name:        "api subdomain",
+			apiURL:      GitHubAPIURL,
+			wantBaseURL: "https://api.github.com/",
+			wantUpload:  "https://api.github.com/",
+			wantGraphQL: "graphql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewGitHubEnterpriseProvider(tt.apiURL, "owner", "repo", "token", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := p.client.BaseURL.String(); got != tt.wantBaseURL {
+				t.Errorf("BaseURL = %q, want %q", got, tt.wantBaseURL)
+			}
+			if got := p.client.UploadURL.String(); got != tt.wantUpload {
+				t.Errorf("UploadURL = %q, want %q", got, tt.wantUpload)
+			}
+			if p.graphQLURL != tt.wantGraphQL {
+				t.Errorf("graphQLURL = %q, want %q", p.graphQLURL, tt.wantGraphQL)
+			}
+		})
+	}
+}
+
+func TestNewGitHubEnterpriseProvider_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/config/contents/base.yaml" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("rules: []\n")),
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certificate, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the bundle, the certificate of the server is unknown
+	p, err := NewGitHubEnterpriseProvider(server.URL+"/api/v3", "owner", "repo", "token", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.GetRepositoryFile(context.Background(), "org/config", "base.yaml", ""); err == nil {
+		t.Error("expected a certificate error without the CA bundle")
+	}
+
+	p, err = NewGitHubEnterpriseProvider(server.URL+"/api/v3", "owner", "repo", "token", bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := p.GetRepositoryFile(context.Background(), "org/config", "base.yaml", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "rules: []\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.pem")} {
+		if _, err := NewGitHubEnterpriseProvider(server.URL, "owner", "repo", "token", path); err == nil {
+			t.Errorf("expected error for CA bundle %s", path)
+		}
+	}
+}
+
 func TestNewGitLabProvider(t *testing.T) {
 	p := NewGitLabProvider("gitlab.com", "owner", "repo", "token")
 
@@ -112,3 +227,445 @@ func TestGitHubProvider_ImplementsInterface(t *testing.T) {
 func TestGitLabProvider_ImplementsInterface(t *testing.T) {
 	var _ Provider = (*GitLabProvider)(nil)
 }
+
+func TestGitHubProvider_ImplementsRemoteFetcher(t *testing.T) {
+	var _ config.RemoteFetcher = (*GitHubProvider)(nil)
+}
+
+func TestGitLabProvider_ImplementsRemoteFetcher(t *testing.T) {
+	var _ config.RemoteFetcher = (*GitLabProvider)(nil)
+}
+
+func TestProviders_ImplementCommentUpdater(t *testing.T) {
+	var _ CommentUpdater = (*GitHubProvider)(nil)
+	var _ CommentUpdater = (*GitLabProvider)(nil)
+}
+
+func TestGitHubProvider_UpdateReviewComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/owner/repo/pulls/comments/42" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["body"] != "updated" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "body": "updated"})
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	if err := p.UpdateReviewComment(context.Background(), 1, 42, "updated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGitLabProvider_UpdateReviewComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.EscapedPath() != "/api/v4/projects/owner%2Frepo/merge_requests/7/notes/42" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.EscapedPath())
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "body": "updated"})
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	p := &GitLabProvider{client: client, projectID: "owner/repo"}
+
+	if err := p.UpdateReviewComment(context.Background(), 7, 42, "updated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGitHubProvider_GetReviewComments(t *testing.T) {
+	var threadQueries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/1/comments":
+			_, _ = w.Write([]byte(`[
+				{"id": 41, "path": "main.go", "line": 3, "body": "Open"},
+				{"id": 42, "path": "main.go", "line": 5, "body": "Resolved by a human"},
+				{"id": 43, "in_reply_to_id": 42, "path": "main.go", "line": 5, "body": "Done"},
+				{"id": 44, "path": "main.go", "line": 7, "body": "No thread"}
+			]`))
+		case "/graphql":
+			threadQueries++
+			var req struct {
+				Variables map[string]any `json:"variables"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Variables["cursor"] == nil {
+				_, _ = w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{
+					"nodes":[{"id":"T1","isResolved":false,"comments":{"nodes":[{"databaseId":41}]}}],
+					"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{
+				"nodes":[{"id":"T2","isResolved":true,"comments":{"nodes":[{"databaseId":42}]}}],
+				"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	comments, err := p.GetReviewComments(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []ExistingComment{
+		{ID: 41, ThreadID: "T1", Path: "main.go", Line: 3, Body: "Open"},
+		{ID: 42, ThreadID: "T2", Resolved: true, Path: "main.go", Line: 5, Body: "Resolved by a human"},
+		{ID: 43, ThreadID: "T2", InReplyTo: 42, Resolved: true, Path: "main.go", Line: 5, Body: "Done"},
+		{ID: 44, Path: "main.go", Line: 7, Body: "No thread"},
+	}
+	if fmt.Sprint(comments) != fmt.Sprint(want) {
+		t.Errorf("expected comments %+v, got %+v", want, comments)
+	}
+	if threadQueries != 2 {
+		t.Errorf("expected the 2 pages of threads to be loaded once, got %d queries", threadQueries)
+	}
+}
+
+func TestGitHubProvider_GetReviewCommentsWithoutThreads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/1/comments":
+			_, _ = w.Write([]byte(`[{"id": 41, "path": "main.go", "line": 3, "body": "Open"}]`))
+		case "/graphql":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	comments, err := p.GetReviewComments(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected the REST comments despite the GraphQL failure, got error: %v", err)
+	}
+
+	want := []ExistingComment{{ID: 41, Path: "main.go", Line: 3, Body: "Open"}}
+	if fmt.Sprint(comments) != fmt.Sprint(want) {
+		t.Errorf("expected comments %+v, got %+v", want, comments)
+	}
+}
+
+func TestGitHubProvider_ResolveComment(t *testing.T) {
+	var resolved []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		id, _ := req.Variables["id"].(string)
+		resolved = append(resolved, id)
+		_, _ = w.Write([]byte(`{"data":{"resolveReviewThread":{"thread":{"id":"` + id + `"}}}}`))
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	if err := p.ResolveComment(context.Background(), 1, ExistingComment{ID: 42, ThreadID: "T2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.ResolveComment(context.Background(), 1, ExistingComment{ID: 41, ThreadID: "T1", Resolved: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(resolved) != "[T2]" {
+		t.Errorf("expected only thread T2 to be resolved, got %v", resolved)
+	}
+
+	if err := p.ResolveComment(context.Background(), 1, ExistingComment{ID: 99}); err == nil {
+		t.Error("expected error for comment without thread")
+	}
+}
+
+func TestGitHubProvider_ImplementsCheckReporter(t *testing.T) {
+	var _ CheckReporter = (*GitHubProvider)(nil)
+}
+
+func TestGitHubProvider_CreateCheckRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations int
+		want        []string // method, status and number of annotations of each request
+	}{
+		{
+			name: "no annotations",
+			want: []string{"POST completed 0"},
+		},
+		{
+			name:        "single batch",
+			annotations: 50,
+			want:        []string{"POST completed 50"},
+		},
+		{
+			name:        "several batches",
+			annotations: 120,
+			want:        []string{"POST in_progress 50", "PATCH  50", "PATCH completed 20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Name       string `json:"name"`
+					HeadSHA    string `json:"head_sha"`
+					Status     string `json:"status"`
+					Conclusion string `json:"conclusion"`
+					Output     struct {
+						Title       string `json:"title"`
+						Annotations []struct {
+							Path            string `json:"path"`
+							StartLine       int    `json:"start_line"`
+							AnnotationLevel string `json:"annotation_level"`
+						} `json:"annotations"`
+					} `json:"output"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				requests = append(requests, fmt.Sprintf("%s %s %d", r.Method, body.Status, len(body.Output.Annotations)))
+
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/owner/repo/check-runs":
+					if body.HeadSHA != "abc123" || body.Name != "ainspector" {
+						t.Errorf("unexpected check run: %+v", body)
+					}
+				case r.Method == http.MethodPatch && r.URL.Path == "/api/v3/repos/owner/repo/check-runs/5":
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+				if body.Status == "completed" && body.Conclusion != "failure" {
+					t.Errorf("expected conclusion failure, got %q", body.Conclusion)
+				}
+				if body.Status != "completed" && body.Conclusion != "" {
+					t.Errorf("unexpected conclusion before completion: %q", body.Conclusion)
+				}
+				if len(body.Output.Annotations) > 0 && body.Output.Annotations[0].AnnotationLevel != "failure" {
+					t.Errorf("expected failure annotation level, got %+v", body.Output.Annotations[0])
+				}
+				if body.Output.Title != "Issues" {
+					t.Errorf("unexpected title: %q", body.Output.Title)
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": 5})
+			}))
+			defer server.Close()
+
+			p, err := NewGitHubEnterpriseProvider(server.URL+"/api/v3", "owner", "repo", "token", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p.headSHA = "abc123"
+
+			run := CheckRun{Conclusion: "failure", Title: "Issues", Summary: "Summary"}
+			for i := 0; i < tt.annotations; i++ {
+				run.Annotations = append(run.Annotations, Annotation{Path: "main.go", Line: i + 1, Severity: "critical", Title: "main", Message: "Issue"})
+			}
+			if err := p.CreateCheckRun(context.Background(), run); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(requests) != fmt.Sprint(tt.want) {
+				t.Errorf("expected requests %v, got %v", tt.want, requests)
+			}
+		})
+	}
+}
+
+func TestProviders_ImplementStatusReporter(t *testing.T) {
+	var _ StatusReporter = (*GitHubProvider)(nil)
+	var _ StatusReporter = (*GitLabProvider)(nil)
+}
+
+func TestGitHubProvider_SetCommitStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/statuses/abc123" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		want := map[string]string{
+			"state":       "failure",
+			"description": "3 issues (1 critical)",
+			"context":     "ainspector/review",
+			"target_url":  "https://github.com/owner/repo/actions/runs/1",
+		}
+		if fmt.Sprint(body) != fmt.Sprint(want) {
+			t.Errorf("expected status %v, got %v", want, body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+	p.headSHA = "abc123"
+
+	status := CommitStatus{State: "failure", Description: "3 issues (1 critical)", TargetURL: "https://github.com/owner/repo/actions/runs/1"}
+	if err := p.SetCommitStatus(context.Background(), status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGitLabProvider_SetCommitStatus(t *testing.T) {
+	// The pending status is set on the commit of the CI environment, before
+	// the head of the merge request is fetched
+	tests := []struct {
+		state   string
+		sha     string
+		headSHA string
+		want    string
+	}{
+		{state: "pending", sha: "abc123", want: "pending"},
+		{state: "success", headSHA: "abc123", want: "success"},
+		{state: "failure", sha: "abc123", headSHA: "abc123", want: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/v4/projects/owner%2Frepo/statuses/abc123" {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.EscapedPath())
+				}
+				var body map[string]string
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				if body["state"] != tt.want || body["name"] != "ainspector/review" || body["description"] != "No issues" {
+					t.Errorf("unexpected status: %v", body)
+				}
+				if _, ok := body["target_url"]; ok {
+					t.Errorf("unexpected target URL: %v", body)
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			p := &GitLabProvider{client: client, projectID: "owner/repo", headSHA: tt.headSHA}
+
+			if err := p.SetCommitStatus(context.Background(), CommitStatus{SHA: tt.sha, State: tt.state, Description: "No issues"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGitLabProvider_ResolveAndReply(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"id":43,"body":"Fixed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"abc"}`))
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	p := &GitLabProvider{client: client, projectID: "owner/repo"}
+	comment := ExistingComment{ID: 42, ThreadID: "abc"}
+
+	if err := p.ReplyToComment(context.Background(), 7, comment, "Fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.ResolveComment(context.Background(), 7, comment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"POST /api/v4/projects/owner%2Frepo/merge_requests/7/discussions/abc/notes",
+		"PUT /api/v4/projects/owner%2Frepo/merge_requests/7/discussions/abc",
+	}
+	if len(requests) != len(want) || requests[0] != want[0] || requests[1] != want[1] {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestGitHubProvider_GetRepositoryFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/ainspector-config/contents/base.yaml" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("ref") != "main" {
+			t.Errorf("expected ref 'main', got %q", r.URL.Query().Get("ref"))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("rules: [Base rule]\n")),
+		})
+	}))
+	defer server.Close()
+
+	p := NewGitHubProvider("owner", "repo", "token")
+	p.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	content, err := p.GetRepositoryFile(context.Background(), "org/ainspector-config", "base.yaml", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "rules: [Base rule]\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if _, err := p.GetRepositoryFile(context.Background(), "invalid", "base.yaml", ""); err == nil {
+		t.Error("expected error for invalid repository")
+	}
+}
+
+func TestGitLabProvider_GetRepositoryFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fconfig/repository/files/base%2Eyaml/raw" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("ref") != "HEAD" {
+			t.Errorf("expected default ref 'HEAD', got %q", r.URL.Query().Get("ref"))
+		}
+		_, _ = w.Write([]byte("rules: [Base rule]\n"))
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	p := &GitLabProvider{client: client, projectID: "owner/repo"}
+
+	content, err := p.GetRepositoryFile(context.Background(), "group/config", "base.yaml", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "rules: [Base rule]\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// restClient is a minimal client for the JSON REST APIs of the git hosts
+// without a Go SDK
+type restClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+// newRESTClient creates a client for the API at baseURL, sending header
+// (e.g. the authentication) with every request
+func newRESTClient(baseURL string, header http.Header) *restClient {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  header,
+		client:  http.DefaultClient,
+	}
+}
+
+// do sends a request with body encoded as JSON, and decodes the JSON response
+// into result. body and result may be nil.
+func (c *restClient) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	header := http.Header{"Accept": {"application/json"}}
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.send(ctx, method, path, reader, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// getRaw returns the raw body of a GET request
+func (c *restClient) getRaw(ctx context.Context, path string) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// send sends a request to path, an API path or an absolute URL (e.g. the next
+// page link of a paginated response), and checks the response status.
+// Absolute URLs must point to the API host, which the credentials are sent to.
+func (c *restClient) send(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		target = c.baseURL + path
+	} else if err := c.checkHost(path); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s %s: unexpected status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return resp, nil
+}
+
+// checkHost checks that an absolute URL points to the scheme and host of the API
+func (c *restClient) checkHost(rawURL string) error {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return err
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if target.Scheme != base.Scheme || target.Host != base.Host {
+		return fmt.Errorf("refusing to follow %s outside of the API host %s", rawURL, base.Host)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/iq2i/ainspector/internal/llm"
+	"github.com/iq2i/ainspector/internal/parser"
+)
+
+// Git hosts a CI workflow can be generated for
+const (
+	HostGitHub = "github"
+	HostGitLab = "gitlab"
+)
+
+// Project describes what was found in the working tree
+type Project struct {
+	// Languages lists the supported languages found, most used first
+	Languages []string
+	// IgnorePaths lists the dependency, build and generated paths found
+	IgnorePaths []string
+	// ContextFiles lists the documentation and AI instruction files found
+	ContextFiles []string
+	// Host is the detected git host (github or gitlab)
+	Host string
+}
+
+// dependencyDirs lists directories holding dependencies or build output,
+// ignored when present
+var dependencyDirs = []string{"vendor", "node_modules", "dist", "build", "target", "coverage"}
+
+// generatedPatterns lists glob patterns of generated files, ignored when a
+// matching file is present
+var generatedPatterns = []string{
+	"*.pb.go",
+	"*_gen.go",
+	"*_generated.go",
+	"*.generated.*",
+	"*.min.js",
+	"*.min.css",
+	"*_pb2.py",
+}
+
+// Detect inspects the tree under root: languages of the supported source
+// files, dependency directories, generated files, context files and git host
+func Detect(root string) (*Project, error) {
+	project := &Project{Host: detectHost(root)}
+
+	for _, dir := range dependencyDirs {
+		if info, err := os.Stat(filepath.Join(root, dir)); err == nil && info.IsDir() {
+			project.IgnorePaths = append(project.IgnorePaths, dir+"/")
+		}
+	}
+
+	supported := make(map[string]bool)
+	for _, ext := range parser.SupportedExtensions() {
+		supported[ext] = true
+	}
+
+	counts := make(map[string]int)
+	generated := make(map[string]bool)
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || contains(dependencyDirs, name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		for _, pattern := range generatedPatterns {
+			if matched, _ := doublestar.Match(pattern, d.Name()); matched {
+				generated[pattern] = true
+				return nil
+			}
+		}
+
+		if supported[strings.ToLower(filepath.Ext(p))] {
+			counts[parser.GetConfig(p).Name]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range generatedPatterns {
+		if generated[pattern] {
+			project.IgnorePaths = append(project.IgnorePaths, "**/"+pattern)
+		}
+	}
+
+	for lang := range counts {
+		project.Languages = append(project.Languages, lang)
+	}
+	sort.Slice(project.Languages, func(i, j int) bool {
+		a, b := project.Languages[i], project.Languages[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+
+	for _, file := range append(llm.InstructionFiles(), "README.md", "ARCHITECTURE.md") {
+		if _, err := os.Stat(filepath.Join(root, file)); err == nil {
+			project.ContextFiles = append(project.ContextFiles, file)
+		}
+	}
+
+	return project, nil
+}
+
+// detectHost guesses the git host from CI files and the origin remote.
+// Defaults to GitHub.
+func detectHost(root string) string {
+	if _, err := os.Stat(filepath.Join(root, ".gitlab-ci.yml")); err == nil {
+		return HostGitLab
+	}
+	if _, err := os.Stat(filepath.Join(root, ".github")); err == nil {
+		return HostGitHub
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ".git", "config"))
+	if err == nil && strings.Contains(string(data), "gitlab") {
+		return HostGitLab
+	}
+
+	return HostGitHub
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
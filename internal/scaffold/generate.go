@@ -0,0 +1,122 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+)
+
+// starterRules lists generic review rules suggested for every project
+var starterRules = []string{
+	"No secrets, tokens or credentials in source code",
+	"Errors must be handled or propagated, never silently ignored",
+}
+
+// languageRules lists starter rules suggested for detected languages
+var languageRules = map[string][]string{
+	"go":         {"Errors must be wrapped with context using fmt.Errorf and %w"},
+	"javascript": {"No console.log in production code"},
+	"typescript": {"Avoid the any type; prefer precise types or unknown"},
+	"python":     {"Public functions must have type hints"},
+	"php":        {"Files must declare strict_types=1"},
+	"java":       {"Resources must be closed with try-with-resources"},
+	"rust":       {"No unwrap() or expect() outside of tests"},
+}
+
+// Config returns a commented ainspector.yaml for the project
+func Config(project *Project) string {
+	var sb strings.Builder
+
+	sb.WriteString("# ainspector configuration, generated by `ainspector init`\n")
+	sb.WriteString("# Run `ainspector config validate` after editing it.\n")
+	sb.WriteString("# yaml-language-server: $schema=https://raw.githubusercontent.com/iq2i/ainspector/main/schema/ainspector.schema.json\n")
+	if len(project.Languages) > 0 {
+		sb.WriteString(fmt.Sprintf("# Detected languages: %s\n", strings.Join(project.Languages, ", ")))
+	}
+
+	sb.WriteString("\n# Files to skip during review (glob patterns, ** matches any directory)\n")
+	if len(project.IgnorePaths) > 0 {
+		sb.WriteString("ignore:\n  paths:\n")
+		writeList(&sb, "    ", project.IgnorePaths)
+	} else {
+		sb.WriteString("# ignore:\n#   paths:\n#     - vendor/\n#     - \"**/*.generated.go\"\n")
+	}
+
+	sb.WriteString("\n# Files describing the project, sent to the AI as context\n")
+	if len(project.ContextFiles) > 0 {
+		sb.WriteString("context:\n  include:\n")
+		writeList(&sb, "    ", project.ContextFiles)
+	} else {
+		sb.WriteString("# context:\n#   include:\n#     - CLAUDE.md\n#     - docs/**.md\n")
+	}
+
+	sb.WriteString("\n# Custom review rules enforced by the AI. A rule is a plain string or a\n")
+	sb.WriteString("# mapping with id, text, paths, languages and severity (critical, warning, info).\n")
+	sb.WriteString("rules:\n")
+	writeList(&sb, "  ", starterRules)
+	for _, lang := range project.Languages {
+		for i, rule := range languageRules[lang] {
+			sb.WriteString(fmt.Sprintf("  - id: %s-%d\n", lang, i+1))
+			sb.WriteString(fmt.Sprintf("    text: %q\n", rule))
+			sb.WriteString(fmt.Sprintf("    languages: [%s]\n", lang))
+		}
+	}
+
+	return sb.String()
+}
+
+// Workflow returns the path and content of a CI configuration running
+// ainspector on pull/merge requests for the given host. For GitLab, the
+// content is a job to add to .gitlab-ci.yml.
+func Workflow(host string) (string, string) {
+	if host == HostGitLab {
+		return ".gitlab-ci.yml", gitlabWorkflow
+	}
+	return ".github/workflows/ainspector.yml", githubWorkflow
+}
+
+// writeList writes a YAML list of quoted strings with the given indentation
+func writeList(sb *strings.Builder, indent string, items []string) {
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("%s- %q\n", indent, item))
+	}
+}
+
+const githubWorkflow = `name: AI Code Review
+
+on:
+  pull_request:
+    types: [opened, synchronize]
+
+jobs:
+  review:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      pull-requests: write
+    steps:
+      - uses: actions/checkout@v4
+
+      - name: Download ainspector
+        run: |
+          curl -sL https://github.com/iq2i/ainspector/releases/latest/download/ainspector-linux-amd64 -o ainspector
+          chmod +x ainspector
+
+      - name: Run AI review
+        env:
+          GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+          LLM_API_KEY: ${{ secrets.LLM_API_KEY }}
+        run: ./ainspector review
+`
+
+const gitlabWorkflow = `ai-review:
+  stage: test
+  rules:
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event"
+  script:
+    - curl -sL https://github.com/iq2i/ainspector/releases/latest/download/ainspector-linux-amd64 -o ainspector
+    - chmod +x ainspector
+    - ./ainspector review
+  variables:
+    GITLAB_TOKEN: $GITLAB_API_TOKEN
+    LLM_API_KEY: $LLM_API_KEY
+`
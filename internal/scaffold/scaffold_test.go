@@ -0,0 +1,130 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iq2i/ainspector/internal/config"
+)
+
+func writeFile(t *testing.T, root, relPath, content string) {
+	t.Helper()
+	fullPath := filepath.Join(root, relPath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main")
+	writeFile(t, root, "internal/app/app.go", "package app")
+	writeFile(t, root, "internal/api/api.pb.go", "package api")
+	writeFile(t, root, "web/index.ts", "export {}")
+	writeFile(t, root, "vendor/lib/lib.go", "package lib")
+	writeFile(t, root, "node_modules/pkg/index.js", "")
+	writeFile(t, root, ".hidden/script.py", "")
+	writeFile(t, root, "CLAUDE.md", "# Instructions")
+	writeFile(t, root, "README.md", "# Project")
+	writeFile(t, root, ".gitlab-ci.yml", "stages: [test]")
+
+	project, err := Detect(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"go", "typescript"}; !reflect.DeepEqual(project.Languages, want) {
+		t.Errorf("Languages = %v, want %v", project.Languages, want)
+	}
+	if want := []string{"vendor/", "node_modules/", "**/*.pb.go"}; !reflect.DeepEqual(project.IgnorePaths, want) {
+		t.Errorf("IgnorePaths = %v, want %v", project.IgnorePaths, want)
+	}
+	if want := []string{"CLAUDE.md", "README.md"}; !reflect.DeepEqual(project.ContextFiles, want) {
+		t.Errorf("ContextFiles = %v, want %v", project.ContextFiles, want)
+	}
+	if project.Host != HostGitLab {
+		t.Errorf("Host = %q, want %q", project.Host, HostGitLab)
+	}
+}
+
+func TestDetectHost(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"defaults to github", nil, HostGitHub},
+		{"github directory", map[string]string{".github/workflows/ci.yml": ""}, HostGitHub},
+		{"gitlab ci file", map[string]string{".gitlab-ci.yml": ""}, HostGitLab},
+		{"gitlab remote", map[string]string{".git/config": "[remote \"origin\"]\n\turl = git@gitlab.com:org/repo.git\n"}, HostGitLab},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for path, content := range tt.files {
+				writeFile(t, root, path, content)
+			}
+			if got := detectHost(root); got != tt.want {
+				t.Errorf("detectHost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_IsValid(t *testing.T) {
+	projects := map[string]*Project{
+		"empty project": {},
+		"full project": {
+			Languages:    []string{"go", "typescript", "css"},
+			IgnorePaths:  []string{"vendor/", "**/*.pb.go"},
+			ContextFiles: []string{"CLAUDE.md"},
+		},
+	}
+
+	for name, project := range projects {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ainspector.yaml")
+			writeFile(t, filepath.Dir(path), "ainspector.yaml", Config(project))
+
+			cfg, err := config.LoadFromPath(path)
+			if err != nil {
+				t.Fatalf("generated config is invalid: %v\n%s", err, Config(project))
+			}
+			if !reflect.DeepEqual(cfg.Ignore.Paths, project.IgnorePaths) {
+				t.Errorf("Ignore.Paths = %v, want %v", cfg.Ignore.Paths, project.IgnorePaths)
+			}
+			if !reflect.DeepEqual(cfg.Context.Include, project.ContextFiles) {
+				t.Errorf("Context.Include = %v, want %v", cfg.Context.Include, project.ContextFiles)
+			}
+			if len(cfg.Rules) < len(starterRules) {
+				t.Errorf("expected at least %d rules, got %d", len(starterRules), len(cfg.Rules))
+			}
+			for _, rule := range cfg.Rules {
+				for _, lang := range rule.Languages {
+					if !rule.AppliesTo("file", lang) {
+						t.Errorf("rule %q should apply to %s", rule.ID, lang)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	path, content := Workflow(HostGitHub)
+	if path != ".github/workflows/ainspector.yml" || !strings.Contains(content, "GITHUB_TOKEN") {
+		t.Errorf("unexpected GitHub workflow %s:\n%s", path, content)
+	}
+
+	path, content = Workflow(HostGitLab)
+	if path != ".gitlab-ci.yml" || !strings.Contains(content, "merge_request_event") {
+		t.Errorf("unexpected GitLab workflow %s:\n%s", path, content)
+	}
+}